@@ -29,12 +29,21 @@ package clib
 #include <libxml/parser.h>
 #include <libxml/parserInternals.h>
 #include <libxml/tree.h>
+#include <libxml/valid.h>
 #include <libxml/xmlerror.h>
 #include <libxml/xpath.h>
 #include <libxml/xpathInternals.h>
 #include <libxml/c14n.h>
+#include <libxml/entities.h>
 #include <libxml/xmlschemas.h>
 #include <libxml/schemasInternals.h>
+#include <libxml/relaxng.h>
+#include <libxml/schematron.h>
+#include <libxml/xmlIO.h>
+
+// Implemented in Go, see stream.go and resolver.go
+extern int goReadStream(uintptr_t ctx, char *buf, int len);
+extern int goResolveEntity(uintptr_t ctx, char *url, void **data, int *len);
 
 static inline void MY_nilErrorHandler(void *ctx, const char *msg, ...) {}
 
@@ -255,87 +264,469 @@ CLEANUP:
 	return node;
 }
 
-#define GO_LIBXML2_ERRWARN_ACCUMULATOR_SIZE 32
-typedef struct go_libxml2_errwarn_accumulator {
-	char *errors[GO_LIBXML2_ERRWARN_ACCUMULATOR_SIZE];
-	char *warnings[GO_LIBXML2_ERRWARN_ACCUMULATOR_SIZE];
-	int erridx;
-	int warnidx;
-} go_libxml2_errwarn_accumulator;
+// go_libxml2_error holds a copy of the interesting parts of an
+// xmlError, as the original is only valid during the callback
+typedef struct go_libxml2_error {
+	char *message;
+	char *file;
+	char *path;
+	char *str1;
+	char *str2;
+	char *str3;
+	void *node;
+	int line;
+	int column;
+	int code;
+	int domain;
+	int level;
+} go_libxml2_error;
+
+typedef struct go_libxml2_error_list {
+	go_libxml2_error *errors;
+	int count;
+	int size;
+	int max; // 0 means unlimited
+} go_libxml2_error_list;
+
+static
+go_libxml2_error_list*
+MY_createErrorList(int max) {
+	go_libxml2_error_list *list;
+	list = (go_libxml2_error_list *) calloc(1, sizeof(go_libxml2_error_list));
+	list->max = max;
+	return list;
+}
 
 static
-go_libxml2_errwarn_accumulator*
-MY_createErrWarnAccumulator() {
+void
+MY_freeErrorList(go_libxml2_error_list *list) {
 	int i;
-	go_libxml2_errwarn_accumulator *ctx;
-	ctx = (go_libxml2_errwarn_accumulator *) malloc(sizeof(go_libxml2_errwarn_accumulator));
-	for (i = 0; i < GO_LIBXML2_ERRWARN_ACCUMULATOR_SIZE; i++) {
-		ctx->errors[i] = NULL;
-		ctx->warnings[i] = NULL;
+	for (i = 0; i < list->count; i++) {
+		free(list->errors[i].message);
+		free(list->errors[i].file);
+		free(list->errors[i].str1);
+		free(list->errors[i].str2);
+		free(list->errors[i].str3);
+		if (list->errors[i].path != NULL) {
+			xmlFree(list->errors[i].path);
+		}
+	}
+	free(list->errors);
+	free(list);
+}
+
+static
+char*
+MY_strdupOrNull(const char *s) {
+	if (s == NULL) {
+		return NULL;
 	}
-	ctx->erridx = 0;
-	ctx->warnidx = 0;
-	return ctx;
+	return strdup(s);
 }
 
+// Declared with a const argument to match libxml2 >= 2.12. Older
+// versions take a non-const pointer, hence the casts when registering
 static
 void
-MY_freeErrWarnAccumulator(go_libxml2_errwarn_accumulator* ctx) {
-	int i = 0;
-	for (i = 0; i < GO_LIBXML2_ERRWARN_ACCUMULATOR_SIZE; i++) {
-		if (ctx->errors[i] != NULL) {
-			free(ctx->errors[i]);
+MY_collectStructuredError(void *ctx, const xmlError *err) {
+	go_libxml2_error_list *list = (go_libxml2_error_list *) ctx;
+	go_libxml2_error *e;
+	xmlNodePtr node;
+	size_t len;
+
+	if (err == NULL || (list->max > 0 && list->count >= list->max)) {
+		return;
+	}
+
+	if (list->count >= list->size) {
+		int size = list->size == 0 ? 8 : list->size * 2;
+		go_libxml2_error *errors = (go_libxml2_error *) realloc(list->errors, size * sizeof(go_libxml2_error));
+		if (errors == NULL) {
+			return;
 		}
-		if (ctx->warnings[i] != NULL) {
-			free(ctx->errors[i]);
+		list->errors = errors;
+		list->size = size;
+	}
+
+	e = &list->errors[list->count++];
+	e->message = MY_strdupOrNull(err->message);
+	if (e->message != NULL) {
+		// don't want newlines in my error values
+		len = strlen(e->message);
+		while (len > 0 && e->message[len-1] == '\n') {
+			e->message[--len] = '\0';
 		}
 	}
-	free(ctx);
+	e->file = MY_strdupOrNull(err->file);
+	e->str1 = MY_strdupOrNull(err->str1);
+	e->str2 = MY_strdupOrNull(err->str2);
+	e->str3 = MY_strdupOrNull(err->str3);
+	e->line = err->line;
+	e->column = err->int2;
+	e->code = err->code;
+	e->domain = err->domain;
+	e->level = err->level;
+	e->node = err->node;
+	e->path = NULL;
+
+	node = (xmlNodePtr) err->node;
+	if (node != NULL && node->type != XML_NAMESPACE_DECL) {
+		e->path = (char *) xmlGetNodePath(node);
+	}
 }
 
 static
 void
-MY_accumulateErr(void *ctx, const char *msg, ...) {
-  char buf[1024];
-  va_list args;
-	go_libxml2_errwarn_accumulator *accum;
-	int len;
-
-	accum = (go_libxml2_errwarn_accumulator *) ctx;
-	if (accum->erridx >= GO_LIBXML2_ERRWARN_ACCUMULATOR_SIZE) {
-		return;
+MY_setRelaxNGParserErrors(xmlRelaxNGParserCtxtPtr ctxt, go_libxml2_error_list *list) {
+	xmlRelaxNGSetParserStructuredErrors(ctxt, (xmlStructuredErrorFunc) MY_collectStructuredError, list);
+}
+
+static
+void
+MY_setRelaxNGValidErrors(xmlRelaxNGValidCtxtPtr ctxt, go_libxml2_error_list *list) {
+	xmlRelaxNGSetValidStructuredErrors(ctxt, (xmlStructuredErrorFunc) MY_collectStructuredError, list);
+}
+
+static
+void
+MY_setSchemaParserErrors(xmlSchemaParserCtxtPtr ctxt, go_libxml2_error_list *list) {
+	xmlSchemaSetParserStructuredErrors(ctxt, (xmlStructuredErrorFunc) MY_collectStructuredError, list);
+}
+
+static
+void
+MY_setSchemaValidErrors(xmlSchemaValidCtxtPtr ctxt, go_libxml2_error_list *list) {
+	xmlSchemaSetValidStructuredErrors(ctxt, (xmlStructuredErrorFunc) MY_collectStructuredError, list);
+}
+
+// The validation functions below temporarily install the error list
+// as the structured error handler of the current thread. This has to
+// happen within a single C call, as goroutines may switch threads
+// between cgo calls
+
+static
+int
+MY_validateDtd(xmlDocPtr doc, xmlDtdPtr dtd, go_libxml2_error_list *list) {
+	xmlStructuredErrorFunc oldfn = xmlStructuredError;
+	void *oldctx = xmlStructuredErrorContext;
+	xmlValidCtxtPtr vctxt;
+	int ret = -1;
+
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_collectStructuredError);
+	vctxt = xmlNewValidCtxt();
+	if (vctxt != NULL) {
+		ret = xmlValidateDtd(vctxt, doc, dtd);
+		xmlFreeValidCtxt(vctxt);
 	}
+	xmlSetStructuredErrorFunc(oldctx, oldfn);
+	return ret;
+}
+
+static
+int
+MY_validateDtdElement(xmlDocPtr doc, xmlDtdPtr dtd, xmlNodePtr elem, go_libxml2_error_list *list) {
+	xmlStructuredErrorFunc oldfn = xmlStructuredError;
+	void *oldctx = xmlStructuredErrorContext;
+	xmlValidCtxtPtr vctxt;
+	xmlDtdPtr oldint, oldext;
+	int ret = -1;
+
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_collectStructuredError);
+	vctxt = xmlNewValidCtxt();
+	if (vctxt != NULL) {
+		// Same trick as xmlValidateDtd: swap the DTD in while validating
+		oldint = doc->intSubset;
+		oldext = doc->extSubset;
+		doc->intSubset = NULL;
+		doc->extSubset = dtd;
+		ret = xmlValidateElement(vctxt, doc, elem);
+		doc->intSubset = oldint;
+		doc->extSubset = oldext;
+		xmlFreeValidCtxt(vctxt);
+	}
+	xmlSetStructuredErrorFunc(oldctx, oldfn);
+	return ret;
+}
 
-  va_start(args, msg);
-  len = vsnprintf(buf, sizeof(buf), msg, args);
-  va_end(args);
+static
+int
+MY_validateDocument(xmlDocPtr doc, go_libxml2_error_list *list) {
+	xmlStructuredErrorFunc oldfn = xmlStructuredError;
+	void *oldctx = xmlStructuredErrorContext;
+	xmlValidCtxtPtr vctxt;
+	int ret = -1;
 
-	if (len == 0) {
-		return;
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_collectStructuredError);
+	vctxt = xmlNewValidCtxt();
+	if (vctxt != NULL) {
+		ret = xmlValidateDocument(vctxt, doc);
+		xmlFreeValidCtxt(vctxt);
 	}
+	xmlSetStructuredErrorFunc(oldctx, oldfn);
+	return ret;
+}
 
-	char *out = (char *) calloc(sizeof(char), len);
-	if (buf[len-1] == '\n') {
-		// don't want newlines in my error values
-		buf[len-1] = '\0';
-		len--;
+static
+xmlDtdPtr
+MY_parseDtdMemory(const char *buf, int len, go_libxml2_error_list *list) {
+	xmlStructuredErrorFunc oldfn = xmlStructuredError;
+	void *oldctx = xmlStructuredErrorContext;
+	xmlParserInputBufferPtr input;
+	xmlDtdPtr dtd = NULL;
+
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_collectStructuredError);
+	input = xmlParserInputBufferCreateMem(buf, len, XML_CHAR_ENCODING_NONE);
+	if (input != NULL) {
+		// xmlIOParseDTD takes ownership of the input buffer
+		dtd = xmlIOParseDTD(NULL, input, XML_CHAR_ENCODING_NONE);
+	}
+	xmlSetStructuredErrorFunc(oldctx, oldfn);
+	return dtd;
+}
+
+static
+xmlDtdPtr
+MY_parseDtdFile(const char *path, go_libxml2_error_list *list) {
+	xmlStructuredErrorFunc oldfn = xmlStructuredError;
+	void *oldctx = xmlStructuredErrorContext;
+	xmlDtdPtr dtd;
+
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_collectStructuredError);
+	dtd = xmlParseDTD(NULL, (const xmlChar *) path);
+	xmlSetStructuredErrorFunc(oldctx, oldfn);
+	return dtd;
+}
+
+static
+int
+MY_readStream(void *ctx, char *buf, int len) {
+	return goReadStream((uintptr_t) ctx, buf, len);
+}
+
+// The input buffer is owned, and freed, by xmlSchemaValidateStream
+static
+int
+MY_validateSchemaStream(xmlSchemaValidCtxtPtr ctxt, uintptr_t handle, go_libxml2_error_list *list) {
+	xmlStructuredErrorFunc oldfn = xmlStructuredError;
+	void *oldctx = xmlStructuredErrorContext;
+	xmlParserInputBufferPtr input;
+	int ret = -1;
+
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_collectStructuredError);
+	input = xmlParserInputBufferCreateIO(MY_readStream, NULL, (void *) handle, XML_CHAR_ENCODING_NONE);
+	if (input != NULL) {
+		ret = xmlSchemaValidateStream(ctxt, input, XML_CHAR_ENCODING_NONE, NULL, NULL);
+	}
+	xmlSetStructuredErrorFunc(oldctx, oldfn);
+	return ret;
+}
+
+static
+int
+MY_validateSchemaFile(xmlSchemaValidCtxtPtr ctxt, const char *path, go_libxml2_error_list *list) {
+	xmlStructuredErrorFunc oldfn = xmlStructuredError;
+	void *oldctx = xmlStructuredErrorContext;
+	int ret;
+
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_collectStructuredError);
+	ret = xmlSchemaValidateFile(ctxt, path, 0);
+	xmlSetStructuredErrorFunc(oldctx, oldfn);
+	return ret;
+}
+
+// The following mirror structures that are private to xmlschemas.c,
+// and are needed to walk the components of a compiled schema. Their
+// layout is known to be the same from libxml2 2.7 up to 2.14. Other
+// versions must be checked before they are added to this range
+#if LIBXML_VERSION >= 20700 && LIBXML_VERSION < 21500
+#define MY_SCHEMA_INTROSPECTION 1
+#else
+#define MY_SCHEMA_INTROSPECTION 0
+#endif
+
+typedef struct MY_xmlSchemaTreeItem {
+	xmlSchemaTypeType type;
+	xmlSchemaAnnotPtr annot;
+	struct MY_xmlSchemaTreeItem *next;
+	struct MY_xmlSchemaTreeItem *children;
+} MY_xmlSchemaTreeItem;
+
+typedef struct MY_xmlSchemaParticle {
+	xmlSchemaTypeType type;
+	xmlSchemaAnnotPtr annot;
+	MY_xmlSchemaTreeItem *next;
+	MY_xmlSchemaTreeItem *children;
+	int minOccurs;
+	int maxOccurs;
+	xmlNodePtr node;
+} MY_xmlSchemaParticle;
+
+typedef struct MY_xmlSchemaAttributeUse {
+	xmlSchemaTypeType type;
+	xmlSchemaAnnotPtr annot;
+	void *next;
+	xmlSchemaAttributePtr attrDecl;
+	int flags;
+	xmlNodePtr node;
+	int occurs;
+	const xmlChar *defValue;
+	xmlSchemaValPtr defVal;
+} MY_xmlSchemaAttributeUse;
+
+#define MY_XML_SCHEMA_ATTR_USE_FIXED 1<<0
+
+typedef struct MY_xmlSchemaItemList {
+	void **items;
+	int nbItems;
+	int sizeItems;
+} MY_xmlSchemaItemList;
+
+typedef struct MY_ptrList {
+	void **items;
+	int count;
+	int size;
+} MY_ptrList;
+
+static
+void
+MY_collectHashValue(void *payload, void *data, const xmlChar *name) {
+	MY_ptrList *list = (MY_ptrList *) data;
+	if (list->count >= list->size) {
+		int size = list->size == 0 ? 16 : list->size * 2;
+		void **items = (void **) realloc(list->items, size * sizeof(void *));
+		if (items == NULL) {
+			return;
+		}
+		list->items = items;
+		list->size = size;
+	}
+	list->items[list->count++] = payload;
+}
+
+// MY_hashValues returns the values stored in the hash table. The
+// items of the returned list must be freed
+static
+MY_ptrList
+MY_hashValues(xmlHashTablePtr table) {
+	MY_ptrList list = { NULL, 0, 0 };
+	if (table != NULL) {
+		xmlHashScan(table, MY_collectHashValue, &list);
+	}
+	return list;
+}
+
+// The resolver in effect for the schema being parsed on this thread.
+// The entity loader is global, so it must be able to tell parses
+// using a resolver apart from other parses, which are handed to the
+// loader that was installed before it
+static __thread uintptr_t MY_resolverHandle = 0;
+static xmlExternalEntityLoader MY_previousEntityLoader = NULL;
+
+static
+xmlParserInputPtr
+MY_resolvingEntityLoader(const char *URL, const char *ID, xmlParserCtxtPtr ctxt) {
+	xmlParserInputBufferPtr buf;
+	xmlParserInputPtr input;
+	void *data = NULL;
+	int len = 0;
+
+	if (MY_resolverHandle == 0 || URL == NULL) {
+		return MY_previousEntityLoader(URL, ID, ctxt);
+	}
+
+	if (goResolveEntity(MY_resolverHandle, (char *) URL, &data, &len) != 0) {
+		return NULL;
+	}
+
+	buf = xmlParserInputBufferCreateMem(data != NULL ? (const char *) data : "", len, XML_CHAR_ENCODING_NONE);
+	free(data);
+	if (buf == NULL) {
+		return NULL;
+	}
+
+	input = xmlNewIOInputStream(ctxt, buf, XML_CHAR_ENCODING_NONE);
+	if (input == NULL) {
+		xmlFreeParserInputBuffer(buf);
+		return NULL;
 	}
-	memcpy(out, buf, len);
+	input->filename = (char *) xmlStrdup((const xmlChar *) URL);
+	return input;
+}
 
-  int i = accum->erridx++;
-	accum->errors[i] = out;
+static
+void
+MY_installResolvingEntityLoader() {
+	MY_previousEntityLoader = xmlGetExternalEntityLoader();
+	xmlSetExternalEntityLoader(MY_resolvingEntityLoader);
 }
 
+// Loaders installed in the meantime are left in place
 static
 void
-MY_setErrWarnAccumulator(xmlSchemaValidCtxtPtr ctxt, go_libxml2_errwarn_accumulator *accum) {
-	xmlSchemaSetValidErrors(ctxt, MY_accumulateErr, NULL, accum);
+MY_uninstallResolvingEntityLoader() {
+	if (xmlGetExternalEntityLoader() == MY_resolvingEntityLoader) {
+		xmlSetExternalEntityLoader(MY_previousEntityLoader);
+	}
+}
+
+static
+xmlSchemaPtr
+MY_parseSchemaWithResolver(xmlSchemaParserCtxtPtr ctxt, uintptr_t handle) {
+	uintptr_t old = MY_resolverHandle;
+	xmlSchemaPtr schema;
+
+	MY_resolverHandle = handle;
+	schema = xmlSchemaParse(ctxt);
+	MY_resolverHandle = old;
+	return schema;
+}
+
+// The parser context is freed, but the document is not, as the
+// schema keeps pointers into it
+static
+xmlSchematronPtr
+MY_parseSchematron(xmlDocPtr doc, go_libxml2_error_list *list) {
+	xmlStructuredErrorFunc oldfn = xmlStructuredError;
+	void *oldctx = xmlStructuredErrorContext;
+	xmlSchematronParserCtxtPtr ctxt;
+	xmlSchematronPtr schema = NULL;
+
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_collectStructuredError);
+	ctxt = xmlSchematronNewDocParserCtxt(doc);
+	if (ctxt != NULL) {
+		schema = xmlSchematronParse(ctxt);
+		xmlSchematronFreeParserCtxt(ctxt);
+	}
+	xmlSetStructuredErrorFunc(oldctx, oldfn);
+	return schema;
+}
+
+static
+int
+MY_validateSchematron(xmlSchematronPtr schema, xmlDocPtr doc, go_libxml2_error_list *list) {
+	xmlStructuredErrorFunc oldfn = xmlStructuredError;
+	void *oldctx = xmlStructuredErrorContext;
+	xmlSchematronValidCtxtPtr ctxt;
+	int ret = -1;
+
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_collectStructuredError);
+	ctxt = xmlSchematronNewValidCtxt(schema, XML_SCHEMATRON_OUT_ERROR);
+	if (ctxt != NULL) {
+		xmlSchematronSetValidStructuredErrors(ctxt, (xmlStructuredErrorFunc) MY_collectStructuredError, list);
+		ret = xmlSchematronValidateDoc(ctxt, doc);
+		xmlSchematronFreeValidCtxt(ctxt);
+	}
+	xmlSetStructuredErrorFunc(oldctx, oldfn);
+	return ret;
 }
 */
 import "C"
 import (
 	"fmt"
+	"io"
+	"runtime/cgo"
 	"strings"
+	"sync"
 	"unicode"
 	"unicode/utf8"
 	"unsafe"
@@ -487,19 +878,18 @@ func stringToXMLChar(s string) *C.xmlChar {
 	return (*C.xmlChar)(unsafe.Pointer(C.CString(s)))
 }
 
-func XMLSchemaParseFromFile(path string) (uintptr, error) {
-	parserCtx := C.xmlSchemaNewParserCtxt(C.CString(path))
-	if parserCtx == nil {
-		return 0, errors.New("failed to create parser")
-	}
-	defer C.xmlSchemaFreeParserCtxt(parserCtx)
+// XMLSchemaParseFromFile parses the XML schema stored in the file
+func XMLSchemaParseFromFile(path string, options ...option.Interface) (uintptr, error) {
+	uri, encoding, coptions := schemaReadOptions(options)
 
-	s := C.xmlSchemaParse(parserCtx)
-	if s == nil {
-		return 0, errors.New("failed to parse schema")
+	doc, err := readSchemaFile(path, encoding, coptions)
+	if err != nil {
+		return 0, err
 	}
-
-	return uintptr(unsafe.Pointer(s)), nil
+	if uri != "" {
+		setDocumentURI(doc, uri)
+	}
+	return xmlSchemaParseDoc(doc, options)
 }
 
 func XMLCreateMemoryParserCtxt(s string, o int) (uintptr, error) {
@@ -867,7 +1257,7 @@ func XMLNodeName(n PtrSource) (string, error) {
 			s = xmlCharToString(nptr.name)
 		}
 	case ElementDecl, AttributeDecl:
-		panic("unimplemented")
+		s = xmlCharToString(nptr.name)
 	default:
 		panic("unknown")
 	}
@@ -893,6 +1283,8 @@ func XMLNodeValue(n PtrSource) (string, error) {
 			s = xmlCharToString(xc)
 			C.MY_xmlFree(unsafe.Pointer(xc))
 		}
+	case DTDNode, ElementDecl, AttributeDecl:
+		// Declarations do not have a value
 	default:
 		panic("unimplmented")
 	}
@@ -942,6 +1334,16 @@ func XMLFirstChild(n PtrSource) (uintptr, error) {
 	return uintptr(unsafe.Pointer(nptr.children)), nil
 }
 
+// XMLFirstChildPtr is like XMLFirstChild, but returns 0 instead of
+// an error when the node is invalid or has no children.
+func XMLFirstChildPtr(n PtrSource) uintptr {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return 0
+	}
+	return uintptr(unsafe.Pointer(nptr.children))
+}
+
 func XMLHasChildNodes(n PtrSource) bool {
 	nptr, err := validNodePtr(n)
 	if err != nil {
@@ -1070,6 +1472,179 @@ func XMLTextContent(n PtrSource) string {
 	return xmlCharToString(C.xmlXPathCastNodeToString(nptr))
 }
 
+// XMLGetNodePath returns an XPath expression that uniquely
+// identifies the node within its document
+func XMLGetNodePath(n PtrSource) (string, error) {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return "", err
+	}
+
+	path := C.xmlGetNodePath(nptr)
+	if path == nil {
+		return "", errors.New("failed to compute node path")
+	}
+	defer C.MY_xmlFree(unsafe.Pointer(path))
+	return xmlCharToString(path), nil
+}
+
+// XMLGetLineNo returns the line number where the node was found by
+// the parser, or 0 if it is not known
+func XMLGetLineNo(n PtrSource) int {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return 0
+	}
+
+	if XMLNodeType(nptr._type) == NamespaceDecl {
+		return 0
+	}
+
+	l := C.xmlGetLineNo(nptr)
+	if l < 0 {
+		return 0
+	}
+	return int(l)
+}
+
+// XMLNodeGetBase returns the base URI of the node, taking xml:base
+// attributes and the document URI into account
+func XMLNodeGetBase(n PtrSource) string {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return ""
+	}
+
+	if XMLNodeType(nptr._type) == NamespaceDecl {
+		return ""
+	}
+
+	base := C.xmlNodeGetBase(nptr.doc, nptr)
+	if base == nil {
+		return ""
+	}
+	defer C.MY_xmlFree(unsafe.Pointer(base))
+	return xmlCharToString(base)
+}
+
+// XMLElementSetBase sets the xml:base attribute of the element
+func XMLElementSetBase(n PtrSource, uri string) error {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return err
+	}
+
+	if XMLNodeType(nptr._type) != ElementNode {
+		return ErrInvalidNode
+	}
+
+	curi := stringToXMLChar(uri)
+	defer C.free(unsafe.Pointer(curi))
+
+	C.xmlNodeSetBase(nptr, curi)
+	return nil
+}
+
+// XMLNodeGetLang returns the language of the node, as declared by
+// the xml:lang attribute of the node or its nearest ancestor
+func XMLNodeGetLang(n PtrSource) string {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return ""
+	}
+
+	if XMLNodeType(nptr._type) == NamespaceDecl {
+		return ""
+	}
+
+	lang := C.xmlNodeGetLang(nptr)
+	if lang == nil {
+		return ""
+	}
+	defer C.MY_xmlFree(unsafe.Pointer(lang))
+	return xmlCharToString(lang)
+}
+
+// XMLNodeSetLang sets the xml:lang attribute of the element
+func XMLNodeSetLang(n PtrSource, lang string) error {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return err
+	}
+
+	if XMLNodeType(nptr._type) != ElementNode {
+		return ErrInvalidNode
+	}
+
+	clang := stringToXMLChar(lang)
+	defer C.free(unsafe.Pointer(clang))
+
+	C.xmlNodeSetLang(nptr, clang)
+	return nil
+}
+
+// XMLNodeGetSpacePreserve returns 1 if the node is in the scope of
+// an xml:space="preserve" attribute, 0 if it is in the scope of an
+// xml:space="default" attribute, and -1 if neither applies. Nodes
+// other than elements take the value from their parent element
+func XMLNodeGetSpacePreserve(n PtrSource) int {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return -1
+	}
+
+	switch XMLNodeType(nptr._type) {
+	case ElementNode:
+	case TextNode, CDataSectionNode, CommentNode, PiNode, EntityRefNode, AttributeNode:
+		nptr = nptr.parent
+		if nptr == nil || XMLNodeType(nptr._type) != ElementNode {
+			return -1
+		}
+	default:
+		return -1
+	}
+	return int(C.xmlNodeGetSpacePreserve(nptr))
+}
+
+// XMLSetTextContent replaces all children of the node with a single
+// text node holding the given string. The string is taken literally,
+// i.e. entity references in it are not substituted
+func XMLSetTextContent(n PtrSource, content string) error {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return err
+	}
+
+	switch XMLNodeType(nptr._type) {
+	case ElementNode, DocumentFragNode:
+	default:
+		return ErrInvalidNode
+	}
+
+	if nptr.children != nil {
+		C.xmlFreeNodeList(nptr.children)
+		nptr.children = nil
+		nptr.last = nil
+	}
+
+	if content == "" {
+		return nil
+	}
+
+	ccontent := stringToXMLChar(content)
+	defer C.free(unsafe.Pointer(ccontent))
+
+	text := C.xmlNewDocText(nptr.doc, ccontent)
+	if text == nil {
+		return errors.New("failed to create text node")
+	}
+	if C.xmlAddChild(nptr, text) == nil {
+		C.xmlFreeNode(text)
+		return errors.New("failed to add text node")
+	}
+	return nil
+}
+
 func XMLToString(n PtrSource, format int, _ bool) string {
 	nptr, err := validNodePtr(n)
 	if err != nil {
@@ -1142,41 +1717,452 @@ func XMLGetNodeType(n PtrSource) XMLNodeType {
 	return XMLNodeType(nptr._type)
 }
 
-func XMLChildNodes(n PtrSource) ([]uintptr, error) {
-	nptr, err := validNodePtr(n)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get valid node for XMLChildNodes")
-	}
+func xmlStrEqual(a, b *C.xmlChar) bool {
+	return C.xmlStrEqual(a, b) == 1
+}
 
-	ret := []uintptr(nil)
-	for chld := nptr.children; chld != nil; chld = chld.next {
-		ret = append(ret, uintptr(unsafe.Pointer(chld)))
+func isEqualNs(a, b *C.xmlNs) bool {
+	if a == nil || b == nil {
+		return a == b
 	}
-	return ret, nil
+	return xmlStrEqual(a.href, b.href) && xmlStrEqual(a.prefix, b.prefix)
 }
 
-type stringer interface {
-	String() string
+func countNsDefs(n *C.xmlNode) int {
+	var i int
+	for ns := n.nsDef; ns != nil; ns = ns.next {
+		i++
+	}
+	return i
 }
 
-func XMLRemoveChild(n PtrSource, t PtrSource) error {
-	nptr, err := validNodePtr(n)
-	if err != nil {
-		return errors.Wrap(err, "failed to get valid node for XMLRemoveChild")
+func isEqualNsDefs(a, b *C.xmlNode) bool {
+	if countNsDefs(a) != countNsDefs(b) {
+		return false
 	}
 
-	tptr, err := validNodePtr(t)
-	if err != nil {
-		return errors.Wrap(err, "failed to get valid node for XMLRemoveChild")
+OUTER:
+	for ans := a.nsDef; ans != nil; ans = ans.next {
+		for bns := b.nsDef; bns != nil; bns = bns.next {
+			if isEqualNs(ans, bns) {
+				continue OUTER
+			}
+		}
+		return false
 	}
+	return true
+}
 
-	switch XMLNodeType(tptr._type) {
-	case AttributeNode, NamespaceDecl:
-		return nil
+func isEqualAttributes(a, b *C.xmlNode) bool {
+	var acount, bcount int
+	for attr := a.properties; attr != nil; attr = attr.next {
+		acount++
 	}
-
-	if tptr.parent != nptr {
-		return nil /* not a child! */
+	for attr := b.properties; attr != nil; attr = attr.next {
+		bcount++
+	}
+	if acount != bcount {
+		return false
+	}
+
+	for aattr := a.properties; aattr != nil; aattr = aattr.next {
+		var href *C.xmlChar
+		if aattr.ns != nil {
+			href = aattr.ns.href
+		}
+
+		battr := C.xmlHasNsProp(b, aattr.name, href)
+		if battr == nil || XMLNodeType(battr._type) != AttributeNode {
+			return false
+		}
+		if !isEqualNodes((*C.xmlNode)(unsafe.Pointer(aattr)), (*C.xmlNode)(unsafe.Pointer(battr))) {
+			return false
+		}
+	}
+	return true
+}
+
+// isEqualNodes implements the DOM Level 3 isEqualNode algorithm:
+// both nodes must have the same type, names, namespace, value,
+// attributes and (recursively) children. Attributes and namespace
+// declarations are compared regardless of their order
+func isEqualNodes(a, b *C.xmlNode) bool {
+	if a == b {
+		return true
+	}
+
+	if a._type != b._type {
+		return false
+	}
+
+	switch XMLNodeType(a._type) {
+	case NamespaceDecl:
+		return isEqualNs((*C.xmlNs)(unsafe.Pointer(a)), (*C.xmlNs)(unsafe.Pointer(b)))
+	case DocumentNode, HTMLDocumentNode, DocbDocumentNode:
+	default:
+		if !xmlStrEqual(a.name, b.name) {
+			return false
+		}
+	}
+
+	switch XMLNodeType(a._type) {
+	case ElementNode, AttributeNode:
+		if !isEqualNs(a.ns, b.ns) {
+			return false
+		}
+	case TextNode, CDataSectionNode, CommentNode, PiNode:
+		if !xmlStrEqual(a.content, b.content) {
+			return false
+		}
+	}
+
+	if XMLNodeType(a._type) == ElementNode {
+		if !isEqualNsDefs(a, b) || !isEqualAttributes(a, b) {
+			return false
+		}
+	}
+
+	if XMLNodeType(a._type) == EntityRefNode {
+		// children point to the entity declaration
+		return true
+	}
+
+	achld, bchld := a.children, b.children
+	for achld != nil && bchld != nil {
+		if !isEqualNodes(achld, bchld) {
+			return false
+		}
+		achld, bchld = achld.next, bchld.next
+	}
+	return achld == nil && bchld == nil
+}
+
+// XMLIsEqualNode returns true if the two nodes are structurally equal
+// as defined by DOM Level 3
+func XMLIsEqualNode(n PtrSource, other PtrSource) bool {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return false
+	}
+
+	optr, err := validNodePtr(other)
+	if err != nil {
+		return false
+	}
+
+	return isEqualNodes(nptr, optr)
+}
+
+// XMLXPathCmpNodes compares the position of two nodes in the
+// document. It returns -1 if n comes before other, 0 if they
+// are the same node, and 1 if n comes after other.
+// An error is returned if the nodes do not belong to the same tree.
+// Note that the sign of the result is the reverse of what
+// xmlXPathCmpNodes returns, so that it can be used with slices.SortFunc
+func XMLXPathCmpNodes(n PtrSource, other PtrSource) (int, error) {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return 0, err
+	}
+
+	optr, err := validNodePtr(other)
+	if err != nil {
+		return 0, err
+	}
+
+	if XMLNodeType(nptr._type) == NamespaceDecl || XMLNodeType(optr._type) == NamespaceDecl {
+		return 0, errors.New("cannot compare namespace nodes")
+	}
+
+	switch C.xmlXPathCmpNodes(nptr, optr) {
+	case 1:
+		return -1, nil
+	case 0:
+		return 0, nil
+	case -1:
+		return 1, nil
+	default:
+		return 0, errors.New("nodes do not belong to the same tree")
+	}
+}
+
+// XMLTreeRoot returns the topmost ancestor of n, which is the
+// document for nodes that belong to one. Namespace nodes are
+// their own root.
+func XMLTreeRoot(n PtrSource) uintptr {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return 0
+	}
+
+	if XMLNodeType(nptr._type) == NamespaceDecl {
+		return uintptr(unsafe.Pointer(nptr))
+	}
+
+	for nptr.parent != nil {
+		nptr = nptr.parent
+	}
+	return uintptr(unsafe.Pointer(nptr))
+}
+
+// XMLIsAncestor returns true if n is an ancestor of other
+func XMLIsAncestor(n PtrSource, other PtrSource) bool {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return false
+	}
+
+	optr, err := validNodePtr(other)
+	if err != nil || XMLNodeType(optr._type) == NamespaceDecl {
+		return false
+	}
+
+	for p := optr.parent; p != nil; p = p.parent {
+		if p == nptr {
+			return true
+		}
+	}
+	return false
+}
+
+// XMLCompareDocumentPosition returns the position of other
+// relative to n, as defined by DOM Level 3
+func XMLCompareDocumentPosition(n PtrSource, other PtrSource) DocumentPosition {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return DocumentPositionDisconnected
+	}
+
+	optr, err := validNodePtr(other)
+	if err != nil {
+		return DocumentPositionDisconnected
+	}
+
+	if nptr == optr {
+		return 0
+	}
+
+	cmp, err := XMLXPathCmpNodes(n, other)
+	if err != nil {
+		// Disconnected nodes are ordered consistently, but arbitrarily,
+		// by comparing the roots of their trees
+		ret := DocumentPositionDisconnected | DocumentPositionImplementationSpecific
+		nroot, oroot := XMLTreeRoot(n), XMLTreeRoot(other)
+		if nroot < oroot || (nroot == oroot && n.Pointer() < other.Pointer()) {
+			return ret | DocumentPositionFollowing
+		}
+		return ret | DocumentPositionPreceding
+	}
+
+	switch {
+	case XMLIsAncestor(other, n):
+		return DocumentPositionContains | DocumentPositionPreceding
+	case XMLIsAncestor(n, other):
+		return DocumentPositionContainedBy | DocumentPositionFollowing
+	case cmp < 0:
+		return DocumentPositionFollowing
+	default:
+		return DocumentPositionPreceding
+	}
+}
+
+func XMLChildNodes(n PtrSource) ([]uintptr, error) {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get valid node for XMLChildNodes")
+	}
+
+	ret := []uintptr(nil)
+	for chld := nptr.children; chld != nil; chld = chld.next {
+		ret = append(ret, uintptr(unsafe.Pointer(chld)))
+	}
+	return ret, nil
+}
+
+// xmlCharEquals compares the NUL terminated C string s against
+// the Go string v without allocating or calling out to C
+func xmlCharEquals(s *C.xmlChar, v string) bool {
+	if s == nil {
+		return v == ""
+	}
+
+	p := unsafe.Pointer(s)
+	for i := 0; i < len(v); i++ {
+		if *(*byte)(unsafe.Add(p, i)) != v[i] {
+			return false
+		}
+	}
+	return *(*byte)(unsafe.Add(p, len(v))) == 0
+}
+
+// matchElementName checks if the element's qualified name matches
+// name. "*" matches all elements
+func matchElementName(nptr *C.xmlNode, name string) bool {
+	if name == "*" {
+		return true
+	}
+
+	if ns := nptr.ns; ns != nil && ns.prefix != nil {
+		prefix, local := SplitPrefixLocal(name)
+		return xmlCharEquals(ns.prefix, prefix) && xmlCharEquals(nptr.name, local)
+	}
+	return xmlCharEquals(nptr.name, name)
+}
+
+// matchElementNameNS checks if the element's namespace URI and
+// local name match. "*" matches any namespace or local name
+func matchElementNameNS(nptr *C.xmlNode, nsuri, local string) bool {
+	if local != "*" && !xmlCharEquals(nptr.name, local) {
+		return false
+	}
+
+	if nsuri == "*" {
+		return true
+	}
+
+	if nptr.ns == nil {
+		return nsuri == ""
+	}
+	return xmlCharEquals(nptr.ns.href, nsuri)
+}
+
+// walkElements walks the descendants of root in document order
+// without recursion, and calls fn on each element. The root itself
+// is not visited
+func walkElements(root *C.xmlNode, fn func(*C.xmlNode)) {
+	cur := root.children
+	for cur != nil {
+		if XMLNodeType(cur._type) == ElementNode {
+			fn(cur)
+
+			if cur.children != nil {
+				cur = cur.children
+				continue
+			}
+		}
+
+		for cur.next == nil {
+			cur = cur.parent
+			if cur == nil || cur == root {
+				return
+			}
+		}
+		cur = cur.next
+	}
+}
+
+// collectElements returns the descendant elements of root for
+// which match returns true, in document order
+func collectElements(root *C.xmlNode, match func(*C.xmlNode) bool) []uintptr {
+	ret := []uintptr(nil)
+	walkElements(root, func(e *C.xmlNode) {
+		if match(e) {
+			ret = append(ret, uintptr(unsafe.Pointer(e)))
+		}
+	})
+	return ret
+}
+
+// XMLGetElementsByTagName returns the descendant elements of n whose
+// qualified name matches name, in document order
+func XMLGetElementsByTagName(n PtrSource, name string) ([]uintptr, error) {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get valid node for XMLGetElementsByTagName")
+	}
+
+	return collectElements(nptr, func(e *C.xmlNode) bool {
+		return matchElementName(e, name)
+	}), nil
+}
+
+// XMLGetElementsByTagNameNS returns the descendant elements of n whose
+// namespace URI and local name match, in document order
+func XMLGetElementsByTagNameNS(n PtrSource, nsuri, local string) ([]uintptr, error) {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get valid node for XMLGetElementsByTagNameNS")
+	}
+
+	return collectElements(nptr, func(e *C.xmlNode) bool {
+		return matchElementNameNS(e, nsuri, local)
+	}), nil
+}
+
+// XMLChildElements returns the element children of n. If name is
+// not empty, only the children whose qualified name matches are returned
+func XMLChildElements(n PtrSource, name string) ([]uintptr, error) {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get valid node for XMLChildElements")
+	}
+
+	ret := []uintptr(nil)
+	for chld := nptr.children; chld != nil; chld = chld.next {
+		if XMLNodeType(chld._type) != ElementNode {
+			continue
+		}
+		if name != "" && !matchElementName(chld, name) {
+			continue
+		}
+		ret = append(ret, uintptr(unsafe.Pointer(chld)))
+	}
+	return ret, nil
+}
+
+// XMLFirstElementChild returns the first child of n that is an element
+func XMLFirstElementChild(n PtrSource) (uintptr, error) {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return 0, err
+	}
+
+	for chld := nptr.children; chld != nil; chld = chld.next {
+		if XMLNodeType(chld._type) == ElementNode {
+			return uintptr(unsafe.Pointer(chld)), nil
+		}
+	}
+	return 0, ErrNodeNotFound
+}
+
+// XMLNextElementSibling returns the first sibling following n that
+// is an element
+func XMLNextElementSibling(n PtrSource) (uintptr, error) {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return 0, err
+	}
+
+	for sib := nptr.next; sib != nil; sib = sib.next {
+		if XMLNodeType(sib._type) == ElementNode {
+			return uintptr(unsafe.Pointer(sib)), nil
+		}
+	}
+	return 0, ErrNodeNotFound
+}
+
+type stringer interface {
+	String() string
+}
+
+func XMLRemoveChild(n PtrSource, t PtrSource) error {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return errors.Wrap(err, "failed to get valid node for XMLRemoveChild")
+	}
+
+	tptr, err := validNodePtr(t)
+	if err != nil {
+		return errors.Wrap(err, "failed to get valid node for XMLRemoveChild")
+	}
+
+	switch XMLNodeType(tptr._type) {
+	case AttributeNode, NamespaceDecl:
+		return nil
+	}
+
+	if tptr.parent != nptr {
+		return nil /* not a child! */
 	}
 
 	unlinkNode(tptr)
@@ -1300,51 +2286,427 @@ func reconcileNsSave(tree *C.xmlNode, unused **C.xmlNs) {
 	}
 }
 
-func SplitPrefixLocal(s string) (string, string) {
-	i := strings.IndexByte(s, ':')
-	if i == -1 {
-		return "", s
+// walkSubtree calls fn on root (if it is an element) and all of
+// its descendant elements, in document order
+func walkSubtree(root *C.xmlNode, fn func(*C.xmlNode)) {
+	if XMLNodeType(root._type) == ElementNode {
+		fn(root)
 	}
-	return s[:i], s[i+1:]
+	walkElements(root, fn)
 }
 
-func XMLNamespaceHref(n PtrSource) string {
-	nsptr, err := validNamespacePtr(n)
+func validSubtreeRootPtr(n PtrSource) (*C.xmlNode, error) {
+	nptr, err := validNodePtr(n)
 	if err != nil {
-		return ""
+		return nil, err
 	}
-	return xmlCharToString(nsptr.href)
-}
 
-func XMLNamespacePrefix(n PtrSource) string {
-	nsptr, err := validNamespacePtr(n)
-	if err != nil {
-		return ""
+	switch XMLNodeType(nptr._type) {
+	case ElementNode, DocumentNode, HTMLDocumentNode, DocbDocumentNode, DocumentFragNode:
+		return nptr, nil
 	}
-	return xmlCharToString(nsptr.prefix)
+	return nil, ErrInvalidNode
 }
 
-func XMLNamespaceFree(n PtrSource) {
-	nsptr, err := validNamespacePtr(n)
+// XMLReconciliateNs makes sure that all namespaces used by the
+// elements and attributes in the tree are declared within the tree,
+// creating new declarations where needed
+func XMLReconciliateNs(n PtrSource) error {
+	nptr, err := validNodePtr(n)
 	if err != nil {
-		return
+		return err
 	}
-	C.MY_xmlFree(unsafe.Pointer(nsptr))
-}
 
-func XMLCreateAttributeNS(doc PtrSource, uri, k, v string) (uintptr, error) {
-	dptr, err := validDocumentPtr(doc)
-	if err != nil {
-		return 0, err
+	if XMLNodeType(nptr._type) != ElementNode {
+		return nil
 	}
 
-	rootptr := C.xmlDocGetRootElement(dptr)
-	if rootptr == nil {
-		return 0, errors.New("no document element found")
+	if nptr.doc == nil {
+		return ErrInvalidDocument
 	}
 
-	if err := isSafeName(k); err != nil {
-		return 0, err
+	if C.xmlReconciliateNs(nptr.doc, nptr) < 0 {
+		return errors.New("failed to reconcile namespaces")
+	}
+	return nil
+}
+
+// XMLDeclareNs creates a namespace declaration on the element,
+// without changing the namespace of the element itself
+func XMLDeclareNs(n PtrSource, nsuri, prefix string) (uintptr, error) {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return 0, err
+	}
+
+	if XMLNodeType(nptr._type) != ElementNode {
+		return 0, ErrInvalidNode
+	}
+
+	return XMLNewNs(n, nsuri, prefix)
+}
+
+// XMLRenameNamespace changes the URI of all namespace declarations
+// within the tree that declare oldURI to newURI. Elements and
+// attributes that use those declarations are affected as well. Those
+// that use a declaration of oldURI outside of the tree are given a
+// new declaration of newURI with the same prefix
+func XMLRenameNamespace(n PtrSource, oldURI, newURI string) error {
+	nptr, err := validSubtreeRootPtr(n)
+	if err != nil {
+		return err
+	}
+
+	if newURI == "" {
+		return errors.New("namespace URI must not be empty")
+	}
+
+	if oldURI == newURI {
+		return nil
+	}
+
+	cnew := stringToXMLChar(newURI)
+	defer C.free(unsafe.Pointer(cnew))
+
+	walkSubtree(nptr, func(e *C.xmlNode) {
+		for ns := e.nsDef; ns != nil; ns = ns.next {
+			if !xmlCharEquals(ns.href, oldURI) {
+				continue
+			}
+			if ns.href != nil {
+				C.MY_xmlFree(unsafe.Pointer(ns.href))
+			}
+			ns.href = C.xmlStrdup(cnew)
+		}
+	})
+
+	// Nodes may also use a declaration of oldURI made by an ancestor
+	// of the tree, which is left alone. Those nodes get a declaration
+	// of newURI with the same prefix on the topmost element of the
+	// tree that contains them
+	anchors := []*C.xmlNode{nptr}
+	if XMLNodeType(nptr._type) != ElementNode {
+		anchors = nil
+		for c := nptr.children; c != nil; c = c.next {
+			if XMLNodeType(c._type) == ElementNode {
+				anchors = append(anchors, c)
+			}
+		}
+	}
+
+	for _, anchor := range anchors {
+		declared := make(map[*C.xmlNs]*C.xmlNs)
+		var err error
+		redeclare := func(ns *C.xmlNs) *C.xmlNs {
+			if ns == nil || err != nil || !xmlCharEquals(ns.href, oldURI) {
+				return ns
+			}
+			if newns, ok := declared[ns]; ok {
+				return newns
+			}
+			newns := C.xmlNewNs(anchor, cnew, ns.prefix)
+			if newns == nil {
+				err = errors.Errorf("failed to declare namespace prefix '%s'", xmlCharToString(ns.prefix))
+				return ns
+			}
+			declared[ns] = newns
+			return newns
+		}
+
+		walkSubtree(anchor, func(e *C.xmlNode) {
+			e.ns = redeclare(e.ns)
+			for attr := e.properties; attr != nil; attr = attr.next {
+				attr.ns = redeclare(attr.ns)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isXMLNamespace(ns *C.xmlNs) bool {
+	return xmlCharEquals(ns.href, "http://www.w3.org/XML/1998/namespace")
+}
+
+// XMLStripNamespaces removes all namespaces from the elements and
+// attributes in the tree, as well as all namespace declarations.
+// Attributes in the XML namespace (e.g. xml:lang) are left untouched.
+// If stripping the namespace of an attribute would result in a
+// duplicate attribute, the namespaced attribute is removed. The
+// removed declarations are kept until the document is freed, as
+// Namespace values may still point at them. Nodes that do not belong
+// to a document are refused
+func XMLStripNamespaces(n PtrSource) error {
+	nptr, err := validSubtreeRootPtr(n)
+	if err != nil {
+		return err
+	}
+	if nptr.doc == nil {
+		return errors.New("node does not belong to a document")
+	}
+
+	// Clear all references first, as the declarations are shared
+	// between elements, and they are retired in the second pass
+	walkSubtree(nptr, func(e *C.xmlNode) {
+		e.ns = nil
+		for attr := e.properties; attr != nil; {
+			next := attr.next
+			if attr.ns != nil && !isXMLNamespace(attr.ns) {
+				if C.xmlHasNsProp(e, attr.name, nil) != nil {
+					C.xmlRemoveProp(attr)
+				} else {
+					attr.ns = nil
+				}
+			}
+			attr = next
+		}
+	})
+
+	walkSubtree(nptr, func(e *C.xmlNode) {
+		for ns := e.nsDef; ns != nil; {
+			next := ns.next
+			retireNs(nptr.doc, ns)
+			ns = next
+		}
+		e.nsDef = nil
+	})
+	return nil
+}
+
+// XMLRemoveUnusedNamespaceDeclarations removes the namespace
+// declarations within the tree that are not used by any element
+// or attribute in the tree. The removed declarations are kept until
+// the document is freed, as Namespace values may still point at them.
+// Nodes that do not belong to a document are refused
+func XMLRemoveUnusedNamespaceDeclarations(n PtrSource) error {
+	nptr, err := validSubtreeRootPtr(n)
+	if err != nil {
+		return err
+	}
+	if nptr.doc == nil {
+		return errors.New("node does not belong to a document")
+	}
+
+	used := make(map[*C.xmlNs]struct{})
+	walkSubtree(nptr, func(e *C.xmlNode) {
+		if e.ns != nil {
+			used[e.ns] = struct{}{}
+		}
+		for attr := e.properties; attr != nil; attr = attr.next {
+			if attr.ns != nil {
+				used[attr.ns] = struct{}{}
+			}
+		}
+	})
+
+	walkSubtree(nptr, func(e *C.xmlNode) {
+		var prev *C.xmlNs
+		for ns := e.nsDef; ns != nil; {
+			next := ns.next
+			if _, ok := used[ns]; ok {
+				prev = ns
+			} else {
+				if prev == nil {
+					e.nsDef = next
+				} else {
+					prev.next = next
+				}
+				retireNs(nptr.doc, ns)
+			}
+			ns = next
+		}
+	})
+	return nil
+}
+
+// removeNode unlinks the node from the tree and frees it
+func removeNode(nptr *C.xmlNode) {
+	C.xmlUnlinkNode(nptr)
+	C.xmlFreeNode(nptr)
+}
+
+// isSpacePreserved returns true if the node is in the scope of an
+// xml:space="preserve" attribute
+func isSpacePreserved(nptr *C.xmlNode) bool {
+	if XMLNodeType(nptr._type) != ElementNode {
+		return false
+	}
+	return C.xmlNodeGetSpacePreserve(nptr) == 1
+}
+
+// eachSubtreeParent calls fn on every node within the tree that
+// may contain text nodes, i.e. the root and the descendant elements
+func eachSubtreeParent(root *C.xmlNode, fn func(*C.xmlNode)) {
+	if XMLNodeType(root._type) != ElementNode {
+		fn(root)
+	}
+	walkSubtree(root, fn)
+}
+
+// XMLNormalize merges adjacent text nodes and removes empty text
+// nodes within the tree
+func XMLNormalize(n PtrSource) error {
+	nptr, err := validSubtreeRootPtr(n)
+	if err != nil {
+		return err
+	}
+
+	eachSubtreeParent(nptr, func(e *C.xmlNode) {
+		for cur := e.children; cur != nil; {
+			if XMLNodeType(cur._type) != TextNode {
+				cur = cur.next
+				continue
+			}
+
+			// xmlTextMerge() does not merge text nodes with different
+			// names, such as those that must not be escaped, so adjacent
+			// nodes are only merged while they are consumed
+			for next := cur.next; next != nil && XMLNodeType(next._type) == TextNode && next.name == cur.name; next = cur.next {
+				if C.xmlTextMerge(cur, next) == nil || cur.next == next {
+					break
+				}
+			}
+
+			next := cur.next
+			if cur.content == nil || *cur.content == 0 {
+				removeNode(cur)
+			}
+			cur = next
+		}
+	})
+	return nil
+}
+
+// XMLStripBlankText removes the text nodes that only contain
+// whitespace within the tree, except where xml:space="preserve"
+// is in effect
+func XMLStripBlankText(n PtrSource) error {
+	nptr, err := validSubtreeRootPtr(n)
+	if err != nil {
+		return err
+	}
+
+	eachSubtreeParent(nptr, func(e *C.xmlNode) {
+		if isSpacePreserved(e) {
+			return
+		}
+
+		for cur := e.children; cur != nil; {
+			next := cur.next
+			if XMLNodeType(cur._type) == TextNode && C.xmlIsBlankNode(cur) == 1 {
+				removeNode(cur)
+			}
+			cur = next
+		}
+	})
+	return nil
+}
+
+func isXMLSpace(c byte) bool {
+	return c == 0x20 || c == 0x9 || c == 0xD || c == 0xA
+}
+
+// collapseWhitespace replaces each run of XML whitespace characters
+// with a single space
+func collapseWhitespace(s string) string {
+	var buf strings.Builder
+	buf.Grow(len(s))
+
+	var inSpace bool
+	for i := 0; i < len(s); i++ {
+		if isXMLSpace(s[i]) {
+			if !inSpace {
+				buf.WriteByte(' ')
+			}
+			inSpace = true
+			continue
+		}
+		inSpace = false
+		buf.WriteByte(s[i])
+	}
+	return buf.String()
+}
+
+// XMLNormalizeWhitespace collapses each run of whitespace within the
+// text nodes of the tree into a single space, except where
+// xml:space="preserve" is in effect
+func XMLNormalizeWhitespace(n PtrSource) error {
+	nptr, err := validSubtreeRootPtr(n)
+	if err != nil {
+		return err
+	}
+
+	eachSubtreeParent(nptr, func(e *C.xmlNode) {
+		if isSpacePreserved(e) {
+			return
+		}
+
+		for cur := e.children; cur != nil; cur = cur.next {
+			if XMLNodeType(cur._type) != TextNode || cur.content == nil {
+				continue
+			}
+
+			content := xmlCharToString(cur.content)
+			collapsed := collapseWhitespace(content)
+			if collapsed == content {
+				continue
+			}
+
+			ccollapsed := stringToXMLChar(collapsed)
+			C.xmlNodeSetContent(cur, ccollapsed)
+			C.free(unsafe.Pointer(ccollapsed))
+		}
+	})
+	return nil
+}
+
+func SplitPrefixLocal(s string) (string, string) {
+	i := strings.IndexByte(s, ':')
+	if i == -1 {
+		return "", s
+	}
+	return s[:i], s[i+1:]
+}
+
+func XMLNamespaceHref(n PtrSource) string {
+	nsptr, err := validNamespacePtr(n)
+	if err != nil {
+		return ""
+	}
+	return xmlCharToString(nsptr.href)
+}
+
+func XMLNamespacePrefix(n PtrSource) string {
+	nsptr, err := validNamespacePtr(n)
+	if err != nil {
+		return ""
+	}
+	return xmlCharToString(nsptr.prefix)
+}
+
+func XMLNamespaceFree(n PtrSource) {
+	nsptr, err := validNamespacePtr(n)
+	if err != nil {
+		return
+	}
+	C.MY_xmlFree(unsafe.Pointer(nsptr))
+}
+
+func XMLCreateAttributeNS(doc PtrSource, uri, k, v string) (uintptr, error) {
+	dptr, err := validDocumentPtr(doc)
+	if err != nil {
+		return 0, err
+	}
+
+	rootptr := C.xmlDocGetRootElement(dptr)
+	if rootptr == nil {
+		return 0, errors.New("no document element found")
+	}
+
+	if err := isSafeName(k); err != nil {
+		return 0, err
 	}
 
 	prefix, local := SplitPrefixLocal(k)
@@ -1513,6 +2875,7 @@ func XMLFreeDoc(doc PtrSource) error {
 	if err != nil {
 		return err
 	}
+	freeRetired(dptr)
 	C.xmlFreeDoc(dptr)
 	return nil
 }
@@ -1660,6 +3023,17 @@ func XMLElementAttributes(n PtrSource) ([]uintptr, error) {
 	return attrs, nil
 }
 
+// XMLFirstAttributePtr returns the first attribute of the element,
+// or 0 if it has none. The following attributes are reached through
+// XMLNextSibling.
+func XMLFirstAttributePtr(n PtrSource) uintptr {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return 0
+	}
+	return uintptr(unsafe.Pointer(nptr.properties))
+}
+
 func XMLElementNamespaces(n PtrSource) ([]uintptr, error) {
 	nptr, err := validNodePtr(n)
 	if err != nil {
@@ -1733,413 +3107,1538 @@ func XMLElementGetAttributeNode(n PtrSource, name string) (uintptr, error) {
 	return uintptr(unsafe.Pointer(prop)), nil
 }
 
-func XMLFreeProp(attr PtrSource) error {
-	nptr, err := validAttributePtr(attr)
-	if err != nil {
-		return err
+// attrValue returns the string value of the attribute. The caller
+// is responsible for freeing the returned value
+func attrValue(aptr *C.xmlAttr) *C.xmlChar {
+	return C.xmlNodeListGetString(aptr.doc, aptr.children, 1)
+}
+
+// lookupID returns the attribute registered in the document's ID
+// table under the given id, or nil
+func lookupID(dptr *C.xmlDoc, id *C.xmlChar) *C.xmlAttr {
+	aptr := C.xmlGetID(dptr, id)
+	// In streaming mode libxml2 returns the document itself
+	if aptr == nil || unsafe.Pointer(aptr) == unsafe.Pointer(dptr) {
+		return nil
 	}
-	C.xmlFreeProp(nptr)
-	return nil
+	return aptr
 }
 
-func XMLFreeNode(n PtrSource) error {
-	nptr, err := validNodePtr(n)
+// XMLGetID returns the element that holds the attribute registered
+// as the ID id in the document's ID table
+func XMLGetID(doc PtrSource, id string) (uintptr, error) {
+	dptr, err := validDocumentPtr(doc)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	C.xmlFreeNode(nptr)
-	return nil
+
+	cid := stringToXMLChar(id)
+	defer C.free(unsafe.Pointer(cid))
+
+	aptr := lookupID(dptr, cid)
+	if aptr == nil || aptr.parent == nil {
+		return 0, ErrNodeNotFound
+	}
+	return uintptr(unsafe.Pointer(aptr.parent)), nil
 }
 
-func XMLUnsetProp(n PtrSource, name string) error {
-	nptr, err := validNodePtr(n)
-	if err != nil {
-		return err
+func addID(aptr *C.xmlAttr) error {
+	if aptr.doc == nil {
+		return ErrInvalidDocument
 	}
 
-	cname := stringToXMLChar(name)
-	defer C.free(unsafe.Pointer(cname))
+	value := attrValue(aptr)
+	if value == nil {
+		return errors.New("attribute has no value")
+	}
+	defer C.MY_xmlFree(unsafe.Pointer(value))
 
-	i := C.xmlUnsetProp(nptr, cname)
-	if i == C.int(0) {
-		return errors.New("failed to unset prop")
+	if lookupID(aptr.doc, value) == aptr {
+		// already registered
+		return nil
+	}
+
+	if C.xmlAddID(nil, aptr.doc, value, aptr) == nil {
+		return errors.Errorf("failed to register ID %s", xmlCharToString(value))
 	}
 	return nil
 }
 
-func XMLUnsetNsProp(n PtrSource, ns PtrSource, name string) error {
-	nptr, err := validNodePtr(n)
+// XMLAddID registers the attribute's value in the document's
+// ID table, marking the attribute as an ID
+func XMLAddID(attr PtrSource) error {
+	aptr, err := validAttributePtr(attr)
 	if err != nil {
 		return err
 	}
+	return addID(aptr)
+}
 
-	nsptr, err := validNamespacePtr(ns)
+// XMLRemoveID removes the attribute from the document's ID table
+func XMLRemoveID(attr PtrSource) error {
+	aptr, err := validAttributePtr(attr)
 	if err != nil {
 		return err
 	}
 
-	cname := stringToXMLChar(name)
-	defer C.free(unsafe.Pointer(cname))
+	if aptr.doc == nil {
+		return ErrInvalidDocument
+	}
 
-	i := C.xmlUnsetNsProp(
-		nptr,
-		nsptr,
-		cname,
-	)
-	if i == C.int(0) {
-		return errors.New("failed to unset prop")
+	if C.xmlRemoveID(aptr.doc, aptr) != 0 {
+		return errors.New("attribute is not registered as an ID")
 	}
 	return nil
 }
 
-func XMLC14NDocDumpMemory(d PtrSource, mode int, withComments bool) (string, error) {
-	dptr, err := validDocumentPtr(d)
-	if err != nil {
-		return "", err
+// XMLIsID returns true if the attribute is registered in the
+// document's ID table
+func XMLIsID(attr PtrSource) bool {
+	aptr, err := validAttributePtr(attr)
+	if err != nil || aptr.doc == nil {
+		return false
 	}
 
-	var result *C.xmlChar
-
-	var withCommentsInt C.int
-	if withComments {
-		withCommentsInt = 1
+	value := attrValue(aptr)
+	if value == nil {
+		return false
 	}
+	defer C.MY_xmlFree(unsafe.Pointer(value))
 
-	modeInt := C.int(mode)
-
-	written := C.xmlC14NDocDumpMemory(
-		dptr,
-		nil,
-		modeInt,
-		nil,
-		withCommentsInt,
-		&result,
-	)
-	if written < 0 {
-		e := C.MY_xmlLastError()
-		return "", errors.New("c14n dump failed: " + C.GoString(e.message))
-	}
-	defer C.MY_xmlFree(unsafe.Pointer(result))
-	return xmlCharToString(result), nil
+	return lookupID(aptr.doc, value) == aptr
 }
 
-func XMLAppendText(n PtrSource, s string) error {
-	nptr, err := validNodePtr(n)
+func registerIDAttributes(doc PtrSource, match func(*C.xmlAttr) bool) error {
+	dptr, err := validDocumentPtr(doc)
 	if err != nil {
 		return err
 	}
 
-	cs := stringToXMLChar(s)
-	defer C.free(unsafe.Pointer(cs))
+	var ret error
+	walkElements((*C.xmlNode)(unsafe.Pointer(dptr)), func(e *C.xmlNode) {
+		for aptr := e.properties; aptr != nil && ret == nil; aptr = aptr.next {
+			if match(aptr) {
+				ret = addID(aptr)
+			}
+		}
+	})
+	return ret
+}
 
-	txt := C.xmlNewText(cs)
-	if txt == nil {
-		return errors.New("failed to create text node")
-	}
+// XMLRegisterIDAttribute registers the values of all attributes in
+// the document whose qualified name matches name as IDs
+func XMLRegisterIDAttribute(doc PtrSource, name string) error {
+	prefix, local := SplitPrefixLocal(name)
+	return registerIDAttributes(doc, func(aptr *C.xmlAttr) bool {
+		if !xmlCharEquals(aptr.name, local) {
+			return false
+		}
+		if aptr.ns == nil || aptr.ns.prefix == nil {
+			return prefix == ""
+		}
+		return xmlCharEquals(aptr.ns.prefix, prefix)
+	})
+}
 
-	if C.xmlAddChild(nptr, (*C.xmlNode)(txt)) == nil {
-		return errors.New("failed to create text node")
-	}
-	return nil
+// XMLRegisterIDAttributeNS registers the values of all attributes in
+// the document in the given namespace with the given local name as IDs
+func XMLRegisterIDAttributeNS(doc PtrSource, nsuri, local string) error {
+	return registerIDAttributes(doc, func(aptr *C.xmlAttr) bool {
+		if !xmlCharEquals(aptr.name, local) {
+			return false
+		}
+		if aptr.ns == nil {
+			return nsuri == ""
+		}
+		return xmlCharEquals(aptr.ns.href, nsuri)
+	})
 }
 
-func XMLDocCopyNode(n PtrSource, d PtrSource, extended int) (uintptr, error) {
-	nptr, err := validNodePtr(n)
-	if err != nil {
-		return 0, err
+// optionalXMLChar converts s to a C string, or returns nil if s is
+// empty. The result must be freed with C.free
+func optionalXMLChar(s string) *C.xmlChar {
+	if s == "" {
+		return nil
 	}
+	return stringToXMLChar(s)
+}
 
-	dptr, err := validDocumentPtr(d)
+// validDtdPtr returns the DTD pointed to by n, or an error if
+// n is not a DTD node
+func validDtdPtr(n PtrSource) (*C.xmlDtd, error) {
+	nptr, err := validNodePtr(n)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	ret := C.xmlDocCopyNode(nptr, dptr, C.int(extended))
-	if ret == nil {
-		return 0, errors.New("copy node failed")
+	if XMLNodeType(nptr._type) != DTDNode {
+		return nil, ErrInvalidNode
 	}
-
-	return uintptr(unsafe.Pointer(ret)), nil
+	return (*C.xmlDtd)(unsafe.Pointer(nptr)), nil
 }
 
-func XMLSetTreeDoc(n PtrSource, d PtrSource) error {
+// validDeclPtr returns n if it is a declaration of the given type
+func validDeclPtr(n PtrSource, typ XMLNodeType) (*C.xmlNode, error) {
 	nptr, err := validNodePtr(n)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dptr, err := validDocumentPtr(d)
-	if err != nil {
-		return err
+	if XMLNodeType(nptr._type) != typ {
+		return nil, ErrInvalidNode
 	}
-
-	C.xmlSetTreeDoc(nptr, dptr)
-	return nil
+	return nptr, nil
 }
 
-func XMLParseInNodeContext(n PtrSource, data string, o int) (uintptr, error) {
-	nptr, err := validNodePtr(n)
+// XMLGetIntSubset returns the internal subset of the document, i.e.
+// the node holding the DOCTYPE declaration
+func XMLGetIntSubset(doc PtrSource) (uintptr, error) {
+	dptr, err := validDocumentPtr(doc)
 	if err != nil {
 		return 0, err
 	}
 
-	var ret C.xmlNodePtr
-	cdata := C.CString(data)
-	defer C.free(unsafe.Pointer(cdata))
-	if C.xmlParseInNodeContext(nptr, cdata, C.int(len(data)), C.int(o), &ret) != 0 {
-		return 0, errors.New("XXX PLACE HOLDER XXX")
+	dtd := C.xmlGetIntSubset(dptr)
+	if dtd == nil {
+		return 0, ErrNodeNotFound
 	}
-
-	return uintptr(unsafe.Pointer(ret)), nil
+	return uintptr(unsafe.Pointer(dtd)), nil
 }
 
-func XMLXPathNewContext(n PtrSource) (uintptr, error) {
-	ctx := C.xmlXPathNewContext(nil)
-	ctx.namespaces = nil
+// XMLCreateIntSubset creates the internal subset of the document.
+// It fails if the document already has one
+func XMLCreateIntSubset(doc PtrSource, name, publicID, systemID string) (uintptr, error) {
+	dptr, err := validDocumentPtr(doc)
+	if err != nil {
+		return 0, err
+	}
 
-	nptr, err := validNodePtr(n)
-	if err == nil {
-		ctx.node = (*C.xmlNode)(unsafe.Pointer(nptr))
+	if dptr.intSubset != nil {
+		return 0, errors.New("document already has an internal subset")
 	}
 
-	return uintptr(unsafe.Pointer(ctx)), nil
+	cname := stringToXMLChar(name)
+	defer C.free(unsafe.Pointer(cname))
+	cpublic := optionalXMLChar(publicID)
+	defer C.free(unsafe.Pointer(cpublic))
+	csystem := optionalXMLChar(systemID)
+	defer C.free(unsafe.Pointer(csystem))
+
+	dtd := C.xmlCreateIntSubset(dptr, cname, cpublic, csystem)
+	if dtd == nil {
+		return 0, errors.New("failed to create internal subset")
+	}
+	return uintptr(unsafe.Pointer(dtd)), nil
 }
 
-func XMLXPathContextSetContextNode(x PtrSource, n PtrSource) error {
-	xptr, err := validXPathContextPtr(x)
+// XMLSetIntSubset replaces the internal subset of the document with
+// a copy of the given DTD, which may belong to another document
+func XMLSetIntSubset(doc PtrSource, dtd PtrSource) error {
+	dptr, err := validDocumentPtr(doc)
 	if err != nil {
 		return err
 	}
 
-	nptr, err := validNodePtr(n)
+	src, err := validDtdPtr(dtd)
 	if err != nil {
 		return err
 	}
 
-	xptr.node = nptr
-	return nil
-}
+	if src == dptr.intSubset {
+		return nil
+	}
 
-func XMLXPathCompile(s string) (uintptr, error) {
-	if len(s) > MaxXPathExpressionLength {
-		return 0, ErrXPathExpressionTooLong
+	cp := C.xmlCopyDtd(src)
+	if cp == nil {
+		return errors.New("failed to copy DTD")
 	}
+	cpnode := (*C.xmlNode)(unsafe.Pointer(cp))
+	C.xmlSetTreeDoc(cpnode, dptr)
 
-	var xcs [MaxXPathExpressionLength]C.xmlChar
-	for i := 0; i < len(s); i++ {
-		xcs[i] = C.xmlChar(s[i])
+	// Entity references and DTD values may still point at the old
+	// subset and its declarations, so it is only freed along with
+	// the document
+	if old := dptr.intSubset; old != nil {
+		oldnode := (*C.xmlNode)(unsafe.Pointer(old))
+		C.xmlUnlinkNode(oldnode)
+		retireDtd(dptr, old)
 	}
-	xcsptr := unsafe.Pointer(&xcs[0])
 
-	if p := C.xmlXPathCompile((*C.xmlChar)(xcsptr)); p != nil {
-		return uintptr(unsafe.Pointer(p)), nil
+	// Like xmlCreateIntSubset, place the declaration before the
+	// document element
+	next := dptr.children
+	for next != nil && XMLNodeType(next._type) != ElementNode {
+		next = next.next
 	}
-	return 0, ErrXPathCompileFailure
+	if next != nil {
+		C.xmlAddPrevSibling(next, cpnode)
+	} else {
+		cp.parent = dptr
+		if dptr.last == nil {
+			dptr.children = cpnode
+		} else {
+			dptr.last.next = cpnode
+			cpnode.prev = dptr.last
+		}
+		dptr.last = cpnode
+	}
+	dptr.intSubset = cp
+	return nil
 }
 
-func XMLXPathFreeCompExpr(x PtrSource) error {
-	xptr, err := validXPathExpressionPtr(x)
+// XMLDtdPublicID returns the public identifier of the DTD
+func XMLDtdPublicID(n PtrSource) string {
+	dtd, err := validDtdPtr(n)
 	if err != nil {
-		return err
+		return ""
 	}
-	C.xmlXPathFreeCompExpr(xptr)
-	return nil
+	return xmlCharToString(dtd.ExternalID)
 }
 
-func XMLXPathFreeContext(x PtrSource) error {
-	xptr, err := validXPathContextPtr(x)
+// XMLDtdSystemID returns the system identifier of the DTD
+func XMLDtdSystemID(n PtrSource) string {
+	dtd, err := validDtdPtr(n)
 	if err != nil {
-		return err
+		return ""
 	}
-	C.xmlXPathFreeContext(xptr)
-	return nil
+	return xmlCharToString(dtd.SystemID)
 }
 
-func XMLXPathNSLookup(x PtrSource, prefix string) (string, error) {
-	xptr, err := validXPathContextPtr(x)
+// XMLGetDtdElementDesc returns the declaration of the named element
+func XMLGetDtdElementDesc(n PtrSource, name string) (uintptr, error) {
+	dtd, err := validDtdPtr(n)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
-	cprefix := stringToXMLChar(prefix)
-	defer C.free(unsafe.Pointer(cprefix))
+	cname := stringToXMLChar(name)
+	defer C.free(unsafe.Pointer(cname))
 
-	if s := C.xmlXPathNsLookup(xptr, cprefix); s != nil {
-		return xmlCharToString(s), nil
+	decl := C.xmlGetDtdElementDesc(dtd, cname)
+	if decl == nil {
+		return 0, ErrNodeNotFound
 	}
-
-	return "", ErrNamespaceNotFound{Target: prefix}
+	return uintptr(unsafe.Pointer(decl)), nil
 }
 
-func XMLXPathRegisterNS(x PtrSource, prefix, nsuri string) error {
-	xptr, err := validXPathContextPtr(x)
+// XMLGetDtdAttrDesc returns the declaration of the named attribute
+// of the named element
+func XMLGetDtdAttrDesc(n PtrSource, elem, name string) (uintptr, error) {
+	dtd, err := validDtdPtr(n)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	if len(prefix) > MaxElementNameLength {
-		return ErrElementNameTooLong
-	}
-	var cprefix [MaxElementNameLength]C.xmlChar
-	for i := 0; i < len(prefix); i++ {
-		cprefix[i] = C.xmlChar(prefix[i])
-	}
-	cprefixptr := unsafe.Pointer(&cprefix[0])
+	celem := stringToXMLChar(elem)
+	defer C.free(unsafe.Pointer(celem))
+	cname := stringToXMLChar(name)
+	defer C.free(unsafe.Pointer(cname))
 
-	if len(nsuri) > MaxNamespaceURILength {
-		return ErrNamespaceURITooLong
+	decl := C.xmlGetDtdAttrDesc(dtd, celem, cname)
+	if decl == nil {
+		return 0, ErrNodeNotFound
 	}
-	var cnsuri [MaxNamespaceURILength]C.xmlChar
-	for i := 0; i < len(nsuri); i++ {
-		cnsuri[i] = C.xmlChar(nsuri[i])
+	return uintptr(unsafe.Pointer(decl)), nil
+}
+
+// XMLGetDocEntity looks up a general entity by name in the internal
+// and external subsets of the document, and in the predefined entities
+func XMLGetDocEntity(doc PtrSource, name string) (uintptr, error) {
+	dptr, err := validDocumentPtr(doc)
+	if err != nil {
+		return 0, err
 	}
-	cnsuriptr := unsafe.Pointer(&cnsuri[0])
 
-	if res := C.xmlXPathRegisterNs(xptr, (*C.xmlChar)(cprefixptr), (*C.xmlChar)(cnsuriptr)); res == -1 {
-		return ErrXPathNamespaceRegisterFailure
+	cname := stringToXMLChar(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	ent := C.xmlGetDocEntity(dptr, cname)
+	if ent == nil {
+		return 0, ErrNodeNotFound
 	}
-	return nil
+	return uintptr(unsafe.Pointer(ent)), nil
 }
 
-func XMLEvalXPath(x PtrSource, expr PtrSource) (uintptr, error) {
-	xptr, err := validXPathContextPtr(x)
+// XMLGetParameterEntity looks up a parameter entity by name in the
+// internal and external subsets of the document
+func XMLGetParameterEntity(doc PtrSource, name string) (uintptr, error) {
+	dptr, err := validDocumentPtr(doc)
 	if err != nil {
 		return 0, err
 	}
 
-	exprptr, err := validXPathExpressionPtr(expr)
+	cname := stringToXMLChar(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	ent := C.xmlGetParameterEntity(dptr, cname)
+	if ent == nil {
+		return 0, ErrNodeNotFound
+	}
+	return uintptr(unsafe.Pointer(ent)), nil
+}
+
+// XMLElementDeclContent returns the content model of the element
+// declaration, as it would appear in the DTD, e.g. "(title, para*)"
+func XMLElementDeclContent(n PtrSource) (string, error) {
+	nptr, err := validDeclPtr(n, ElementDecl)
 	if err != nil {
-		return 0, err
+		return "", err
 	}
 
-	// If there is no document associated with this context,
-	// then xmlXPathCompiledEval() just fails to match
-	if xptr.node != nil && xptr.node.doc != nil {
-		xptr.doc = xptr.node.doc
+	decl := (*C.xmlElement)(unsafe.Pointer(nptr))
+	switch decl.etype {
+	case C.XML_ELEMENT_TYPE_EMPTY:
+		return "EMPTY", nil
+	case C.XML_ELEMENT_TYPE_ANY:
+		return "ANY", nil
 	}
 
-	if xptr.doc == nil {
-		var xcv [3]C.xmlChar = [3]C.xmlChar{'1', '.', '0'}
-		xcvptr := unsafe.Pointer(&xcv[0])
-		xptr.doc = C.xmlNewDoc((*C.xmlChar)(xcvptr))
+	if decl.content == nil {
+		return "", nil
+	}
 
-		defer C.xmlFreeDoc(xptr.doc)
+	var b strings.Builder
+	writeElementContent(&b, decl.content, true)
+	return b.String(), nil
+}
+
+// writeElementContent formats a content model like
+// xmlSnprintfElementContent does, without its size limit
+func writeElementContent(b *strings.Builder, c *C.xmlElementContent, englob bool) {
+	if englob {
+		b.WriteByte('(')
 	}
 
-	res := C.xmlXPathCompiledEval(exprptr, xptr)
-	if res == nil {
-		return 0, ErrXPathEmptyResult
+	switch c._type {
+	case C.XML_ELEMENT_CONTENT_PCDATA:
+		b.WriteString("#PCDATA")
+	case C.XML_ELEMENT_CONTENT_ELEMENT:
+		if c.prefix != nil {
+			b.WriteString(xmlCharToString(c.prefix))
+			b.WriteByte(':')
+		}
+		b.WriteString(xmlCharToString(c.name))
+	case C.XML_ELEMENT_CONTENT_SEQ, C.XML_ELEMENT_CONTENT_OR:
+		sep, other := " , ", C.xmlElementContentType(C.XML_ELEMENT_CONTENT_OR)
+		if c._type == C.XML_ELEMENT_CONTENT_OR {
+			sep, other = " | ", C.XML_ELEMENT_CONTENT_SEQ
+		}
+		c1, c2 := c.c1, c.c2
+		writeElementContent(b, c1, c1._type == C.XML_ELEMENT_CONTENT_OR || c1._type == C.XML_ELEMENT_CONTENT_SEQ)
+		b.WriteString(sep)
+		writeElementContent(b, c2, (c2._type == other || c2.ocur != C.XML_ELEMENT_CONTENT_ONCE) &&
+			c2._type != C.XML_ELEMENT_CONTENT_ELEMENT)
 	}
 
-	return uintptr(unsafe.Pointer(res)), nil
+	if englob {
+		b.WriteByte(')')
+	}
+	switch c.ocur {
+	case C.XML_ELEMENT_CONTENT_OPT:
+		b.WriteByte('?')
+	case C.XML_ELEMENT_CONTENT_MULT:
+		b.WriteByte('*')
+	case C.XML_ELEMENT_CONTENT_PLUS:
+		b.WriteByte('+')
+	}
 }
 
-func XMLXPathFreeObject(x PtrSource) {
-	xptr, err := validXPathObjectPtr(x)
+// XMLAttributeDeclElement returns the name of the element that the
+// attribute declaration applies to
+func XMLAttributeDeclElement(n PtrSource) (string, error) {
+	nptr, err := validDeclPtr(n, AttributeDecl)
 	if err != nil {
-		return
+		return "", err
 	}
-	C.xmlXPathFreeObject(xptr)
-	//	if xptr.nodesetval != nil {
-	//		C.xmlXPathFreeNodeSet(xptr.nodesetval)
-	//	}
+
+	decl := (*C.xmlAttribute)(unsafe.Pointer(nptr))
+	return xmlCharToString(decl.elem), nil
 }
 
-func XMLXPathObjectNodeListLen(x PtrSource) int {
-	xptr, err := validXPathObjectPtr(x)
+// XMLAttributeDeclType returns the type of the attribute declaration,
+// as it would appear in the DTD, e.g. "CDATA" or "ID"
+func XMLAttributeDeclType(n PtrSource) (string, error) {
+	nptr, err := validDeclPtr(n, AttributeDecl)
 	if err != nil {
-		return 0
+		return "", err
 	}
 
-	if xptr.nodesetval == nil {
-		return 0
+	decl := (*C.xmlAttribute)(unsafe.Pointer(nptr))
+	switch decl.atype {
+	case C.XML_ATTRIBUTE_CDATA:
+		return "CDATA", nil
+	case C.XML_ATTRIBUTE_ID:
+		return "ID", nil
+	case C.XML_ATTRIBUTE_IDREF:
+		return "IDREF", nil
+	case C.XML_ATTRIBUTE_IDREFS:
+		return "IDREFS", nil
+	case C.XML_ATTRIBUTE_ENTITY:
+		return "ENTITY", nil
+	case C.XML_ATTRIBUTE_ENTITIES:
+		return "ENTITIES", nil
+	case C.XML_ATTRIBUTE_NMTOKEN:
+		return "NMTOKEN", nil
+	case C.XML_ATTRIBUTE_NMTOKENS:
+		return "NMTOKENS", nil
+	case C.XML_ATTRIBUTE_ENUMERATION:
+		return "ENUMERATION", nil
+	case C.XML_ATTRIBUTE_NOTATION:
+		return "NOTATION", nil
+	}
+	return "", errors.New("unknown attribute type")
+}
+
+// XMLAttributeDeclDefault returns the default declaration of the
+// attribute, i.e. "#REQUIRED", "#IMPLIED", "#FIXED" or an empty
+// string if only a default value is given
+func XMLAttributeDeclDefault(n PtrSource) (string, error) {
+	nptr, err := validDeclPtr(n, AttributeDecl)
+	if err != nil {
+		return "", err
 	}
 
-	return int(xptr.nodesetval.nodeNr)
+	decl := (*C.xmlAttribute)(unsafe.Pointer(nptr))
+	switch decl.def {
+	case C.XML_ATTRIBUTE_REQUIRED:
+		return "#REQUIRED", nil
+	case C.XML_ATTRIBUTE_IMPLIED:
+		return "#IMPLIED", nil
+	case C.XML_ATTRIBUTE_FIXED:
+		return "#FIXED", nil
+	}
+	return "", nil
 }
 
-func XMLXPathObjectType(x PtrSource) XPathObjectType {
-	xptr, err := validXPathObjectPtr(x)
+// XMLAttributeDeclDefaultValue returns the default value of the
+// attribute declaration, if any
+func XMLAttributeDeclDefaultValue(n PtrSource) (string, error) {
+	nptr, err := validDeclPtr(n, AttributeDecl)
 	if err != nil {
-		return XPathUndefinedType
+		return "", err
 	}
-	return XPathObjectType(xptr._type)
+
+	decl := (*C.xmlAttribute)(unsafe.Pointer(nptr))
+	return xmlCharToString(decl.defaultValue), nil
 }
 
-func XMLXPathObjectFloat64(x PtrSource) float64 {
-	xptr, err := validXPathObjectPtr(x)
+// XMLEntityPublicID returns the public identifier of the entity
+func XMLEntityPublicID(n PtrSource) (string, error) {
+	nptr, err := validDeclPtr(n, EntityDecl)
 	if err != nil {
-		return float64(0)
+		return "", err
 	}
 
-	return float64(xptr.floatval)
+	ent := (*C.xmlEntity)(unsafe.Pointer(nptr))
+	return xmlCharToString(ent.ExternalID), nil
 }
 
-func XMLXPathObjectBool(x PtrSource) bool {
-	xptr, err := validXPathObjectPtr(x)
+// XMLEntitySystemID returns the system identifier of the entity
+func XMLEntitySystemID(n PtrSource) (string, error) {
+	nptr, err := validDeclPtr(n, EntityDecl)
 	if err != nil {
-		return false
+		return "", err
 	}
 
-	return xptr.boolval == 1
+	ent := (*C.xmlEntity)(unsafe.Pointer(nptr))
+	return xmlCharToString(ent.SystemID), nil
 }
 
-func XMLXPathObjectNodeList(x PtrSource) ([]uintptr, error) {
-	// Probably needs NodeList iterator
-	xptr, err := validXPathObjectPtr(x)
+// XMLEntityType returns the type of the entity
+func XMLEntityType(n PtrSource) (EntityType, error) {
+	nptr, err := validDeclPtr(n, EntityDecl)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get valid xpath object for XMLXPathObjectNodeList")
+		return 0, err
 	}
 
-	nodeset := xptr.nodesetval
-	if nodeset == nil {
-		return nil, errors.Wrap(ErrInvalidNode, "failed to get valid node for XMLXPathObjectNodeList")
-	}
+	ent := (*C.xmlEntity)(unsafe.Pointer(nptr))
+	return EntityType(ent.etype), nil
+}
 
-	if nodeset.nodeNr == 0 {
-		return nil, errors.Wrap(ErrInvalidNode, "failed to get valid node for XMLXPathObjectNodeList")
+func XMLFreeProp(attr PtrSource) error {
+	nptr, err := validAttributePtr(attr)
+	if err != nil {
+		return err
 	}
+	C.xmlFreeProp(nptr)
+	return nil
+}
 
-	nodes := unsafe.Slice(nodeset.nodeTab, nodeset.nodeNr)
-
-	ret := make([]uintptr, nodeset.nodeNr)
-	for i := 0; i < int(nodeset.nodeNr); i++ {
-		ret[i] = uintptr(unsafe.Pointer(nodes[i]))
+func XMLFreeNode(n PtrSource) error {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return err
 	}
-
-	return ret, nil
+	C.xmlFreeNode(nptr)
+	return nil
 }
 
-func XMLTextData(n PtrSource) string {
+func XMLUnsetProp(n PtrSource, name string) error {
 	nptr, err := validNodePtr(n)
 	if err != nil {
-		return ""
+		return err
 	}
-	return xmlCharToString(nptr.content)
-}
 
-func XMLSchemaParse(buf []byte, options ...option.Interface) (uintptr, error) {
-	var uri string
-	var encoding string
-	var coptions int
+	cname := stringToXMLChar(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	i := C.xmlUnsetProp(nptr, cname)
+	if i == C.int(0) {
+		return errors.New("failed to unset prop")
+	}
+	return nil
+}
+
+func XMLUnsetNsProp(n PtrSource, ns PtrSource, name string) error {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return err
+	}
+
+	nsptr, err := validNamespacePtr(ns)
+	if err != nil {
+		return err
+	}
+
+	cname := stringToXMLChar(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	i := C.xmlUnsetNsProp(
+		nptr,
+		nsptr,
+		cname,
+	)
+	if i == C.int(0) {
+		return errors.New("failed to unset prop")
+	}
+	return nil
+}
+
+func XMLC14NDocDumpMemory(d PtrSource, mode int, withComments bool) (string, error) {
+	dptr, err := validDocumentPtr(d)
+	if err != nil {
+		return "", err
+	}
+
+	var result *C.xmlChar
+
+	var withCommentsInt C.int
+	if withComments {
+		withCommentsInt = 1
+	}
+
+	modeInt := C.int(mode)
+
+	written := C.xmlC14NDocDumpMemory(
+		dptr,
+		nil,
+		modeInt,
+		nil,
+		withCommentsInt,
+		&result,
+	)
+	if written < 0 {
+		e := C.MY_xmlLastError()
+		return "", errors.New("c14n dump failed: " + C.GoString(e.message))
+	}
+	defer C.MY_xmlFree(unsafe.Pointer(result))
+	return xmlCharToString(result), nil
+}
+
+func XMLAppendText(n PtrSource, s string) error {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return err
+	}
+
+	cs := stringToXMLChar(s)
+	defer C.free(unsafe.Pointer(cs))
+
+	txt := C.xmlNewText(cs)
+	if txt == nil {
+		return errors.New("failed to create text node")
+	}
+
+	if C.xmlAddChild(nptr, (*C.xmlNode)(txt)) == nil {
+		return errors.New("failed to create text node")
+	}
+	return nil
+}
+
+func XMLDocCopyNode(n PtrSource, d PtrSource, extended int) (uintptr, error) {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return 0, err
+	}
+
+	dptr, err := validDocumentPtr(d)
+	if err != nil {
+		return 0, err
+	}
+
+	ret := C.xmlDocCopyNode(nptr, dptr, C.int(extended))
+	if ret == nil {
+		return 0, errors.New("copy node failed")
+	}
+
+	return uintptr(unsafe.Pointer(ret)), nil
+}
+
+// XMLCopyDoc creates a copy of the document, including its DTD,
+// encoding, version, standalone flag and URI. If recursive is false,
+// the content of the document is not copied
+func XMLCopyDoc(d PtrSource, recursive bool) (uintptr, error) {
+	dptr, err := validDocumentPtr(d)
+	if err != nil {
+		return 0, err
+	}
+
+	var crecursive C.int
+	if recursive {
+		crecursive = 1
+	}
+
+	ret := C.xmlCopyDoc(dptr, crecursive)
+	if ret == nil {
+		return 0, errors.New("copy document failed")
+	}
+	return uintptr(unsafe.Pointer(ret)), nil
+}
+
+// XMLRemoveAttributes removes all attributes from the element. If
+// deep is true, the attributes of its descendants are removed as well
+func XMLRemoveAttributes(n PtrSource, deep bool) error {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return err
+	}
+
+	if XMLNodeType(nptr._type) != ElementNode {
+		return nil
+	}
+
+	remove := func(e *C.xmlNode) {
+		if e.properties != nil {
+			C.xmlFreePropList(e.properties)
+			e.properties = nil
+		}
+	}
+
+	if !deep {
+		remove(nptr)
+		return nil
+	}
+	walkSubtree(nptr, remove)
+	return nil
+}
+
+func XMLSetTreeDoc(n PtrSource, d PtrSource) error {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return err
+	}
+
+	dptr, err := validDocumentPtr(d)
+	if err != nil {
+		return err
+	}
+
+	C.xmlSetTreeDoc(nptr, dptr)
+	return nil
+}
+
+func XMLParseInNodeContext(n PtrSource, data string, o int) (uintptr, error) {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return 0, err
+	}
+
+	var ret C.xmlNodePtr
+	cdata := C.CString(data)
+	defer C.free(unsafe.Pointer(cdata))
+	if C.xmlParseInNodeContext(nptr, cdata, C.int(len(data)), C.int(o), &ret) != 0 {
+		return 0, errors.New("XXX PLACE HOLDER XXX")
+	}
+
+	return uintptr(unsafe.Pointer(ret)), nil
+}
+
+func XMLXPathNewContext(n PtrSource) (uintptr, error) {
+	ctx := C.xmlXPathNewContext(nil)
+	ctx.namespaces = nil
+
+	nptr, err := validNodePtr(n)
+	if err == nil {
+		ctx.node = (*C.xmlNode)(unsafe.Pointer(nptr))
+	}
+
+	return uintptr(unsafe.Pointer(ctx)), nil
+}
+
+func XMLXPathContextSetContextNode(x PtrSource, n PtrSource) error {
+	xptr, err := validXPathContextPtr(x)
+	if err != nil {
+		return err
+	}
+
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return err
+	}
+
+	xptr.node = nptr
+	return nil
+}
+
+func XMLXPathCompile(s string) (uintptr, error) {
+	if len(s) > MaxXPathExpressionLength {
+		return 0, ErrXPathExpressionTooLong
+	}
+
+	var xcs [MaxXPathExpressionLength]C.xmlChar
+	for i := 0; i < len(s); i++ {
+		xcs[i] = C.xmlChar(s[i])
+	}
+	xcsptr := unsafe.Pointer(&xcs[0])
+
+	if p := C.xmlXPathCompile((*C.xmlChar)(xcsptr)); p != nil {
+		return uintptr(unsafe.Pointer(p)), nil
+	}
+	return 0, ErrXPathCompileFailure
+}
+
+func XMLXPathFreeCompExpr(x PtrSource) error {
+	xptr, err := validXPathExpressionPtr(x)
+	if err != nil {
+		return err
+	}
+	C.xmlXPathFreeCompExpr(xptr)
+	return nil
+}
+
+func XMLXPathFreeContext(x PtrSource) error {
+	xptr, err := validXPathContextPtr(x)
+	if err != nil {
+		return err
+	}
+	xpathContexts.Delete(x.Pointer())
+	C.xmlXPathFreeContext(xptr)
+	return nil
+}
+
+func XMLXPathNSLookup(x PtrSource, prefix string) (string, error) {
+	xptr, err := validXPathContextPtr(x)
+	if err != nil {
+		return "", err
+	}
+
+	cprefix := stringToXMLChar(prefix)
+	defer C.free(unsafe.Pointer(cprefix))
+
+	if s := C.xmlXPathNsLookup(xptr, cprefix); s != nil {
+		return xmlCharToString(s), nil
+	}
+
+	return "", ErrNamespaceNotFound{Target: prefix}
+}
+
+func XMLXPathRegisterNS(x PtrSource, prefix, nsuri string) error {
+	xptr, err := validXPathContextPtr(x)
+	if err != nil {
+		return err
+	}
+
+	if len(prefix) > MaxElementNameLength {
+		return ErrElementNameTooLong
+	}
+	var cprefix [MaxElementNameLength]C.xmlChar
+	for i := 0; i < len(prefix); i++ {
+		cprefix[i] = C.xmlChar(prefix[i])
+	}
+	cprefixptr := unsafe.Pointer(&cprefix[0])
+
+	if len(nsuri) > MaxNamespaceURILength {
+		return ErrNamespaceURITooLong
+	}
+	var cnsuri [MaxNamespaceURILength]C.xmlChar
+	for i := 0; i < len(nsuri); i++ {
+		cnsuri[i] = C.xmlChar(nsuri[i])
+	}
+	cnsuriptr := unsafe.Pointer(&cnsuri[0])
+
+	if res := C.xmlXPathRegisterNs(xptr, (*C.xmlChar)(cprefixptr), (*C.xmlChar)(cnsuriptr)); res == -1 {
+		return ErrXPathNamespaceRegisterFailure
+	}
+	return nil
+}
+
+func XMLEvalXPath(x PtrSource, expr PtrSource) (uintptr, error) {
+	xptr, err := validXPathContextPtr(x)
+	if err != nil {
+		return 0, err
+	}
+
+	exprptr, err := validXPathExpressionPtr(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	// If there is no document associated with this context,
+	// then xmlXPathCompiledEval() just fails to match
+	if xptr.node != nil && xptr.node.doc != nil {
+		xptr.doc = xptr.node.doc
+	}
+
+	if xptr.doc == nil {
+		var xcv [3]C.xmlChar = [3]C.xmlChar{'1', '.', '0'}
+		xcvptr := unsafe.Pointer(&xcv[0])
+		xptr.doc = C.xmlNewDoc((*C.xmlChar)(xcvptr))
+
+		defer C.xmlFreeDoc(xptr.doc)
+	}
+
+	data := lookupXPathContextData(x.Pointer())
+	if data != nil {
+		data.err = nil
+	}
+
+	res := C.xmlXPathCompiledEval(exprptr, xptr)
+	if data != nil && data.err != nil {
+		if res != nil {
+			C.xmlXPathFreeObject(res)
+		}
+		return 0, data.err
+	}
+	if res == nil {
+		return 0, ErrXPathEmptyResult
+	}
+
+	return uintptr(unsafe.Pointer(res)), nil
+}
+
+func XMLXPathFreeObject(x PtrSource) {
+	xptr, err := validXPathObjectPtr(x)
+	if err != nil {
+		return
+	}
+	C.xmlXPathFreeObject(xptr)
+	//	if xptr.nodesetval != nil {
+	//		C.xmlXPathFreeNodeSet(xptr.nodesetval)
+	//	}
+}
+
+func XMLXPathObjectNodeListLen(x PtrSource) int {
+	xptr, err := validXPathObjectPtr(x)
+	if err != nil {
+		return 0
+	}
+
+	if xptr.nodesetval == nil {
+		return 0
+	}
+
+	return int(xptr.nodesetval.nodeNr)
+}
+
+func XMLXPathObjectType(x PtrSource) XPathObjectType {
+	xptr, err := validXPathObjectPtr(x)
+	if err != nil {
+		return XPathUndefinedType
+	}
+	return XPathObjectType(xptr._type)
+}
+
+func XMLXPathObjectFloat64(x PtrSource) float64 {
+	xptr, err := validXPathObjectPtr(x)
+	if err != nil {
+		return float64(0)
+	}
+
+	return float64(xptr.floatval)
+}
+
+func XMLXPathObjectBool(x PtrSource) bool {
+	xptr, err := validXPathObjectPtr(x)
+	if err != nil {
+		return false
+	}
+
+	return xptr.boolval == 1
+}
+
+func XMLXPathObjectNodeList(x PtrSource) ([]uintptr, error) {
+	// Probably needs NodeList iterator
+	xptr, err := validXPathObjectPtr(x)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get valid xpath object for XMLXPathObjectNodeList")
+	}
+
+	nodeset := xptr.nodesetval
+	if nodeset == nil {
+		return nil, errors.Wrap(ErrInvalidNode, "failed to get valid node for XMLXPathObjectNodeList")
+	}
+
+	if nodeset.nodeNr == 0 {
+		return nil, errors.Wrap(ErrInvalidNode, "failed to get valid node for XMLXPathObjectNodeList")
+	}
+
+	nodes := unsafe.Slice(nodeset.nodeTab, nodeset.nodeNr)
+
+	ret := make([]uintptr, nodeset.nodeNr)
+	for i := 0; i < int(nodeset.nodeNr); i++ {
+		ret[i] = uintptr(unsafe.Pointer(nodes[i]))
+	}
+
+	return ret, nil
+}
+
+func XMLTextData(n PtrSource) string {
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return ""
+	}
+	return xmlCharToString(nptr.content)
+}
+
+// XMLSchemaParse parses the XML schema in buf
+func XMLSchemaParse(buf []byte, options ...option.Interface) (uintptr, error) {
+	uri, encoding, coptions := schemaReadOptions(options)
+
+	doc, err := readSchemaDocument(buf, uri, encoding, coptions)
+	if err != nil {
+		return 0, err
+	}
+	return xmlSchemaParseDoc(doc, options)
+}
+
+// XMLSchemaParseDocument parses the XML schema held in the document.
+// The document is copied, so that it may be freed or modified while
+// the schema is in use
+func XMLSchemaParseDocument(document PtrSource, options ...option.Interface) (uintptr, error) {
+	dptr, err := validDocumentPtr(document)
+	if err != nil {
+		return 0, err
+	}
+
+	doc := C.xmlCopyDoc(dptr, 1)
+	if doc == nil {
+		return 0, errors.New("failed to copy document")
+	}
+
+	if uri, _, _ := schemaReadOptions(options); uri != "" {
+		setDocumentURI(doc, uri)
+	}
+	return xmlSchemaParseDoc(doc, options)
+}
+
+// schemaReadOptions returns the options used to read schema documents
+func schemaReadOptions(options []option.Interface) (uri string, encoding string, coptions int) {
 	//nolint:forcetypeassert
 	for _, opt := range options {
 		switch opt.Name() {
 		case option.OptKeyWithURI:
 			uri = opt.Value().(string)
+		case option.OptKeyWithEncoding:
+			encoding = opt.Value().(string)
+		case option.OptKeyWithParserOptions:
+			coptions = opt.Value().(int)
+		}
+	}
+	return uri, encoding, coptions
+}
+
+// setDocumentURI replaces the URI of the document, which relative
+// references are resolved against
+func setDocumentURI(doc *C.xmlDoc, uri string) {
+	if doc.URL != nil {
+		C.MY_xmlFree(unsafe.Pointer(doc.URL))
+	}
+	curi := stringToXMLChar(uri)
+	doc.URL = C.xmlStrdup(curi)
+	C.free(unsafe.Pointer(curi))
+}
+
+// xmlSchemaParseDoc parses the schema held in doc. The document is
+// owned by the resulting schema, and freed along with it. On failure,
+// the document is freed
+func xmlSchemaParseDoc(doc *C.xmlDoc, options []option.Interface) (uintptr, error) {
+	var resolver SchemaResolver
+	var relative bool
+	for _, opt := range options {
+		switch opt.Name() {
+		case option.OptKeyWithResolver:
+			switch v := opt.Value().(type) {
+			case SchemaResolver:
+				resolver, relative = v, false
+			case RelativeSchemaResolver:
+				resolver, relative = SchemaResolver(v), true
+			}
+		}
+	}
+
+	parserCtx := C.xmlSchemaNewDocParserCtxt(doc)
+	if parserCtx == nil {
+		C.xmlFreeDoc(doc)
+		return 0, errors.New("failed to create parser")
+	}
+	defer C.xmlSchemaFreeParserCtxt(parserCtx)
+
+	list := C.MY_createErrorList(0)
+	defer C.MY_freeErrorList(list)
+
+	C.MY_setSchemaParserErrors(parserCtx, list)
+
+	var s *C.xmlSchema
+	var err error
+	if resolver != nil {
+		s, err = xmlSchemaParseWithResolver(parserCtx, doc, resolver, relative)
+	} else {
+		s = C.xmlSchemaParse(parserCtx)
+	}
+	if s == nil {
+		C.xmlFreeDoc(doc)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to parse schema")
+		}
+		return 0, errors.Wrap(firstError(collectErrors(list), "unknown error"), "failed to parse schema")
+	}
+
+	// Schemas parsed from a document keep pointers into it, but do
+	// not free it. Remember it so that XMLSchemaFree can
+	s._private = unsafe.Pointer(doc)
+	return uintptr(unsafe.Pointer(s)), nil
+}
+
+// The resolving entity loader is only installed while schemas are
+// being parsed with a resolver, so that the loaders installed by other
+// code are not overridden
+var (
+	resolvingEntityLoaderMu    sync.Mutex
+	resolvingEntityLoaderUsers int
+)
+
+func acquireResolvingEntityLoader() {
+	resolvingEntityLoaderMu.Lock()
+	defer resolvingEntityLoaderMu.Unlock()
+	if resolvingEntityLoaderUsers == 0 {
+		C.MY_installResolvingEntityLoader()
+	}
+	resolvingEntityLoaderUsers++
+}
+
+func releaseResolvingEntityLoader() {
+	resolvingEntityLoaderMu.Lock()
+	defer resolvingEntityLoaderMu.Unlock()
+	resolvingEntityLoaderUsers--
+	if resolvingEntityLoaderUsers == 0 {
+		C.MY_uninstallResolvingEntityLoader()
+	}
+}
+
+// xmlSchemaParseWithResolver parses the schema, loading the schemas
+// it includes or imports using resolver. The error returned by the
+// resolver, if any, is returned along with a nil schema. If relative is
+// true, locations are made relative to the URI of doc
+func xmlSchemaParseWithResolver(parserCtx *C.xmlSchemaParserCtxt, doc *C.xmlDoc, resolver SchemaResolver, relative bool) (*C.xmlSchema, error) {
+	acquireResolvingEntityLoader()
+	defer releaseResolvingEntityLoader()
+
+	sr := &schemaResolver{
+		resolve:    resolver,
+		namespaces: make(map[string]string),
+	}
+	if relative {
+		sr.base = xmlCharToString(doc.URL)
+	}
+	sr.scan(doc)
+
+	h := cgo.NewHandle(sr)
+	defer h.Delete()
+
+	s := C.MY_parseSchemaWithResolver(parserCtx, C.uintptr_t(h))
+	if s == nil {
+		return nil, sr.err
+	}
+	return s, nil
+}
+
+// xmlSchemaValidate creates a validation context for the schema, and
+// calls fn with it and the list collecting the errors. The errors
+// reported while fn runs are returned if fn returns a non-zero value,
+// or if warnings are reported and are to be treated as errors
+func xmlSchemaValidate(schema PtrSource, options []option.Interface, fn func(*C.xmlSchemaValidCtxt, *C.go_libxml2_error_list) C.int) []error {
+	var vcoptions C.int
+	var maxErrors int
+	var warningsAsErrors bool
+	//nolint:forcetypeassert
+	for _, opt := range options {
+		switch opt.Name() {
+		case option.OptKeyWithDefaultAttributes:
+			if opt.Value().(bool) {
+				vcoptions |= C.XML_SCHEMA_VAL_VC_I_CREATE
+			}
+		case option.OptKeyWithMaxErrors:
+			maxErrors = opt.Value().(int)
+		case option.OptKeyWithWarningsAsErrors:
+			warningsAsErrors = opt.Value().(bool)
 		}
 	}
 
-	docctx := C.xmlCreateMemoryParserCtxt((*C.char)(unsafe.Pointer(&buf[0])), C.int(len(buf)))
-	if docctx == nil {
-		return 0, errors.New("error creating doc parser")
+	sptr, err := validSchemaPtr(schema)
+	if err != nil {
+		return []error{err}
+	}
+
+	ctx := C.xmlSchemaNewValidCtxt(sptr)
+	if ctx == nil {
+		return []error{errors.New("failed to build validator")}
+	}
+	defer C.xmlSchemaFreeValidCtxt(ctx)
+
+	list := C.MY_createErrorList(C.int(maxErrors))
+	defer C.MY_freeErrorList(list)
+
+	C.MY_setSchemaValidErrors(ctx, list)
+	C.xmlSchemaSetValidOptions(ctx, vcoptions)
+
+	if fn(ctx, list) == 0 && (!warningsAsErrors || list.count == 0) {
+		return nil
+	}
+
+	return validationErrors(list)
+}
+
+// XMLSchemaValidateDocument validates the document against the schema.
+// The errors returned are of type StructuredError
+func XMLSchemaValidateDocument(schema PtrSource, document PtrSource, options ...option.Interface) []error {
+	dptr, err := validDocumentPtr(document)
+	if err != nil {
+		return []error{err}
+	}
+
+	return xmlSchemaValidate(schema, options, func(ctx *C.xmlSchemaValidCtxt, _ *C.go_libxml2_error_list) C.int {
+		return C.xmlSchemaValidateDoc(ctx, dptr)
+	})
+}
+
+// XMLSchemaValidateElement validates the element and its descendants
+// against the schema. The element must belong to a document. The
+// errors returned are of type StructuredError
+func XMLSchemaValidateElement(schema PtrSource, elem PtrSource, options ...option.Interface) []error {
+	nptr, err := validNodePtr(elem)
+	if err != nil {
+		return []error{err}
+	}
+
+	if XMLNodeType(nptr._type) != ElementNode || nptr.doc == nil {
+		return []error{ErrInvalidNode}
+	}
+
+	return xmlSchemaValidate(schema, options, func(ctx *C.xmlSchemaValidCtxt, _ *C.go_libxml2_error_list) C.int {
+		return C.xmlSchemaValidateOneElement(ctx, nptr)
+	})
+}
+
+// XMLSchemaValidateStream validates the document read from r against
+// the schema, without building a tree. The errors returned are of
+// type StructuredError, except for errors returned by r
+func XMLSchemaValidateStream(schema PtrSource, r io.Reader, options ...option.Interface) []error {
+	sr := &streamReader{r: r}
+	h := cgo.NewHandle(sr)
+	defer h.Delete()
+
+	errs := xmlSchemaValidate(schema, options, func(ctx *C.xmlSchemaValidCtxt, list *C.go_libxml2_error_list) C.int {
+		return C.MY_validateSchemaStream(ctx, C.uintptr_t(h), list)
+	})
+	if sr.err != nil {
+		errs = append([]error{errors.Wrap(sr.err, "failed to read input")}, errs...)
+	}
+	return errs
+}
+
+// XMLSchemaValidateFile validates the document stored in the given
+// file against the schema, without building a tree. The errors
+// returned are of type StructuredError
+func XMLSchemaValidateFile(schema PtrSource, path string, options ...option.Interface) []error {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	return xmlSchemaValidate(schema, options, func(ctx *C.xmlSchemaValidCtxt, list *C.go_libxml2_error_list) C.int {
+		return C.MY_validateSchemaFile(ctx, cpath, list)
+	})
+}
+
+// validSchemaLikePtr converts the pointer held by any kind of
+// compiled schema to an unsafe.Pointer, returning ErrInvalidSchema
+// if there is none
+func validSchemaLikePtr(schema PtrSource) (unsafe.Pointer, error) {
+	if schema == nil {
+		return nil, ErrInvalidSchema
+	}
+	sptr := schema.Pointer()
+	if sptr == 0 {
+		return nil, ErrInvalidSchema
+	}
+
+	return unsafe.Pointer(sptr), nil
+}
+
+func validSchemaPtr(schema PtrSource) (*C.xmlSchema, error) {
+	ptr, err := validSchemaLikePtr(schema)
+	if err != nil {
+		return nil, err
+	}
+	return (*C.xmlSchema)(ptr), nil
+}
+
+func XMLSchemaFree(s PtrSource) error {
+	sptr, err := validSchemaPtr(s)
+	if err != nil {
+		return err
+	}
+
+	doc := (*C.xmlDoc)(sptr._private)
+	C.xmlSchemaFree(sptr)
+	if doc != nil {
+		C.xmlFreeDoc(doc)
+	}
+	return nil
+}
+
+func XMLCtxtReadMemory(ctx PtrSource, file string, baseURL string, encoding string, options int) (uintptr, error) {
+	ctxptr, err := validParserCtxtPtr(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "not a valid pointer")
+	}
+
+	var cfile, cbaseURL, cencoding *C.char
+	if file != "" {
+		cfile = C.CString(file)
+		defer C.free(unsafe.Pointer(cfile))
+	}
+
+	if baseURL != "" {
+		cbaseURL = C.CString(baseURL)
+		defer C.free(unsafe.Pointer(cbaseURL))
+	}
+
+	if encoding != "" {
+		cencoding = C.CString(encoding)
+		defer C.free(unsafe.Pointer(cencoding))
+	}
+
+	doc := C.xmlCtxtReadMemory(ctxptr, cfile, C.int(len(file)), cbaseURL, cencoding, C.int(options))
+	if doc == nil {
+		return 0, errors.Errorf("failed to read document from memory: %v", xmlCtxtLastError(ctx))
+	}
+	return uintptr(unsafe.Pointer(doc)), nil
+}
+
+// collectErrors converts the errors accumulated in the list into
+// StructuredError values
+func collectErrors(list *C.go_libxml2_error_list) []error {
+	if list.count == 0 {
+		return nil
+	}
+
+	entries := unsafe.Slice(list.errors, int(list.count))
+	errs := make([]error, len(entries))
+	for i, e := range entries {
+		errs[i] = structuredError(&e)
+	}
+	return errs
+}
+
+func structuredError(e *C.go_libxml2_error) StructuredError {
+	return StructuredError{
+		Message: C.GoString(e.message),
+		File:    C.GoString(e.file),
+		Line:    int(e.line),
+		Column:  int(e.column),
+		Code:    int(e.code),
+		Domain:  int(e.domain),
+		Level:   ErrorLevel(e.level),
+		Path:    C.GoString(e.path),
+		Node:    uintptr(e.node),
+	}
+}
+
+// validationErrors returns the errors accumulated in the list after
+// a failed validation. Some failures are not reported through the
+// error handler, so a generic error is returned if the list is empty
+func validationErrors(list *C.go_libxml2_error_list) []error {
+	if errs := collectErrors(list); len(errs) > 0 {
+		return errs
+	}
+	return []error{errors.New("validation failed")}
+}
+
+// firstError returns the first error in errs, or def if it is empty
+func firstError(errs []error, def string) error {
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return errors.New(def)
+}
+
+// XMLParseDTD parses the DTD contained in buf, i.e. the content of an
+// external subset
+func XMLParseDTD(buf []byte) (uintptr, error) {
+	if len(buf) == 0 {
+		return 0, errors.New("empty DTD")
+	}
+
+	list := C.MY_createErrorList(0)
+	defer C.MY_freeErrorList(list)
+
+	cbuf := C.CBytes(buf)
+	defer C.free(cbuf)
+
+	dtd := C.MY_parseDtdMemory((*C.char)(cbuf), C.int(len(buf)), list)
+	if dtd == nil {
+		return 0, firstError(collectErrors(list), "failed to parse DTD")
+	}
+	return uintptr(unsafe.Pointer(dtd)), nil
+}
+
+// XMLParseDTDFile parses the DTD stored in the given file or URL
+func XMLParseDTDFile(path string) (uintptr, error) {
+	list := C.MY_createErrorList(0)
+	defer C.MY_freeErrorList(list)
+
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	dtd := C.MY_parseDtdFile(cpath, list)
+	if dtd == nil {
+		return 0, firstError(collectErrors(list), "failed to parse DTD")
+	}
+	return uintptr(unsafe.Pointer(dtd)), nil
+}
+
+// XMLFreeDtd frees a DTD which does not belong to a document
+func XMLFreeDtd(dtd PtrSource) error {
+	dptr, err := validDtdPtr(dtd)
+	if err != nil {
+		return err
+	}
+	C.xmlFreeDtd(dptr)
+	return nil
+}
+
+// XMLValidateDtd validates the document against the given DTD,
+// ignoring any DTD the document may declare itself
+func XMLValidateDtd(dtd PtrSource, doc PtrSource) []error {
+	dtdptr, err := validDtdPtr(dtd)
+	if err != nil {
+		return []error{err}
+	}
+
+	dptr, err := validDocumentPtr(doc)
+	if err != nil {
+		return []error{err}
+	}
+
+	list := C.MY_createErrorList(0)
+	defer C.MY_freeErrorList(list)
+
+	if C.MY_validateDtd(dptr, dtdptr, list) == 1 {
+		return nil
+	}
+	return validationErrors(list)
+}
+
+// XMLValidateDtdElement validates the element and its descendants
+// against the given DTD
+func XMLValidateDtdElement(dtd PtrSource, n PtrSource) []error {
+	dtdptr, err := validDtdPtr(dtd)
+	if err != nil {
+		return []error{err}
+	}
+
+	nptr, err := validNodePtr(n)
+	if err != nil {
+		return []error{err}
+	}
+
+	if XMLNodeType(nptr._type) != ElementNode || nptr.doc == nil {
+		return []error{ErrInvalidNode}
+	}
+
+	list := C.MY_createErrorList(0)
+	defer C.MY_freeErrorList(list)
+
+	if C.MY_validateDtdElement(nptr.doc, dtdptr, nptr, list) == 1 {
+		return nil
+	}
+	return validationErrors(list)
+}
+
+// XMLValidateDocument validates the document against its own DTD,
+// loading the external subset if necessary
+func XMLValidateDocument(doc PtrSource) []error {
+	dptr, err := validDocumentPtr(doc)
+	if err != nil {
+		return []error{err}
+	}
+
+	list := C.MY_createErrorList(0)
+	defer C.MY_freeErrorList(list)
+
+	if C.MY_validateDocument(dptr, list) == 1 {
+		return nil
+	}
+	return validationErrors(list)
+}
+
+// readSchemaDocument parses the schema document in buf, so that it can
+// be handed to a schema parser. The uri is used to resolve relative
+// references such as includes. The encoding, if not empty, overrides
+// the one declared by the document. The result must be freed by the
+// caller
+func readSchemaDocument(buf []byte, uri string, encoding string, options int) (*C.xmlDoc, error) {
+	if len(buf) == 0 {
+		return nil, errors.New("empty schema")
+	}
+
+	docctx := C.xmlNewParserCtxt()
+	if docctx == nil {
+		return nil, errors.New("error creating doc parser")
+	}
+	defer C.xmlFreeParserCtxt(docctx)
+
+	var curi *C.char
+	if uri != "" {
+		curi = C.CString(uri)
+		defer C.free(unsafe.Pointer(curi))
+	}
+
+	var cencoding *C.char
+	if encoding != "" {
+		cencoding = C.CString(encoding)
+		defer C.free(unsafe.Pointer(cencoding))
+	}
+
+	cbuf := C.CBytes(buf)
+	defer C.free(cbuf)
+
+	doc := C.xmlCtxtReadMemory(docctx, (*C.char)(cbuf), C.int(len(buf)), curi, cencoding, C.int(options))
+	if doc == nil {
+		return nil, errors.Errorf("failed to read schema from memory: %v",
+			xmlCtxtLastErrorRaw(uintptr(unsafe.Pointer(docctx))))
 	}
+	return doc, nil
+}
 
-	var curi *C.char
-	if uri != "" {
-		curi = C.CString(uri)
-		defer C.free(unsafe.Pointer(curi))
+func readSchemaFile(path string, encoding string, options int) (*C.xmlDoc, error) {
+	docctx := C.xmlNewParserCtxt()
+	if docctx == nil {
+		return nil, errors.New("error creating doc parser")
 	}
+	defer C.xmlFreeParserCtxt(docctx)
+
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
 
 	var cencoding *C.char
 	if encoding != "" {
@@ -2147,28 +4646,79 @@ func XMLSchemaParse(buf []byte, options ...option.Interface) (uintptr, error) {
 		defer C.free(unsafe.Pointer(cencoding))
 	}
 
-	doc := C.xmlCtxtReadMemory(docctx, (*C.char)(unsafe.Pointer(&buf[0])), C.int(len(buf)), curi, cencoding, C.int(coptions))
+	doc := C.xmlCtxtReadFile(docctx, cpath, cencoding, C.int(options))
 	if doc == nil {
-		return 0, errors.Errorf("failed to read schema from memory: %v",
+		return nil, errors.Errorf("failed to read schema from file: %v",
 			xmlCtxtLastErrorRaw(uintptr(unsafe.Pointer(docctx))))
 	}
+	return doc, nil
+}
 
-	parserCtx := C.xmlSchemaNewDocParserCtxt((*C.xmlDoc)(unsafe.Pointer(doc)))
-	if parserCtx == nil {
-		return 0, errors.New("failed to create parser")
+func validRelaxNGPtr(schema PtrSource) (*C.xmlRelaxNG, error) {
+	ptr, err := validSchemaLikePtr(schema)
+	if err != nil {
+		return nil, err
 	}
-	defer C.xmlSchemaFreeParserCtxt(parserCtx)
+	return (*C.xmlRelaxNG)(ptr), nil
+}
+
+// xmlRelaxNGParse parses a schema using the given parser context,
+// which is freed afterwards
+func xmlRelaxNGParse(parserCtx C.xmlRelaxNGParserCtxtPtr) (uintptr, error) {
+	defer C.xmlRelaxNGFreeParserCtxt(parserCtx)
 
-	s := C.xmlSchemaParse(parserCtx)
+	list := C.MY_createErrorList(0)
+	defer C.MY_freeErrorList(list)
+	C.MY_setRelaxNGParserErrors(parserCtx, list)
+
+	s := C.xmlRelaxNGParse(parserCtx)
 	if s == nil {
-		return 0, errors.New("failed to parse schema")
+		return 0, errors.Wrap(firstError(collectErrors(list), "unknown error"), "failed to parse schema")
 	}
-
 	return uintptr(unsafe.Pointer(s)), nil
 }
 
-func XMLSchemaValidateDocument(schema PtrSource, document PtrSource, options ...int) []error {
-	sptr, err := validSchemaPtr(schema)
+// XMLRelaxNGParse parses a RELAX NG schema (XML syntax) from buf
+func XMLRelaxNGParse(buf []byte, options ...option.Interface) (uintptr, error) {
+	var uri string
+	//nolint:forcetypeassert
+	for _, opt := range options {
+		switch opt.Name() {
+		case option.OptKeyWithURI:
+			uri = opt.Value().(string)
+		}
+	}
+
+	doc, err := readSchemaDocument(buf, uri, "", 0)
+	if err != nil {
+		return 0, err
+	}
+	// The parser context works on a copy of the document
+	defer C.xmlFreeDoc(doc)
+
+	parserCtx := C.xmlRelaxNGNewDocParserCtxt(doc)
+	if parserCtx == nil {
+		return 0, errors.New("failed to create parser")
+	}
+	return xmlRelaxNGParse(parserCtx)
+}
+
+// XMLRelaxNGParseFromFile parses the RELAX NG schema (XML syntax)
+// stored in the given file
+func XMLRelaxNGParseFromFile(path string) (uintptr, error) {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	parserCtx := C.xmlRelaxNGNewParserCtxt(cpath)
+	if parserCtx == nil {
+		return 0, errors.New("failed to create parser")
+	}
+	return xmlRelaxNGParse(parserCtx)
+}
+
+// XMLRelaxNGValidateDocument validates the document against the schema
+func XMLRelaxNGValidateDocument(schema PtrSource, document PtrSource) []error {
+	sptr, err := validRelaxNGPtr(schema)
 	if err != nil {
 		return []error{err}
 	}
@@ -2178,79 +4728,555 @@ func XMLSchemaValidateDocument(schema PtrSource, document PtrSource, options ...
 		return []error{err}
 	}
 
-	ctx := C.xmlSchemaNewValidCtxt(sptr)
+	ctx := C.xmlRelaxNGNewValidCtxt(sptr)
 	if ctx == nil {
 		return []error{errors.New("failed to build validator")}
 	}
-	defer C.xmlSchemaFreeValidCtxt(ctx)
+	defer C.xmlRelaxNGFreeValidCtxt(ctx)
 
-	accum := C.MY_createErrWarnAccumulator()
-	defer C.MY_freeErrWarnAccumulator(accum)
+	list := C.MY_createErrorList(0)
+	defer C.MY_freeErrorList(list)
+	C.MY_setRelaxNGValidErrors(ctx, list)
 
-	C.MY_setErrWarnAccumulator(ctx, accum)
+	if C.xmlRelaxNGValidateDoc(ctx, dptr) == 0 {
+		return nil
+	}
+	return validationErrors(list)
+}
 
-	for _, option := range options {
-		C.xmlSchemaSetValidOptions(ctx, C.int(option))
+// XMLRelaxNGFree frees the schema
+func XMLRelaxNGFree(s PtrSource) error {
+	sptr, err := validRelaxNGPtr(s)
+	if err != nil {
+		return err
 	}
 
-	if C.xmlSchemaValidateDoc(ctx, dptr) == 0 {
-		return nil
+	C.xmlRelaxNGFree(sptr)
+	return nil
+}
+
+const (
+	schematronNS    = "http://purl.oclc.org/dsdl/schematron"
+	schematronOldNS = "http://www.ascc.net/xml/schematron"
+
+	// The message of each assert and report is prefixed with its
+	// index in the list of tests, enclosed in these separators, so
+	// that the results reported by libxml2 can be mapped back to the
+	// tests. They cannot appear in an XML document
+	schematronMarkerStart = "\x1e"
+	schematronMarkerEnd   = "\x1f"
+)
+
+func isSchematronElement(n *C.xmlNode, name string) bool {
+	if n == nil || XMLNodeType(n._type) != ElementNode || n.ns == nil {
+		return false
+	}
+	if !xmlCharEquals(n.ns.href, schematronNS) && !xmlCharEquals(n.ns.href, schematronOldNS) {
+		return false
 	}
+	return xmlCharEquals(n.name, name)
+}
+
+// noNsProp returns the value of the attribute without a namespace
+func noNsProp(n *C.xmlNode, name string) string {
+	cname := stringToXMLChar(name)
+	defer C.free(unsafe.Pointer(cname))
 
-	errs := make([]error, accum.erridx)
-	for i := 0; i < int(accum.erridx); i++ {
-		errs[i] = errors.New(C.GoString(accum.errors[i]))
+	v := C.xmlGetNoNsProp(n, cname)
+	if v == nil {
+		return ""
 	}
-	return errs
+	defer C.MY_xmlFree(unsafe.Pointer(v))
+	return xmlCharToString(v)
 }
 
-func validSchemaPtr(schema PtrSource) (*C.xmlSchema, error) {
-	if schema == nil {
-		return nil, ErrInvalidSchema
+// prepareSchematron records the asserts and reports found in the
+// schema document, and marks their messages with their index
+func prepareSchematron(doc *C.xmlDoc) ([]SchematronTest, error) {
+	root := C.xmlDocGetRootElement(doc)
+	if root == nil {
+		return nil, errors.New("empty schema")
 	}
-	sptr := schema.Pointer()
-	if sptr == 0 {
-		return nil, ErrInvalidSchema
+
+	var tests []SchematronTest
+	var ferr error
+	walkElements(root, func(n *C.xmlNode) {
+		report := isSchematronElement(n, "report")
+		if ferr != nil || (!report && !isSchematronElement(n, "assert")) {
+			return
+		}
+
+		test := SchematronTest{
+			Report: report,
+			Test:   noNsProp(n, "test"),
+			ID:     noNsProp(n, "id"),
+			Role:   noNsProp(n, "role"),
+		}
+		if rule := n.parent; isSchematronElement(rule, "rule") {
+			test.Context = noNsProp(rule, "context")
+			if pattern := rule.parent; isSchematronElement(pattern, "pattern") {
+				test.Pattern = noNsProp(pattern, "id")
+				if test.Pattern == "" {
+					test.Pattern = noNsProp(pattern, "name")
+				}
+			}
+		}
+
+		cmarker := stringToXMLChar(fmt.Sprintf("%s%d%s", schematronMarkerStart, len(tests), schematronMarkerEnd))
+		defer C.free(unsafe.Pointer(cmarker))
+
+		marker := C.xmlNewDocText(doc, cmarker)
+		if marker == nil {
+			ferr = errors.New("failed to create text node")
+			return
+		}
+		if n.children == nil {
+			C.xmlAddChild(n, marker)
+		} else {
+			// Adjacent text nodes are merged, which is fine
+			C.xmlAddPrevSibling(n.children, marker)
+		}
+		tests = append(tests, test)
+	})
+	if ferr != nil {
+		return nil, ferr
+	}
+	return tests, nil
+}
+
+// xmlSchematronParseDoc parses the schema held in doc. On success,
+// the document is owned by the schema and must be freed along with
+// it. On failure, the document is freed
+func xmlSchematronParseDoc(doc *C.xmlDoc) (uintptr, uintptr, []SchematronTest, error) {
+	tests, err := prepareSchematron(doc)
+	if err != nil {
+		C.xmlFreeDoc(doc)
+		return 0, 0, nil, err
 	}
 
-	return (*C.xmlSchema)(unsafe.Pointer(sptr)), nil
+	list := C.MY_createErrorList(0)
+	defer C.MY_freeErrorList(list)
+
+	// Some versions of libxml2 report problems such as a rule without
+	// a context, but still return a schema
+	s := C.MY_parseSchematron(doc, list)
+	if s == nil || list.count > 0 {
+		if s != nil {
+			C.xmlSchematronFree(s)
+		}
+		C.xmlFreeDoc(doc)
+		return 0, 0, nil, errors.Wrap(firstError(collectErrors(list), "unknown error"), "failed to parse schema")
+	}
+	return uintptr(unsafe.Pointer(s)), uintptr(unsafe.Pointer(doc)), tests, nil
 }
 
-func XMLSchemaFree(s PtrSource) error {
-	sptr, err := validSchemaPtr(s)
+// XMLSchematronParse parses a Schematron schema from buf. It returns
+// the schema, the schema document which must be freed after the
+// schema, and the asserts and reports found in the schema
+func XMLSchematronParse(buf []byte, options ...option.Interface) (uintptr, uintptr, []SchematronTest, error) {
+	var uri string
+	//nolint:forcetypeassert
+	for _, opt := range options {
+		switch opt.Name() {
+		case option.OptKeyWithURI:
+			uri = opt.Value().(string)
+		}
+	}
+
+	doc, err := readSchemaDocument(buf, uri, "", 0)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return xmlSchematronParseDoc(doc)
+}
+
+// XMLSchematronParseFromFile parses the Schematron schema stored in
+// the given file. See XMLSchematronParse for the return values
+func XMLSchematronParseFromFile(path string) (uintptr, uintptr, []SchematronTest, error) {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	doc := C.xmlReadFile(cpath, nil, 0)
+	if doc == nil {
+		return 0, 0, nil, errors.New("failed to read schema from file")
+	}
+	return xmlSchematronParseDoc(doc)
+}
+
+func validSchematronPtr(schema PtrSource) (*C.xmlSchematron, error) {
+	ptr, err := validSchemaLikePtr(schema)
+	if err != nil {
+		return nil, err
+	}
+	return (*C.xmlSchematron)(ptr), nil
+}
+
+// XMLSchematronValidateDocument validates the document against the
+// schema. The asserts that failed and the reports that fired are
+// returned as results, other problems as errors
+func XMLSchematronValidateDocument(schema PtrSource, document PtrSource) ([]SchematronResult, []error) {
+	sptr, err := validSchematronPtr(schema)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	dptr, err := validDocumentPtr(document)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	list := C.MY_createErrorList(0)
+	defer C.MY_freeErrorList(list)
+
+	ret := C.MY_validateSchematron(sptr, dptr, list)
+	if ret == 0 {
+		return nil, nil
+	}
+
+	var results []SchematronResult
+	var errs []error
+	if list.count > 0 {
+		for _, e := range unsafe.Slice(list.errors, int(list.count)) {
+			if e.domain != C.XML_FROM_SCHEMATRONV || (e.code != C.XML_SCHEMATRONV_ASSERT && e.code != C.XML_SCHEMATRONV_REPORT) {
+				errs = append(errs, structuredError(&e))
+				continue
+			}
+
+			msg := C.GoString(e.str3)
+			index := -1
+			if rest, ok := strings.CutPrefix(msg, schematronMarkerStart); ok {
+				if i := strings.Index(rest, schematronMarkerEnd); i > 0 {
+					if _, err := fmt.Sscanf(rest[:i], "%d", &index); err == nil {
+						msg = rest[i+len(schematronMarkerEnd):]
+					}
+				}
+			}
+
+			results = append(results, SchematronResult{
+				Test:    index,
+				Message: strings.TrimSpace(msg),
+				Path:    C.GoString(e.path),
+				Line:    int(e.line),
+				Node:    uintptr(e.node),
+			})
+		}
+	}
+
+	if ret < 0 && len(errs) == 0 {
+		errs = append(errs, errors.New("failed to validate document"))
+	}
+	return results, errs
+}
+
+// XMLSchematronFree frees the schema, along with its document
+func XMLSchematronFree(s PtrSource, doc PtrSource) error {
+	sptr, err := validSchematronPtr(s)
 	if err != nil {
 		return err
 	}
 
-	C.xmlSchemaFree(sptr)
+	C.xmlSchematronFree(sptr)
+	if dptr, err := validDocumentPtr(doc); err == nil {
+		C.xmlFreeDoc(dptr)
+	}
 	return nil
 }
 
-func XMLCtxtReadMemory(ctx PtrSource, file string, baseURL string, encoding string, options int) (uintptr, error) {
-	ctxptr, err := validParserCtxtPtr(ctx)
+// xmlSchemaUnbounded is the value libxml2 uses for maxOccurs="unbounded"
+const xmlSchemaUnbounded = 1 << 30
+
+// XMLSchemaTargetNamespace returns the target namespace of the schema
+func XMLSchemaTargetNamespace(schema PtrSource) (string, error) {
+	sptr, err := validSchemaPtr(schema)
 	if err != nil {
-		return 0, errors.Wrap(err, "not a valid pointer")
+		return "", err
 	}
+	return xmlCharToString(sptr.targetNamespace), nil
+}
 
-	var cfile, cbaseURL, cencoding *C.char
-	if file != "" {
-		cfile = C.CString(file)
-		defer C.free(unsafe.Pointer(cfile))
+func schemaComponentTable(sptr *C.xmlSchema, kind SchemaComponentKind) (C.xmlHashTablePtr, error) {
+	switch kind {
+	case SchemaElementComponent:
+		return sptr.elemDecl, nil
+	case SchemaTypeComponent:
+		return sptr.typeDecl, nil
+	case SchemaAttributeComponent:
+		return sptr.attrDecl, nil
 	}
+	return nil, errors.Errorf("unknown schema component kind %d", kind)
+}
 
-	if baseURL != "" {
-		cbaseURL = C.CString(baseURL)
-		defer C.free(unsafe.Pointer(cbaseURL))
+// XMLSchemaGlobalComponents returns the global components of the given
+// kind declared in the target namespace of the schema, in no
+// particular order. Components of imported schemas are not included
+func XMLSchemaGlobalComponents(schema PtrSource, kind SchemaComponentKind) ([]uintptr, error) {
+	sptr, err := validSchemaPtr(schema)
+	if err != nil {
+		return nil, err
 	}
 
-	if encoding != "" {
-		cencoding = C.CString(encoding)
-		defer C.free(unsafe.Pointer(cencoding))
+	table, err := schemaComponentTable(sptr, kind)
+	if err != nil {
+		return nil, err
 	}
 
-	doc := C.xmlCtxtReadMemory(ctxptr, cfile, C.int(len(file)), cbaseURL, cencoding, C.int(options))
-	if doc == nil {
-		return 0, errors.Errorf("failed to read document from memory: %v", xmlCtxtLastError(ctx))
+	list := C.MY_hashValues(table)
+	if list.items == nil {
+		return nil, nil
 	}
-	return uintptr(unsafe.Pointer(doc)), nil
+	defer C.free(unsafe.Pointer(list.items))
+
+	ret := make([]uintptr, int(list.count))
+	for i, item := range unsafe.Slice(list.items, int(list.count)) {
+		ret[i] = uintptr(item)
+	}
+	return ret, nil
+}
+
+// XMLSchemaLookupComponent returns the global component of the given
+// kind and name declared in the target namespace of the schema
+func XMLSchemaLookupComponent(schema PtrSource, kind SchemaComponentKind, name string) (uintptr, error) {
+	sptr, err := validSchemaPtr(schema)
+	if err != nil {
+		return 0, err
+	}
+
+	table, err := schemaComponentTable(sptr, kind)
+	if err != nil {
+		return 0, err
+	}
+	if table == nil {
+		return 0, ErrNodeNotFound
+	}
+
+	cname := stringToXMLChar(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	ptr := C.xmlHashLookup(table, cname)
+	if ptr == nil {
+		return 0, ErrNodeNotFound
+	}
+	return uintptr(ptr), nil
+}
+
+// schemaDocumentation returns the content of the xs:documentation
+// elements in the annotations
+func schemaDocumentation(annot *C.xmlSchemaAnnot) string {
+	var docs []string
+	for ; annot != nil; annot = annot.next {
+		if annot.content == nil {
+			continue
+		}
+		for n := annot.content.children; n != nil; n = n.next {
+			if !isXMLSchemaElement(n, "documentation") {
+				continue
+			}
+			content := C.xmlNodeGetContent(n)
+			if content == nil {
+				continue
+			}
+			if s := strings.TrimSpace(xmlCharToString(content)); s != "" {
+				docs = append(docs, s)
+			}
+			C.MY_xmlFree(unsafe.Pointer(content))
+		}
+	}
+	return strings.Join(docs, "\n")
+}
+
+// XMLSchemaElementInfo describes the element declaration
+func XMLSchemaElementInfo(elem PtrSource) (SchemaElementInfo, error) {
+	ptr, err := validSchemaLikePtr(elem)
+	if err != nil {
+		return SchemaElementInfo{}, err
+	}
+
+	e := (*C.xmlSchemaElement)(ptr)
+	if e._type != C.XML_SCHEMA_TYPE_ELEMENT {
+		return SchemaElementInfo{}, ErrInvalidNode
+	}
+
+	info := SchemaElementInfo{
+		Name:          xmlCharToString(e.name),
+		Namespace:     xmlCharToString(e.targetNamespace),
+		Type:          uintptr(unsafe.Pointer(e.subtypes)),
+		Global:        e.flags&C.XML_SCHEMAS_ELEM_GLOBAL != 0,
+		Nillable:      e.flags&C.XML_SCHEMAS_ELEM_NILLABLE != 0,
+		Abstract:      e.flags&C.XML_SCHEMAS_ELEM_ABSTRACT != 0,
+		Documentation: schemaDocumentation(e.annot),
+	}
+	if e.flags&C.XML_SCHEMAS_ELEM_FIXED != 0 {
+		info.Fixed = xmlCharToString(e.value)
+	} else {
+		info.Default = xmlCharToString(e.value)
+	}
+	return info, nil
+}
+
+// XMLSchemaAttributeInfo describes the attribute declaration
+func XMLSchemaAttributeInfo(attr PtrSource) (SchemaAttributeInfo, error) {
+	ptr, err := validSchemaLikePtr(attr)
+	if err != nil {
+		return SchemaAttributeInfo{}, err
+	}
+
+	a := (*C.xmlSchemaAttribute)(ptr)
+	if a._type != C.XML_SCHEMA_TYPE_ATTRIBUTE {
+		return SchemaAttributeInfo{}, ErrInvalidNode
+	}
+
+	info := SchemaAttributeInfo{
+		Name:          xmlCharToString(a.name),
+		Namespace:     xmlCharToString(a.targetNamespace),
+		Type:          uintptr(unsafe.Pointer(a.subtypes)),
+		Global:        a.flags&C.XML_SCHEMAS_ATTR_GLOBAL != 0,
+		Documentation: schemaDocumentation(a.annot),
+	}
+	if a.flags&C.XML_SCHEMAS_ATTR_FIXED != 0 {
+		info.Fixed = xmlCharToString(a.defValue)
+	} else {
+		info.Default = xmlCharToString(a.defValue)
+	}
+	return info, nil
+}
+
+// XMLSchemaTypeInfo describes the simple or complex type definition
+func XMLSchemaTypeInfo(typ PtrSource) (SchemaTypeInfo, error) {
+	ptr, err := validSchemaLikePtr(typ)
+	if err != nil {
+		return SchemaTypeInfo{}, err
+	}
+
+	t := (*C.xmlSchemaType)(ptr)
+	switch t._type {
+	case C.XML_SCHEMA_TYPE_BASIC, C.XML_SCHEMA_TYPE_SIMPLE, C.XML_SCHEMA_TYPE_COMPLEX:
+	default:
+		return SchemaTypeInfo{}, ErrInvalidNode
+	}
+
+	info := SchemaTypeInfo{
+		Namespace:     xmlCharToString(t.targetNamespace),
+		Global:        t.flags&C.XML_SCHEMAS_TYPE_GLOBAL != 0,
+		Complex:       t._type == C.XML_SCHEMA_TYPE_COMPLEX,
+		Builtin:       t._type == C.XML_SCHEMA_TYPE_BASIC,
+		ContentType:   SchemaContentType(t.contentType),
+		Base:          uintptr(unsafe.Pointer(t.baseType)),
+		SimpleContent: uintptr(unsafe.Pointer(t.contentTypeDef)),
+		Documentation: schemaDocumentation(t.annot),
+	}
+	// Anonymous types are given internal names
+	if info.Global || info.Builtin {
+		info.Name = xmlCharToString(t.name)
+	}
+	if info.Builtin && info.Namespace == "" {
+		info.Namespace = xmlSchemaNS
+	}
+	if info.Complex && t.subtypes != nil && t.subtypes._type == C.XML_SCHEMA_TYPE_PARTICLE {
+		info.Content = uintptr(unsafe.Pointer(t.subtypes))
+	}
+
+	for f := t.facets; f != nil; f = f.next {
+		info.Facets = append(info.Facets, SchemaFacet{
+			Kind:  SchemaFacetKind(f._type - C.XML_SCHEMA_FACET_MININCLUSIVE + 1),
+			Value: xmlCharToString(f.value),
+		})
+	}
+
+	if info.Complex && t.attrUses != nil {
+		if C.MY_SCHEMA_INTROSPECTION == 0 {
+			return SchemaTypeInfo{}, ErrSchemaIntrospectionUnsupported
+		}
+		uses := (*C.MY_xmlSchemaItemList)(t.attrUses)
+		if uses.nbItems > 0 {
+			for _, item := range unsafe.Slice(uses.items, int(uses.nbItems)) {
+				use := (*C.MY_xmlSchemaAttributeUse)(item)
+				switch use._type {
+				case C.XML_SCHEMA_TYPE_ATTRIBUTE_USE:
+				case C.XML_SCHEMA_EXTRA_ATTR_USE_PROHIB:
+					continue
+				default:
+					return SchemaTypeInfo{}, errors.Errorf("unexpected attribute use type %d", int(use._type))
+				}
+				if use.attrDecl == nil {
+					continue
+				}
+
+				u := SchemaAttributeUseInfo{
+					Declaration: uintptr(unsafe.Pointer(use.attrDecl)),
+					Required:    use.occurs == C.XML_SCHEMAS_ATTR_USE_REQUIRED,
+				}
+				switch {
+				case use.defValue != nil && use.flags&C.MY_XML_SCHEMA_ATTR_USE_FIXED != 0:
+					u.Fixed = xmlCharToString(use.defValue)
+				case use.defValue != nil:
+					u.Default = xmlCharToString(use.defValue)
+				case use.attrDecl.flags&C.XML_SCHEMAS_ATTR_FIXED != 0:
+					u.Fixed = xmlCharToString(use.attrDecl.defValue)
+				default:
+					u.Default = xmlCharToString(use.attrDecl.defValue)
+				}
+				info.Attributes = append(info.Attributes, u)
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// XMLSchemaParticleInfo describes a particle of a content model, as
+// returned by XMLSchemaTypeInfo or XMLSchemaParticleInfo
+func XMLSchemaParticleInfo(particle PtrSource) (SchemaParticleInfo, error) {
+	ptr, err := validSchemaLikePtr(particle)
+	if err != nil {
+		return SchemaParticleInfo{}, err
+	}
+
+	if C.MY_SCHEMA_INTROSPECTION == 0 {
+		return SchemaParticleInfo{}, ErrSchemaIntrospectionUnsupported
+	}
+
+	p := (*C.MY_xmlSchemaParticle)(ptr)
+	if p._type != C.XML_SCHEMA_TYPE_PARTICLE || p.children == nil {
+		return SchemaParticleInfo{}, ErrInvalidNode
+	}
+
+	info := SchemaParticleInfo{
+		MinOccurs: int(p.minOccurs),
+		MaxOccurs: int(p.maxOccurs),
+	}
+	if info.MaxOccurs >= xmlSchemaUnbounded {
+		info.MaxOccurs = -1
+	}
+
+	term := p.children
+	if term._type == C.XML_SCHEMA_TYPE_GROUP {
+		// A reference to a named model group
+		term = term.children
+		if term == nil {
+			return SchemaParticleInfo{}, ErrInvalidNode
+		}
+	}
+
+	switch term._type {
+	case C.XML_SCHEMA_TYPE_ELEMENT:
+		info.Kind = SchemaElementParticle
+		info.Term = uintptr(unsafe.Pointer(term))
+		return info, nil
+	case C.XML_SCHEMA_TYPE_ANY:
+		info.Kind = SchemaAnyParticle
+		return info, nil
+	case C.XML_SCHEMA_TYPE_SEQUENCE:
+		info.Kind = SchemaSequenceParticle
+	case C.XML_SCHEMA_TYPE_CHOICE:
+		info.Kind = SchemaChoiceParticle
+	case C.XML_SCHEMA_TYPE_ALL:
+		info.Kind = SchemaAllParticle
+	default:
+		return SchemaParticleInfo{}, ErrInvalidNode
+	}
+
+	for c := term.children; c != nil; c = c.next {
+		info.Particles = append(info.Particles, uintptr(unsafe.Pointer(c)))
+	}
+	return info, nil
 }
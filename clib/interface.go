@@ -1,6 +1,10 @@
 package clib
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 const (
 	MaxEncodingLength        = 256
@@ -93,6 +97,10 @@ var (
 	ErrXPathEmptyResult              = errors.New("empty xpath result")
 	ErrXPathCompileFailure           = errors.New("xpath compilation failed")
 	ErrXPathNamespaceRegisterFailure = errors.New("cannot register namespace")
+	// ErrSchemaIntrospectionUnsupported is returned when the components
+	// of a compiled schema cannot be walked, because the private layout
+	// of libxml2's schema structures is not known for its version
+	ErrSchemaIntrospectionUnsupported = errors.New("schema introspection is not supported with this libxml2 version")
 )
 
 //nolint:errname
@@ -118,3 +126,292 @@ const (
 	XPathUsersType
 	XPathXSLTTreeType
 )
+
+// XPathValue is a value passed to or returned by a function
+// registered with XMLXPathRegisterFunc. Only the field matching Type
+// is used, and Type must be one of XPathNodeSetType,
+// XPathBooleanType, XPathNumberType or XPathStringType
+type XPathValue struct {
+	Type XPathObjectType
+	// Nodes holds pointers to the nodes of a node set
+	Nodes  []uintptr
+	Bool   bool
+	Number float64
+	String string
+}
+
+// XPathFunction is a Go function that can be called from XPath
+// expressions
+type XPathFunction func(args []XPathValue) (XPathValue, error)
+
+// XPathVariableLookup is a Go function that resolves XPath variables.
+// It returns false if the variable is not defined
+type XPathVariableLookup func(ns, name string) (XPathValue, bool)
+
+// ErrorLevel is the severity of an error reported by libxml2
+type ErrorLevel int
+
+const (
+	ErrorLevelNone ErrorLevel = iota
+	ErrorLevelWarning
+	ErrorLevelError
+	ErrorLevelFatal
+)
+
+func (l ErrorLevel) String() string {
+	switch l {
+	case ErrorLevelNone:
+		return "none"
+	case ErrorLevelWarning:
+		return "warning"
+	case ErrorLevelError:
+		return "error"
+	case ErrorLevelFatal:
+		return "fatal"
+	}
+	return fmt.Sprintf("ErrorLevel(%d)", int(l))
+}
+
+// StructuredError holds the details of a single error reported by
+// libxml2, e.g. while validating a document
+type StructuredError struct {
+	// Message is the error message, without a trailing newline
+	Message string
+	// File is the URI of the document the error was found in, if known
+	File string
+	// Line is the line number the error was found at, or 0
+	Line int
+	// Column is the column number the error was found at, or 0
+	Column int
+	// Code is the libxml2 error code (xmlParserErrors)
+	Code int
+	// Domain is the libxml2 module that reported the error
+	// (xmlErrorDomain)
+	Domain int
+	// Level is the severity of the error
+	Level ErrorLevel
+	// Path is an XPath expression locating the offending node,
+	// if the error is related to a node
+	Path string
+	// Node is a pointer to the offending node, if any. It is only
+	// valid for as long as the document is not freed or modified
+	Node uintptr
+}
+
+// Error implements the error interface. The message is prefixed
+// with the location of the error, if known
+func (e StructuredError) Error() string {
+	var buf strings.Builder
+	switch {
+	case e.File != "":
+		buf.WriteString(e.File)
+		if e.Line > 0 {
+			fmt.Fprintf(&buf, ":%d", e.Line)
+			if e.Column > 0 {
+				fmt.Fprintf(&buf, ":%d", e.Column)
+			}
+		}
+		buf.WriteString(": ")
+	case e.Line > 0:
+		fmt.Fprintf(&buf, "line %d", e.Line)
+		if e.Column > 0 {
+			fmt.Fprintf(&buf, ", column %d", e.Column)
+		}
+		buf.WriteString(": ")
+	}
+	if e.Path != "" {
+		fmt.Fprintf(&buf, "%s: ", e.Path)
+	}
+	buf.WriteString(e.Message)
+	return buf.String()
+}
+
+// SchemaResolver returns the content of a schema included or imported
+// by the schema being parsed. namespace is the target namespace the
+// schema is expected to have, and location is the value of its
+// schemaLocation attribute, resolved against the base URI of the
+// including schema
+type SchemaResolver func(namespace, location string) ([]byte, error)
+
+// RelativeSchemaResolver is a SchemaResolver which is given locations
+// relative to the base URI of the schema being parsed, when it has one
+// and they can be expressed that way
+type RelativeSchemaResolver func(namespace, location string) ([]byte, error)
+
+// SchemaComponentKind identifies a kind of global component of an
+// XML schema
+type SchemaComponentKind int
+
+const (
+	SchemaElementComponent SchemaComponentKind = iota
+	SchemaTypeComponent
+	SchemaAttributeComponent
+)
+
+// SchemaContentType describes what a type allows as content
+type SchemaContentType int
+
+const (
+	SchemaContentUnknown SchemaContentType = iota
+	SchemaContentEmpty
+	SchemaContentElements
+	SchemaContentMixed
+	SchemaContentSimple
+	SchemaContentMixedOrElements
+	SchemaContentBasic
+	SchemaContentAny
+)
+
+// SchemaParticleKind identifies the term of a particle in a content
+// model
+type SchemaParticleKind int
+
+const (
+	SchemaElementParticle SchemaParticleKind = iota + 1
+	SchemaSequenceParticle
+	SchemaChoiceParticle
+	SchemaAllParticle
+	SchemaAnyParticle
+)
+
+// SchemaFacetKind identifies a constraining facet of a simple type
+type SchemaFacetKind int
+
+const (
+	SchemaFacetMinInclusive SchemaFacetKind = iota + 1
+	SchemaFacetMinExclusive
+	SchemaFacetMaxInclusive
+	SchemaFacetMaxExclusive
+	SchemaFacetTotalDigits
+	SchemaFacetFractionDigits
+	SchemaFacetPattern
+	SchemaFacetEnumeration
+	SchemaFacetWhiteSpace
+	SchemaFacetLength
+	SchemaFacetMaxLength
+	SchemaFacetMinLength
+)
+
+// SchemaFacet is a constraining facet of a simple type, such as an
+// enumerated value or a pattern
+type SchemaFacet struct {
+	Kind  SchemaFacetKind
+	Value string
+}
+
+// SchemaElementInfo describes an element declaration
+type SchemaElementInfo struct {
+	Name          string
+	Namespace     string
+	Type          uintptr // *C.xmlSchemaType
+	Global        bool
+	Nillable      bool
+	Abstract      bool
+	Default       string
+	Fixed         string
+	Documentation string
+}
+
+// SchemaAttributeInfo describes an attribute declaration
+type SchemaAttributeInfo struct {
+	Name          string
+	Namespace     string
+	Type          uintptr // *C.xmlSchemaType
+	Global        bool
+	Default       string
+	Fixed         string
+	Documentation string
+}
+
+// SchemaAttributeUseInfo describes the use of an attribute by a
+// complex type
+type SchemaAttributeUseInfo struct {
+	Declaration uintptr // *C.xmlSchemaAttribute
+	Required    bool
+	Default     string
+	Fixed       string
+}
+
+// SchemaTypeInfo describes a simple or complex type definition
+type SchemaTypeInfo struct {
+	Name          string
+	Namespace     string
+	Global        bool
+	Complex       bool
+	Builtin       bool
+	ContentType   SchemaContentType
+	Base          uintptr // *C.xmlSchemaType
+	Content       uintptr // the particle of the content model
+	SimpleContent uintptr // *C.xmlSchemaType
+	Attributes    []SchemaAttributeUseInfo
+	Facets        []SchemaFacet
+	Documentation string
+}
+
+// SchemaParticleInfo describes a particle of a content model. Term
+// is set for elements, and Particles for model groups
+type SchemaParticleInfo struct {
+	Kind      SchemaParticleKind
+	MinOccurs int
+	// MaxOccurs is -1 if unbounded
+	MaxOccurs int
+	Term      uintptr // *C.xmlSchemaElement
+	Particles []uintptr
+}
+
+// SchematronTest describes an assert or report in a Schematron schema
+type SchematronTest struct {
+	// Report is true for reports, and false for asserts
+	Report bool
+	// Pattern is the id (or name) of the enclosing pattern
+	Pattern string
+	// Context is the context expression of the enclosing rule
+	Context string
+	// Test is the XPath expression being tested
+	Test string
+	// ID is the id attribute of the assert or report, if any
+	ID string
+	// Role is the role attribute of the assert or report, if any
+	Role string
+}
+
+// SchematronResult describes a failed assert or a fired report
+type SchematronResult struct {
+	// Test is the index of the assert or report in the list returned
+	// when the schema was parsed, or -1 if it is unknown
+	Test int
+	// Message is the message of the assert or report, with any
+	// name and value-of elements evaluated
+	Message string
+	// Path is an XPath expression locating the node being tested
+	Path string
+	// Line is the line number of the node being tested, or 0
+	Line int
+	// Node is a pointer to the node being tested
+	Node uintptr
+}
+
+// EntityType identifies the kind of an entity declaration
+type EntityType int
+
+const (
+	InternalGeneralEntity EntityType = iota + 1
+	ExternalGeneralParsedEntity
+	ExternalGeneralUnparsedEntity
+	InternalParameterEntity
+	ExternalParameterEntity
+	InternalPredefinedEntity
+)
+
+// DocumentPosition is the bitmask returned when comparing the
+// position of two nodes in a document, as defined by DOM Level 3
+type DocumentPosition int
+
+const (
+	DocumentPositionDisconnected DocumentPosition = 1 << iota
+	DocumentPositionPreceding
+	DocumentPositionFollowing
+	DocumentPositionContains
+	DocumentPositionContainedBy
+	DocumentPositionImplementationSpecific
+)
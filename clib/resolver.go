@@ -0,0 +1,225 @@
+package clib
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+#include <libxml/parser.h>
+#include <libxml/tree.h>
+#include <libxml/uri.h>
+
+static inline void MY_freeXMLChar(xmlChar *p) {
+	xmlFree(p);
+}
+*/
+import "C"
+
+import (
+	"bytes"
+	"encoding/xml"
+	"runtime/cgo"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+const xmlSchemaNS = "http://www.w3.org/2001/XMLSchema"
+
+// schemaResolver holds the state of a schema parse using a
+// SchemaResolver. The namespaces expected of the schemas that are
+// referenced so far are recorded by their resolved location
+type schemaResolver struct {
+	resolve    SchemaResolver
+	namespaces map[string]string
+	// base is the URI locations are made relative to before they are
+	// passed to resolve, if any
+	base string
+	err  error
+}
+
+// scan records the namespaces of the schemas referenced by the
+// xs:include, xs:import, xs:redefine and xs:override elements of doc
+func (sr *schemaResolver) scan(doc *C.xmlDoc) {
+	root := C.xmlDocGetRootElement(doc)
+	if root == nil || !isXMLSchemaElement(root, "schema") {
+		return
+	}
+	targetNamespace := noNsProp(root, "targetNamespace")
+
+	for n := root.children; n != nil; n = n.next {
+		var namespace string
+		switch {
+		case isXMLSchemaElement(n, "import"):
+			namespace = noNsProp(n, "namespace")
+		case isXMLSchemaElement(n, "include"), isXMLSchemaElement(n, "redefine"), isXMLSchemaElement(n, "override"):
+			namespace = targetNamespace
+		default:
+			continue
+		}
+
+		location := noNsProp(n, "schemaLocation")
+		if location == "" {
+			continue
+		}
+		sr.namespaces[resolveLocation(n, location)] = namespace
+	}
+}
+
+// scanReferences records the namespaces of the schemas referenced by
+// the schema in buf, which was loaded from location. References must
+// come before the components of the schema, so only the beginning of
+// the document is read, rather than parsing it with libxml2 before it
+// gets parsed again by the schema parser. xml:base attributes are not
+// taken into account
+func (sr *schemaResolver) scanReferences(buf []byte, location string) {
+	dec := xml.NewDecoder(bytes.NewReader(buf))
+	var targetNamespace string
+	var root bool
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+
+		var start xml.StartElement
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			start = tok
+		case xml.EndElement:
+			return
+		default:
+			continue
+		}
+
+		if start.Name.Space != xmlSchemaNS {
+			return
+		}
+		if !root {
+			if start.Name.Local != "schema" {
+				return
+			}
+			targetNamespace = xmlAttr(start, "targetNamespace")
+			root = true
+			continue
+		}
+
+		var namespace string
+		switch start.Name.Local {
+		case "import":
+			namespace = xmlAttr(start, "namespace")
+		case "include", "redefine", "override":
+			namespace = targetNamespace
+		case "annotation":
+		default:
+			return
+		}
+
+		if ref := xmlAttr(start, "schemaLocation"); ref != "" && start.Name.Local != "annotation" {
+			sr.namespaces[buildURI(ref, location)] = namespace
+		}
+		if err := dec.Skip(); err != nil {
+			return
+		}
+	}
+}
+
+func xmlAttr(start xml.StartElement, name string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// buildURI resolves ref against base
+func buildURI(ref, base string) string {
+	cref := stringToXMLChar(ref)
+	defer C.free(unsafe.Pointer(cref))
+	cbase := stringToXMLChar(base)
+	defer C.free(unsafe.Pointer(cbase))
+
+	uri := C.xmlBuildURI(cref, cbase)
+	if uri == nil {
+		return ref
+	}
+	defer C.MY_freeXMLChar(uri)
+	return xmlCharToString(uri)
+}
+
+// relativeURI expresses uri relative to base, if possible
+func relativeURI(uri, base string) string {
+	curi := stringToXMLChar(uri)
+	defer C.free(unsafe.Pointer(curi))
+	cbase := stringToXMLChar(base)
+	defer C.free(unsafe.Pointer(cbase))
+
+	rel := C.xmlBuildRelativeURI(curi, cbase)
+	if rel == nil {
+		return uri
+	}
+	defer C.MY_freeXMLChar(rel)
+	return xmlCharToString(rel)
+}
+
+func isXMLSchemaElement(n *C.xmlNode, name string) bool {
+	if XMLNodeType(n._type) != ElementNode || n.ns == nil || !xmlCharEquals(n.ns.href, xmlSchemaNS) {
+		return false
+	}
+	return xmlCharEquals(n.name, name)
+}
+
+// resolveLocation resolves location against the base URI of n, the
+// same way libxml2 does when loading a referenced schema
+func resolveLocation(n *C.xmlNode, location string) string {
+	clocation := stringToXMLChar(location)
+	defer C.free(unsafe.Pointer(clocation))
+
+	base := C.xmlNodeGetBase(n.doc, n)
+	if base != nil {
+		defer C.MY_freeXMLChar(base)
+	} else {
+		base = n.doc.URL
+	}
+
+	uri := C.xmlBuildURI(clocation, base)
+	if uri == nil {
+		return location
+	}
+	defer C.MY_freeXMLChar(uri)
+	return xmlCharToString(uri)
+}
+
+// goResolveEntity returns the content of the schema at url in a
+// buffer allocated with malloc, which is NULL if the content is empty.
+// It returns -1 if the schema could not be resolved
+//
+//export goResolveEntity
+func goResolveEntity(ctx C.uintptr_t, url *C.char, data *unsafe.Pointer, size *C.int) C.int {
+	//nolint:forcetypeassert
+	sr := cgo.Handle(ctx).Value().(*schemaResolver)
+	if sr.err != nil {
+		return -1
+	}
+
+	location := C.GoString(url)
+	ref := location
+	if sr.base != "" {
+		ref = relativeURI(location, sr.base)
+	}
+	buf, err := sr.resolve(sr.namespaces[location], ref)
+	if err != nil {
+		sr.err = errors.Wrapf(err, "failed to resolve %s", location)
+		return -1
+	}
+
+	// The schemas referenced by this schema are resolved later,
+	// so their namespaces have to be recorded now
+	sr.scanReferences(buf, location)
+
+	*data = nil
+	*size = C.int(len(buf))
+	if len(buf) > 0 {
+		*data = C.CBytes(buf)
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package clib
+
+/*
+#include <libxml/tree.h>
+*/
+import "C"
+
+import "sync"
+
+// retiredNodes holds the structures that were removed from a document
+// while Go values may still point at them, such as the Namespace
+// wrappers of removed namespace declarations, or the entity references
+// to the declarations of a replaced DTD. They are freed along with the
+// document by XMLFreeDoc
+type retiredNodes struct {
+	ns   []*C.xmlNs
+	dtds []*C.xmlDtd
+}
+
+var retired = struct {
+	mu    sync.Mutex
+	nodes map[*C.xmlDoc]*retiredNodes
+}{
+	nodes: make(map[*C.xmlDoc]*retiredNodes),
+}
+
+// retiredFor returns the retired structures of the document, creating
+// the entry if needed. The caller must hold retired.mu
+func retiredFor(doc *C.xmlDoc) *retiredNodes {
+	r, ok := retired.nodes[doc]
+	if !ok {
+		r = &retiredNodes{}
+		retired.nodes[doc] = r
+	}
+	return r
+}
+
+// retireNs keeps a namespace declaration that was removed from an
+// element of doc until the document is freed
+func retireNs(doc *C.xmlDoc, ns *C.xmlNs) {
+	ns.next = nil
+
+	retired.mu.Lock()
+	defer retired.mu.Unlock()
+	r := retiredFor(doc)
+	r.ns = append(r.ns, ns)
+}
+
+// retireDtd keeps a DTD that was removed from doc until the document
+// is freed
+func retireDtd(doc *C.xmlDoc, dtd *C.xmlDtd) {
+	retired.mu.Lock()
+	defer retired.mu.Unlock()
+	r := retiredFor(doc)
+	r.dtds = append(r.dtds, dtd)
+}
+
+// freeRetired frees the structures retired from doc. It must be called
+// before the document itself is freed
+func freeRetired(doc *C.xmlDoc) {
+	retired.mu.Lock()
+	r, ok := retired.nodes[doc]
+	delete(retired.nodes, doc)
+	retired.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	for _, ns := range r.ns {
+		C.xmlFreeNs(ns)
+	}
+	// DTDs use the dictionary of the document when they are freed
+	for _, dtd := range r.dtds {
+		C.xmlFreeDtd(dtd)
+	}
+}
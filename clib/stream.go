@@ -0,0 +1,49 @@
+package clib
+
+/*
+#include <stdint.h>
+*/
+import "C"
+
+import (
+	"io"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// maxConsecutiveEmptyReads is the number of times Read may return
+// no data and no error before giving up, as bufio does
+const maxConsecutiveEmptyReads = 100
+
+// streamReader feeds the content of an io.Reader to libxml2. Any error
+// other than io.EOF is kept, as libxml2 only knows that reading failed
+type streamReader struct {
+	r   io.Reader
+	err error
+}
+
+//export goReadStream
+func goReadStream(ctx C.uintptr_t, buf *C.char, size C.int) C.int {
+	//nolint:forcetypeassert
+	sr := cgo.Handle(ctx).Value().(*streamReader)
+	if sr.err != nil {
+		return -1
+	}
+
+	b := unsafe.Slice((*byte)(unsafe.Pointer(buf)), int(size))
+	for range maxConsecutiveEmptyReads {
+		n, err := sr.r.Read(b)
+		if n > 0 {
+			return C.int(n)
+		}
+		if err == io.EOF {
+			return 0
+		}
+		if err != nil {
+			sr.err = err
+			return -1
+		}
+	}
+	sr.err = io.ErrNoProgress
+	return -1
+}
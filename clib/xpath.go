@@ -0,0 +1,364 @@
+package clib
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+#include <libxml/tree.h>
+#include <libxml/hash.h>
+#include <libxml/xpath.h>
+#include <libxml/xpathInternals.h>
+
+extern void goXPathFunction(xmlXPathParserContextPtr ctxt, int nargs);
+extern xmlXPathObjectPtr goXPathVariableLookup(xmlXPathContextPtr ctxt, xmlChar *name, xmlChar *ns);
+
+// MY_xpathFunction is registered for all the functions implemented
+// in Go. goXPathFunction finds out which one is being called from
+// the XPath context
+static void MY_xpathFunction(xmlXPathParserContextPtr ctxt, int nargs) {
+	goXPathFunction(ctxt, nargs);
+}
+
+static inline int MY_xpathRegisterFunc(xmlXPathContextPtr ctxt, const xmlChar *name, const xmlChar *ns, int unregister) {
+	return xmlXPathRegisterFuncNS(ctxt, name, ns, unregister ? NULL : MY_xpathFunction);
+}
+
+// MY_xpathVariableLookup replaces the lookup of variables in the
+// XPath context when a Go resolver is set. Registered variables are
+// looked up first, as libxml2 does not fall back on them
+static xmlXPathObjectPtr MY_xpathVariableLookup(void *data, const xmlChar *name, const xmlChar *ns) {
+	xmlXPathContextPtr ctxt = (xmlXPathContextPtr) data;
+	xmlXPathObjectPtr obj;
+
+	obj = (xmlXPathObjectPtr) xmlHashLookup2(ctxt->varHash, name, ns);
+	if (obj != NULL) {
+		return xmlXPathObjectCopy(obj);
+	}
+	return goXPathVariableLookup(ctxt, (xmlChar *) name, (xmlChar *) ns);
+}
+
+static inline void MY_xpathRegisterVariableLookup(xmlXPathContextPtr ctxt, int unregister) {
+	if (unregister) {
+		xmlXPathRegisterVariableLookup(ctxt, NULL, NULL);
+	} else {
+		xmlXPathRegisterVariableLookup(ctxt, MY_xpathVariableLookup, ctxt);
+	}
+}
+
+static inline xmlXPathObjectPtr MY_xpathNewNodeSet(uintptr_t *nodes, int n) {
+	xmlXPathObjectPtr obj;
+	int i;
+
+	obj = xmlXPathNewNodeSet(NULL);
+	if (obj == NULL) {
+		return NULL;
+	}
+	for (i = 0; i < n; i++) {
+		if (nodes[i] != 0) {
+			xmlXPathNodeSetAdd(obj->nodesetval, (xmlNodePtr) nodes[i]);
+		}
+	}
+	return obj;
+}
+
+static inline void MY_xpathFreeXMLChar(xmlChar *p) {
+	xmlFree(p);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// xpathContextData holds the Go values attached to an XPath context
+type xpathContextData struct {
+	functions      map[xpathName]XPathFunction
+	variableLookup XPathVariableLookup
+	// err is the error returned by a Go function during the current
+	// evaluation, which aborts it
+	err error
+}
+
+type xpathName struct {
+	ns   string
+	name string
+}
+
+// xpathContexts maps XPath contexts to their xpathContextData
+var xpathContexts sync.Map
+
+func lookupXPathContextData(ctx uintptr) *xpathContextData {
+	if v, ok := xpathContexts.Load(ctx); ok {
+		//nolint:forcetypeassert
+		return v.(*xpathContextData)
+	}
+	return nil
+}
+
+func getXPathContextData(ctx uintptr) *xpathContextData {
+	v, _ := xpathContexts.LoadOrStore(ctx, &xpathContextData{
+		functions: make(map[xpathName]XPathFunction),
+	})
+	//nolint:forcetypeassert
+	return v.(*xpathContextData)
+}
+
+// XMLXPathRegisterFunc registers a Go function that can be called
+// from XPath expressions evaluated against the context. If fn is nil,
+// the function is unregistered
+func XMLXPathRegisterFunc(x PtrSource, ns, name string, fn XPathFunction) error {
+	xptr, err := validXPathContextPtr(x)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errors.New("empty function name")
+	}
+
+	cname := stringToXMLChar(name)
+	defer C.free(unsafe.Pointer(cname))
+	var cns *C.xmlChar
+	if ns != "" {
+		cns = stringToXMLChar(ns)
+		defer C.free(unsafe.Pointer(cns))
+	}
+
+	unregister := C.int(0)
+	if fn == nil {
+		unregister = 1
+	}
+	if C.MY_xpathRegisterFunc(xptr, cname, cns, unregister) != 0 {
+		return errors.Errorf("failed to register function %s", name)
+	}
+
+	data := getXPathContextData(x.Pointer())
+	key := xpathName{ns: ns, name: name}
+	if fn == nil {
+		delete(data.functions, key)
+	} else {
+		data.functions[key] = fn
+	}
+	return nil
+}
+
+// XMLXPathRegisterVariable registers a variable that can be referenced
+// from XPath expressions evaluated against the context. If v is nil,
+// the variable is unregistered
+func XMLXPathRegisterVariable(x PtrSource, ns, name string, v *XPathValue) error {
+	xptr, err := validXPathContextPtr(x)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errors.New("empty variable name")
+	}
+
+	cname := stringToXMLChar(name)
+	defer C.free(unsafe.Pointer(cname))
+	var cns *C.xmlChar
+	if ns != "" {
+		cns = stringToXMLChar(ns)
+		defer C.free(unsafe.Pointer(cns))
+	}
+
+	var obj *C.xmlXPathObject
+	if v != nil {
+		obj, err = xpathNewObject(*v)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The context takes ownership of obj, and frees the previous value.
+	// Unregistering a variable that does not exist is not an error
+	if C.xmlXPathRegisterVariableNS(xptr, cname, cns, obj) != 0 && obj != nil {
+		C.xmlXPathFreeObject(obj)
+		return errors.Errorf("failed to register variable %s", name)
+	}
+	return nil
+}
+
+// XMLXPathRegisterVariableLookup sets a Go function that resolves the
+// variables that are not registered with XMLXPathRegisterVariable. If
+// fn is nil, the function is unset
+func XMLXPathRegisterVariableLookup(x PtrSource, fn XPathVariableLookup) error {
+	xptr, err := validXPathContextPtr(x)
+	if err != nil {
+		return err
+	}
+
+	unregister := C.int(0)
+	if fn == nil {
+		unregister = 1
+	}
+	C.MY_xpathRegisterVariableLookup(xptr, unregister)
+
+	getXPathContextData(x.Pointer()).variableLookup = fn
+	return nil
+}
+
+//export goXPathVariableLookup
+func goXPathVariableLookup(ctx *C.xmlXPathContext, name *C.xmlChar, ns *C.xmlChar) *C.xmlXPathObject {
+	data := lookupXPathContextData(uintptr(unsafe.Pointer(ctx)))
+	if data == nil || data.variableLookup == nil {
+		return nil
+	}
+
+	gns := xmlCharToString(ns)
+	gname := xmlCharToString(name)
+	v, ok, err := callXPathVariableLookup(data.variableLookup, gns, gname)
+	if err != nil {
+		data.err = errors.Wrapf(err, "failed to resolve XPath variable %s", gname)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+
+	obj, err := xpathNewObject(v)
+	if err != nil {
+		data.err = errors.Wrapf(err, "failed to resolve XPath variable %s", gname)
+		return nil
+	}
+	return obj
+}
+
+// callXPathVariableLookup calls fn, turning panics into errors as they
+// cannot go through the C stack
+func callXPathVariableLookup(fn XPathVariableLookup, ns, name string) (res XPathValue, ok bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	res, ok = fn(ns, name)
+	return res, ok, nil
+}
+
+//export goXPathFunction
+func goXPathFunction(ctxt *C.xmlXPathParserContext, nargs C.int) {
+	ctx := ctxt.context
+	data := lookupXPathContextData(uintptr(unsafe.Pointer(ctx)))
+	key := xpathName{
+		ns:   xmlCharToString(ctx.functionURI),
+		name: xmlCharToString(ctx.function),
+	}
+
+	var fn XPathFunction
+	if data != nil {
+		fn = data.functions[key]
+	}
+	if fn == nil {
+		C.xmlXPathErr(ctxt, C.XPATH_UNKNOWN_FUNC_ERROR)
+		return
+	}
+
+	// Arguments are on the stack in reverse order. The objects hold
+	// on to copies of namespace nodes, so they are only freed once
+	// the function returns
+	args := make([]XPathValue, int(nargs))
+	for i := len(args) - 1; i >= 0; i-- {
+		obj := C.valuePop(ctxt)
+		if obj == nil {
+			C.xmlXPathErr(ctxt, C.XPATH_STACK_ERROR)
+			return
+		}
+		defer C.xmlXPathFreeObject(obj)
+		args[i] = xpathValue(obj)
+	}
+
+	res, err := callXPathFunction(fn, args)
+	if err == nil {
+		err = xpathPushValue(ctxt, res)
+	}
+	if err != nil {
+		data.err = errors.Wrapf(err, "failed to call XPath function %s", key.name)
+		// Abort the evaluation without having libxml2 report an error
+		ctxt.error = C.XPATH_EXPR_ERROR
+	}
+}
+
+// callXPathFunction calls fn, turning panics into errors as they
+// cannot go through the C stack
+func callXPathFunction(fn XPathFunction, args []XPathValue) (res XPathValue, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(args)
+}
+
+// xpathValue converts an XPath object. Node sets hold the pointers
+// to the nodes, in document order
+func xpathValue(obj *C.xmlXPathObject) XPathValue {
+	switch XPathObjectType(obj._type) {
+	case XPathNodeSetType, XPathXSLTTreeType:
+		v := XPathValue{Type: XPathNodeSetType}
+		if set := obj.nodesetval; set != nil && set.nodeNr > 0 {
+			nodes := unsafe.Slice(set.nodeTab, int(set.nodeNr))
+			v.Nodes = make([]uintptr, len(nodes))
+			for i, n := range nodes {
+				v.Nodes[i] = uintptr(unsafe.Pointer(n))
+			}
+		}
+		return v
+	case XPathBooleanType:
+		return XPathValue{Type: XPathBooleanType, Bool: obj.boolval != 0}
+	case XPathNumberType:
+		return XPathValue{Type: XPathNumberType, Number: float64(obj.floatval)}
+	case XPathStringType:
+		return XPathValue{Type: XPathStringType, String: xmlCharToString(obj.stringval)}
+	default:
+		s := C.xmlXPathCastToString(obj)
+		defer C.MY_xpathFreeXMLChar(s)
+		return XPathValue{Type: XPathStringType, String: xmlCharToString(s)}
+	}
+}
+
+// xpathNewObject converts the value to an XPath object
+func xpathNewObject(v XPathValue) (*C.xmlXPathObject, error) {
+	var obj *C.xmlXPathObject
+	switch v.Type {
+	case XPathNodeSetType:
+		var nodes *C.uintptr_t
+		if len(v.Nodes) > 0 {
+			nodes = (*C.uintptr_t)(unsafe.Pointer(&v.Nodes[0]))
+		}
+		obj = C.MY_xpathNewNodeSet(nodes, C.int(len(v.Nodes)))
+	case XPathBooleanType:
+		b := C.int(0)
+		if v.Bool {
+			b = 1
+		}
+		obj = C.xmlXPathNewBoolean(b)
+	case XPathNumberType:
+		obj = C.xmlXPathNewFloat(C.double(v.Number))
+	case XPathStringType:
+		s := stringToXMLChar(v.String)
+		defer C.free(unsafe.Pointer(s))
+		obj = C.xmlXPathNewString(s)
+	default:
+		return nil, errors.Errorf("unsupported value type %d", v.Type)
+	}
+
+	if obj == nil {
+		return nil, errors.New("failed to create XPath object")
+	}
+	return obj, nil
+}
+
+// xpathPushValue converts the value to an XPath object, and pushes it
+// onto the stack of the parser context
+func xpathPushValue(ctxt *C.xmlXPathParserContext, v XPathValue) error {
+	obj, err := xpathNewObject(v)
+	if err != nil {
+		return err
+	}
+	C.valuePush(ctxt, obj)
+	return nil
+}
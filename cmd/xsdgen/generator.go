@@ -0,0 +1,724 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lestrrat-go/libxml2/xsd"
+)
+
+// decl is a Go type to be generated
+type decl struct {
+	name   string
+	source string
+	doc    string
+
+	// Simple types are generated as a named type with underlying
+	// type underlying. basic is the predeclared Go type at the
+	// bottom of the chain, and consts hold the enumerated values
+	underlying string
+	basic      string
+	consts     []enumConst
+
+	// Complex types and elements are generated as structs
+	isStruct bool
+	fields   []field
+	// element is set for the structs of global elements
+	element   string
+	namespace string
+}
+
+type enumConst struct {
+	name string
+	// value is the Go expression of the value. Values that cannot be
+	// expressed as constants, such as NaN, are generated as variables
+	value string
+	isVar bool
+}
+
+type field struct {
+	name string
+	typ  string
+	tag  string
+	doc  string
+}
+
+type generator struct {
+	pkg     string
+	sources []string
+	decls   map[string]*decl
+	// taken holds the identifiers used at the package level
+	taken map[string]struct{}
+	// types and elements map the qualified names of global
+	// components to the names of their Go types
+	types    map[string]string
+	elements map[string]string
+	anyType  string
+	// err is the first error reported while walking the schemas
+	err error
+}
+
+func newGenerator(pkg string) *generator {
+	g := &generator{
+		pkg:      pkg,
+		decls:    make(map[string]*decl),
+		taken:    make(map[string]struct{}),
+		types:    make(map[string]string),
+		elements: make(map[string]string),
+	}
+	for _, name := range []string{"SetSchema", "schemas", "schemasMu", "validate"} {
+		g.taken[name] = struct{}{}
+	}
+	return g
+}
+
+// addSchema generates types for the global types and elements of s,
+// which was parsed from path
+func (g *generator) addSchema(path string, s *xsd.Schema) error {
+	g.sources = append(g.sources, filepath.Base(path))
+	for _, t := range s.Types() {
+		g.typeRef(t, "", "")
+	}
+	for _, e := range s.Elements() {
+		g.element(e)
+	}
+	return g.err
+}
+
+// check records err, if it is the first error reported while walking
+// the schemas. The walk goes on with the zero values returned along
+// with the error
+func (g *generator) check(err error) {
+	if err != nil && g.err == nil {
+		g.err = err
+	}
+}
+
+// reserve returns a package level identifier based on name that is
+// not used yet. If name is taken, suffix is appended, and then a
+// number
+func (g *generator) reserve(name, suffix string) string {
+	candidates := []string{name}
+	if suffix != "" {
+		candidates = append(candidates, name+suffix)
+	}
+	for _, c := range candidates {
+		if _, ok := g.taken[c]; !ok {
+			g.taken[c] = struct{}{}
+			return c
+		}
+	}
+	for i := 2; ; i++ {
+		c := name + strconv.Itoa(i)
+		if _, ok := g.taken[c]; !ok {
+			g.taken[c] = struct{}{}
+			return c
+		}
+	}
+}
+
+func (g *generator) add(d *decl) {
+	g.decls[d.name] = d
+}
+
+// element returns the name of the struct generated for the global
+// element e
+func (g *generator) element(e *xsd.ElementDeclaration) string {
+	key := qualifiedName(e.Namespace, e.Name)
+	if name, ok := g.elements[key]; ok {
+		return name
+	}
+
+	name := g.reserve(goName(e.Name), "Element")
+	g.elements[key] = name
+	d := &decl{
+		name:      name,
+		source:    fmt.Sprintf("element %q", e.Name),
+		doc:       e.Documentation,
+		isStruct:  true,
+		element:   key,
+		namespace: e.Namespace,
+	}
+	g.add(d)
+
+	t, err := e.Type()
+	g.check(err)
+	switch {
+	case isAnyType(t):
+		d.fields = g.anyFields()
+	case t.Complex && t.Name != "":
+		// Embed the struct of the named type
+		d.fields = []field{{typ: g.typeRef(t, "", "")}}
+	case t.Complex:
+		d.fields = g.complexFields(t, name)
+	default:
+		d.fields = []field{{
+			name: "Value",
+			typ:  g.typeRef(t, name+"Value", fmt.Sprintf("the anonymous type of element %q", e.Name)),
+			tag:  `xml:",chardata"`,
+		}}
+	}
+	return name
+}
+
+// typeRef returns the Go type for t, generating it if needed.
+// Anonymous types are named after context
+func (g *generator) typeRef(t *xsd.TypeDefinition, context, source string) string {
+	switch {
+	case isAnyType(t):
+		return g.anyContent()
+	case t.Builtin:
+		return builtinType(t.Name)
+	}
+
+	if t.Name == "" {
+		name := g.reserve(context, "Type")
+		g.defineType(name, source, t)
+		return name
+	}
+
+	key := qualifiedName(t.Namespace, t.Name)
+	if name, ok := g.types[key]; ok {
+		return name
+	}
+	name := g.reserve(goName(t.Name), "Type")
+	g.types[key] = name
+	kind := "simple"
+	if t.Complex {
+		kind = "complex"
+	}
+	g.defineType(name, fmt.Sprintf("%s type %q", kind, t.Name), t)
+	return name
+}
+
+func (g *generator) defineType(name, source string, t *xsd.TypeDefinition) {
+	d := &decl{
+		name:   name,
+		source: source,
+		doc:    t.Documentation,
+	}
+	g.add(d)
+
+	if t.Complex {
+		d.isStruct = true
+		d.fields = g.complexFields(t, name)
+		return
+	}
+
+	d.underlying, d.basic = g.simpleBase(t, name)
+	for _, f := range t.Facets {
+		if f.Kind != xsd.FacetEnumeration {
+			continue
+		}
+		value, isVar, ok := enumValue(d.basic, f.Value)
+		if !ok {
+			// The value cannot be represented by the Go type
+			continue
+		}
+		suffix := camelCase(f.Value)
+		if d.basic != "string" && strings.HasPrefix(strings.TrimSpace(f.Value), "-") {
+			suffix = "Neg" + suffix
+		}
+		if suffix == "" {
+			suffix = "Empty"
+		}
+		d.consts = append(d.consts, enumConst{
+			name:  g.reserve(name+suffix, ""),
+			value: value,
+			isVar: isVar,
+		})
+	}
+}
+
+// enumValue returns the Go expression of the enumerated value of a
+// simple type whose predeclared Go type is basic. Numbers are
+// normalized, so that they are not read as octal for example, and
+// special floating point values are returned as variable values
+func enumValue(basic, value string) (string, bool, bool) {
+	switch basic {
+	case "string":
+		return strconv.Quote(value), false, true
+	case "float32", "float64":
+		switch v := strings.TrimSpace(value); v {
+		case "INF", "+INF":
+			return "math.Inf(1)", true, true
+		case "-INF":
+			return "math.Inf(-1)", true, true
+		case "NaN":
+			return "math.NaN()", true, true
+		default:
+			bits := 64
+			if basic == "float32" {
+				bits = 32
+			}
+			f, err := strconv.ParseFloat(v, bits)
+			if err != nil {
+				return "", false, false
+			}
+			return strconv.FormatFloat(f, 'g', -1, bits), false, true
+		}
+	}
+
+	v := strings.TrimSpace(value)
+	bits, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(basic, "u"), "int"))
+	if err != nil {
+		return "", false, false
+	}
+	if strings.HasPrefix(basic, "uint") {
+		u, err := strconv.ParseUint(strings.TrimPrefix(v, "+"), 10, bits)
+		if err != nil {
+			return "", false, false
+		}
+		return strconv.FormatUint(u, 10), false, true
+	}
+	i, err := strconv.ParseInt(v, 10, bits)
+	if err != nil {
+		return "", false, false
+	}
+	return strconv.FormatInt(i, 10), false, true
+}
+
+// simpleBase returns the underlying type of the Go type generated for
+// the simple type t, along with the predeclared type it boils down to
+func (g *generator) simpleBase(t *xsd.TypeDefinition, name string) (string, string) {
+	base, err := t.Base()
+	g.check(err)
+	switch {
+	case base == nil:
+		return "string", "string"
+	case base.Builtin:
+		typ := builtinType(base.Name)
+		return typ, typ
+	case base.Name == "":
+		// Anonymous base types do not get a Go type of their own
+		_, basic := g.simpleBase(base, name)
+		return basic, basic
+	}
+
+	typ := g.typeRef(base, "", "")
+	return typ, g.decls[typ].basic
+}
+
+// complexFields returns the fields of the struct for the complex type t
+func (g *generator) complexFields(t *xsd.TypeDefinition, owner string) []field {
+	var fields []field
+	switch t.ContentType {
+	case xsd.ContentSimple:
+		content, err := t.SimpleContent()
+		g.check(err)
+		fields = append(fields, field{
+			name: "Value",
+			typ:  g.typeRef(content, owner+"Value", fmt.Sprintf("the content of %s", owner)),
+			tag:  `xml:",chardata"`,
+		})
+	case xsd.ContentMixed:
+		fields = append(fields, field{
+			name: "Text",
+			typ:  "string",
+			tag:  `xml:",chardata"`,
+		})
+	}
+
+	p, err := t.Content()
+	g.check(err)
+	if p != nil {
+		fields = g.particleFields(fields, p, owner, false, false)
+	}
+
+	uses, err := t.Attributes()
+	g.check(err)
+	for _, use := range uses {
+		a := use.Declaration
+		name := uniqueField(fields, goName(a.Name), "Attr")
+		at, err := a.Type()
+		g.check(err)
+		typ := g.typeRef(at, owner+name, fmt.Sprintf("the anonymous type of attribute %q", a.Name))
+		tag := qualifiedName(a.Namespace, a.Name) + ",attr"
+		if !use.Required {
+			typ = "*" + typ
+			tag += ",omitempty"
+		}
+		fields = append(fields, field{
+			name: name,
+			typ:  typ,
+			tag:  fmt.Sprintf("xml:%q", tag),
+			doc:  a.Documentation,
+		})
+	}
+	return fields
+}
+
+// particleFields appends the fields for the particle p. Model groups
+// are flattened: elements that may be left out, including the
+// alternatives of a choice, become pointers, and elements that may be
+// repeated become slices
+func (g *generator) particleFields(fields []field, p *xsd.Particle, owner string, optional, repeated bool) []field {
+	optional = optional || p.MinOccurs == 0
+	repeated = repeated || p.MaxOccurs != 1
+
+	switch p.Kind {
+	case xsd.SequenceParticle, xsd.AllParticle, xsd.ChoiceParticle:
+		particles, err := p.Particles()
+		g.check(err)
+		// The alternatives of a choice may all be left out
+		childOptional := optional || p.Kind == xsd.ChoiceParticle
+		for _, c := range particles {
+			fields = g.particleFields(fields, c, owner, childOptional, repeated)
+		}
+	case xsd.AnyParticle:
+		for _, f := range fields {
+			if f.tag == `xml:",any"` {
+				return fields
+			}
+		}
+		fields = append(fields, field{
+			name: uniqueField(fields, "Any", ""),
+			typ:  "[]" + g.anyContent(),
+			tag:  `xml:",any"`,
+		})
+	case xsd.ElementParticle:
+		e, err := p.Element()
+		g.check(err)
+		if e == nil {
+			return fields
+		}
+		tag := qualifiedName(e.Namespace, e.Name)
+
+		// The same element may appear more than once in a content
+		// model. It is mapped to a single slice
+		for i, f := range fields {
+			if f.tag == fmt.Sprintf("xml:%q", tag+",omitempty") || f.tag == fmt.Sprintf("xml:%q", tag) {
+				fields[i].typ = "[]" + strings.TrimLeft(f.typ, "*[]")
+				fields[i].tag = fmt.Sprintf("xml:%q", tag+",omitempty")
+				return fields
+			}
+		}
+
+		name := uniqueField(fields, goName(e.Name), "")
+		var typ string
+		if e.Global {
+			typ = g.element(e)
+		} else {
+			et, err := e.Type()
+			g.check(err)
+			typ = g.typeRef(et, owner+name, fmt.Sprintf("the anonymous type of element %q", e.Name))
+		}
+		switch {
+		case repeated:
+			typ = "[]" + typ
+			tag += ",omitempty"
+		case optional:
+			typ = "*" + typ
+			tag += ",omitempty"
+		}
+		fields = append(fields, field{
+			name: name,
+			typ:  typ,
+			tag:  fmt.Sprintf("xml:%q", tag),
+			doc:  e.Documentation,
+		})
+	}
+	return fields
+}
+
+// anyContent returns the type used for content the schema does not
+// constrain, such as xs:anyType and wildcards
+func (g *generator) anyContent() string {
+	if g.anyType != "" {
+		return g.anyType
+	}
+	g.anyType = g.reserve("AnyContent", "")
+	g.add(&decl{
+		name:     g.anyType,
+		source:   "xs:anyType",
+		isStruct: true,
+		fields:   g.anyFields(),
+	})
+	return g.anyType
+}
+
+func (g *generator) anyFields() []field {
+	return []field{
+		{name: "Attrs", typ: "[]xml.Attr", tag: `xml:",any,attr"`},
+		{name: "Content", typ: "string", tag: `xml:",innerxml"`},
+	}
+}
+
+// generate returns the formatted source of the generated package
+func (g *generator) generate() ([]byte, error) {
+	names := make([]string, 0, len(g.decls))
+	hasElements := false
+	usesXML := false
+	usesMath := false
+	for name, d := range g.decls {
+		names = append(names, name)
+		if d.element != "" {
+			hasElements = true
+		}
+		for _, c := range d.consts {
+			if c.isVar {
+				usesMath = true
+			}
+		}
+		for _, f := range d.fields {
+			if strings.Contains(f.typ, "xml.") {
+				usesXML = true
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by xsdgen")
+	if len(g.sources) > 0 {
+		fmt.Fprintf(&buf, " from %s", strings.Join(g.sources, ", "))
+	}
+	buf.WriteString(". DO NOT EDIT.")
+	fmt.Fprintf(&buf, "\n\npackage %s", g.pkg)
+
+	if hasElements || usesXML || usesMath {
+		buf.WriteString("\n\nimport (")
+		if hasElements || usesXML {
+			buf.WriteString("\n\"encoding/xml\"")
+		}
+		if hasElements {
+			buf.WriteString("\n\"fmt\"")
+		}
+		if usesMath {
+			buf.WriteString("\n\"math\"")
+		}
+		if hasElements {
+			buf.WriteString("\n\"sync\"\n")
+			for _, lib := range []string{"github.com/lestrrat-go/libxml2", "github.com/lestrrat-go/libxml2/xsd"} {
+				fmt.Fprintf(&buf, "\n%s", strconv.Quote(lib))
+			}
+		}
+		buf.WriteString("\n)")
+	}
+
+	if hasElements {
+		buf.WriteString("\n\nvar (")
+		buf.WriteString("\nschemasMu sync.RWMutex")
+		buf.WriteString("\nschemas = make(map[string]*xsd.Schema)")
+		buf.WriteString("\n)")
+		buf.WriteString("\n\n// SetSchema registers the schema used by the Validate methods of the")
+		buf.WriteString("\n// elements in its target namespace. The schema must not be freed")
+		buf.WriteString("\n// while it is registered")
+		buf.WriteString("\nfunc SetSchema(s *xsd.Schema) {")
+		buf.WriteString("\nschemasMu.Lock()")
+		buf.WriteString("\ndefer schemasMu.Unlock()")
+		buf.WriteString("\nschemas[s.TargetNamespace()] = s")
+		buf.WriteString("\n}")
+		buf.WriteString("\n\nfunc validate(namespace string, v interface{}) error {")
+		buf.WriteString("\nschemasMu.RLock()")
+		buf.WriteString("\ns := schemas[namespace]")
+		buf.WriteString("\nschemasMu.RUnlock()")
+		buf.WriteString("\nif s == nil {")
+		buf.WriteString("\nreturn fmt.Errorf(\"no schema registered for namespace %q\", namespace)")
+		buf.WriteString("\n}")
+		buf.WriteString("\n\nbuf, err := xml.Marshal(v)")
+		buf.WriteString("\nif err != nil {")
+		buf.WriteString("\nreturn err")
+		buf.WriteString("\n}")
+		buf.WriteString("\ndoc, err := libxml2.Parse(buf)")
+		buf.WriteString("\nif err != nil {")
+		buf.WriteString("\nreturn err")
+		buf.WriteString("\n}")
+		buf.WriteString("\ndefer doc.Free()")
+		buf.WriteString("\nreturn s.Validate(doc)")
+		buf.WriteString("\n}")
+	}
+
+	for _, name := range names {
+		g.writeDecl(&buf, g.decls[name])
+	}
+	buf.WriteString("\n")
+
+	return format.Source(buf.Bytes())
+}
+
+func (g *generator) writeDecl(buf *bytes.Buffer, d *decl) {
+	fmt.Fprintf(buf, "\n\n// %s is generated from %s", d.name, d.source)
+	writeDoc(buf, d.doc, true)
+
+	if !d.isStruct {
+		fmt.Fprintf(buf, "\ntype %s %s", d.name, d.underlying)
+		if len(d.consts) == 0 {
+			return
+		}
+		for _, isVar := range []bool{false, true} {
+			block := "const"
+			if isVar {
+				block = "var"
+			}
+			started := false
+			for _, c := range d.consts {
+				if c.isVar != isVar {
+					continue
+				}
+				if !started {
+					fmt.Fprintf(buf, "\n\n%s (", block)
+					started = true
+				}
+				if isVar {
+					fmt.Fprintf(buf, "\n%s = %s(%s)", c.name, d.name, c.value)
+				} else {
+					fmt.Fprintf(buf, "\n%s %s = %s", c.name, d.name, c.value)
+				}
+			}
+			if started {
+				buf.WriteString("\n)")
+			}
+		}
+		return
+	}
+
+	fmt.Fprintf(buf, "\ntype %s struct {", d.name)
+	if d.element != "" {
+		fmt.Fprintf(buf, "\nXMLName xml.Name `xml:%q`", d.element)
+	}
+	for _, f := range d.fields {
+		writeDoc(buf, f.doc, false)
+		if f.name == "" {
+			fmt.Fprintf(buf, "\n%s", f.typ)
+			continue
+		}
+		fmt.Fprintf(buf, "\n%s %s", f.name, f.typ)
+		if f.tag != "" {
+			fmt.Fprintf(buf, " `%s`", f.tag)
+		}
+	}
+	buf.WriteString("\n}")
+
+	if d.element != "" {
+		buf.WriteString("\n\n// Validate validates v against the schema registered with SetSchema")
+		fmt.Fprintf(buf, "\nfunc (v *%s) Validate() error {", d.name)
+		fmt.Fprintf(buf, "\nreturn validate(%q, v)", d.namespace)
+		buf.WriteString("\n}")
+	}
+}
+
+// writeDoc writes documentation from the schema as a comment. If
+// continued is true, the comment continues the one already written
+func writeDoc(buf *bytes.Buffer, doc string, continued bool) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return
+	}
+	if continued {
+		buf.WriteString("\n//")
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			buf.WriteString("\n//")
+			continue
+		}
+		fmt.Fprintf(buf, "\n// %s", line)
+	}
+}
+
+// qualifiedName returns the name as used in struct tags
+func qualifiedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + " " + name
+}
+
+// uniqueField returns a field name based on name that is not used by
+// fields yet
+func uniqueField(fields []field, name, suffix string) string {
+	used := func(n string) bool {
+		if n == "XMLName" {
+			return true
+		}
+		for _, f := range fields {
+			if f.name == n {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !used(name) {
+		return name
+	}
+	if suffix != "" && !used(name+suffix) {
+		return name + suffix
+	}
+	for i := 2; ; i++ {
+		if n := name + strconv.Itoa(i); !used(n) {
+			return n
+		}
+	}
+}
+
+// isAnyType tells if t does not constrain the content at all
+func isAnyType(t *xsd.TypeDefinition) bool {
+	return t == nil || (t.Builtin && t.Name == "anyType")
+}
+
+// goName turns an XML name into an exported Go identifier
+func goName(s string) string {
+	name := camelCase(s)
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+	return name
+}
+
+// camelCase removes the characters of s that may not appear in an
+// identifier, capitalizing the words they separate
+func camelCase(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// builtinType returns the Go type for the built-in simple type name
+func builtinType(name string) string {
+	switch name {
+	case "boolean":
+		return "bool"
+	case "float":
+		return "float32"
+	case "double", "decimal":
+		return "float64"
+	case "integer", "long", "nonNegativeInteger", "positiveInteger", "nonPositiveInteger", "negativeInteger":
+		return "int64"
+	case "int":
+		return "int32"
+	case "short":
+		return "int16"
+	case "byte":
+		return "int8"
+	case "unsignedLong":
+		return "uint64"
+	case "unsignedInt":
+		return "uint32"
+	case "unsignedShort":
+		return "uint16"
+	case "unsignedByte":
+		return "uint8"
+	default:
+		return "string"
+	}
+}
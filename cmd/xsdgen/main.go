@@ -0,0 +1,68 @@
+// xsdgen generates Go types from XML Schema documents.
+//
+// Usage:
+//
+//	xsdgen [-package name] [-o file] schema.xsd [schema.xsd...]
+//
+// A struct is generated for each global element and for each complex
+// type reachable from them, and a named type (with constants for its
+// enumeration facets, if any) for each simple type. Elements that may
+// occur more than once become slices, and optional elements and
+// attributes become pointers.
+//
+// The structs generated for global elements have a Validate() method
+// that marshals the value and validates it against the schema
+// registered with SetSchema() for the namespace of the element.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/lestrrat-go/libxml2/xsd"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	if err := _main(); err != nil {
+		log.Printf("%s", err)
+		os.Exit(1)
+	}
+}
+
+func _main() error {
+	var pkg, output string
+	flag.StringVar(&pkg, "package", "schema", "name of the generated package")
+	flag.StringVar(&output, "o", "", "file to write to (default stdout)")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		return errors.New("usage: xsdgen [-package name] [-o file] schema.xsd [schema.xsd...]")
+	}
+
+	g := newGenerator(pkg)
+	for _, path := range flag.Args() {
+		s, err := xsd.ParseFromFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse %s", path)
+		}
+		err = g.addSchema(path, s)
+		// Everything we need has been copied out of the schema by now
+		s.Free()
+		if err != nil {
+			return errors.Wrapf(err, "failed to generate types for %s", path)
+		}
+	}
+
+	src, err := g.generate()
+	if err != nil {
+		return errors.Wrap(err, "failed to format generated code")
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return errors.Wrapf(os.WriteFile(output, src, 0644), "failed to write %s", output)
+}
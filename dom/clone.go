@@ -0,0 +1,123 @@
+package dom
+
+import (
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/internal/option"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/pkg/errors"
+)
+
+// CloneOption is passed to CloneNode to change what is copied
+type CloneOption = types.CloneOption
+
+// WithoutNamespaces makes CloneNode drop all namespaces from the
+// copied elements and attributes, leaving only their local names.
+func WithoutNamespaces() CloneOption {
+	return option.New(option.OptKeyWithoutNamespaces, true)
+}
+
+// WithoutAttributes makes CloneNode drop all attributes from the
+// copied elements.
+func WithoutAttributes() CloneOption {
+	return option.New(option.OptKeyWithoutAttributes, true)
+}
+
+// applyCloneOptions post-processes a freshly copied node (or the
+// root of a freshly copied document) according to the options
+func applyCloneOptions(n clib.PtrSource, deep bool, options []CloneOption) error {
+	var noNamespaces, noAttributes bool
+	//nolint:forcetypeassert
+	for _, opt := range options {
+		switch opt.Name() {
+		case option.OptKeyWithoutNamespaces:
+			noNamespaces = opt.Value().(bool)
+		case option.OptKeyWithoutAttributes:
+			noAttributes = opt.Value().(bool)
+		}
+	}
+
+	if noAttributes {
+		if err := clib.XMLRemoveAttributes(n, deep); err != nil {
+			return errors.Wrap(err, "failed to remove attributes")
+		}
+	}
+	if noNamespaces {
+		if err := clib.XMLStripNamespaces(n); err != nil {
+			return errors.Wrap(err, "failed to strip namespaces")
+		}
+	}
+	return nil
+}
+
+// CloneNode creates a copy of the node, belonging to the same
+// document but not attached to the tree. If deep is true, the
+// descendants of the node are copied as well. Otherwise only the
+// node itself is copied, along with its attributes and namespace
+// declarations if it is an element.
+func (n *XMLNode) CloneNode(deep bool, options ...CloneOption) (types.Node, error) {
+	doc, err := n.OwnerDocument()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get owner document")
+	}
+
+	// 1 copies recursively, 2 copies properties and namespaces only
+	extended := 2
+	if deep {
+		extended = 1
+	}
+
+	nptr, err := clib.XMLDocCopyNode(n, doc, extended)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to copy node")
+	}
+
+	ret, err := WrapNode(nptr)
+	if err != nil {
+		_ = clib.XMLFreeNode(nodePtr(nptr))
+		return nil, errors.Wrap(err, "failed to wrap node")
+	}
+
+	if clib.XMLGetNodeTypeRaw(nptr) == clib.ElementNode {
+		if err := applyCloneOptions(ret, deep, options); err != nil {
+			ret.Free()
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// Clone creates a copy of the document, including the document type
+// declaration, encoding, version, standalone flag and URI.
+//
+// The copy does not share any state with the original, so it is safe
+// to clone a template document from multiple goroutines and modify
+// each copy independently, as long as the template itself is not
+// modified while it is being cloned.
+func (d *Document) Clone() (*Document, error) {
+	ptr, err := clib.XMLCopyDoc(d, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to copy document")
+	}
+	return WrapDocument(ptr), nil
+}
+
+// CloneNode creates a copy of the document. If deep is false, only
+// the document properties are copied and the new document is empty.
+// The options are applied to the copied document element.
+func (d *Document) CloneNode(deep bool, options ...CloneOption) (types.Node, error) {
+	ptr, err := clib.XMLCopyDoc(d, deep)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to copy document")
+	}
+	doc := WrapDocument(ptr)
+
+	if len(options) > 0 {
+		if root, err := doc.DocumentElement(); err == nil {
+			if err := applyCloneOptions(root, deep, options); err != nil {
+				doc.Free()
+				return nil, err
+			}
+		}
+	}
+	return doc, nil
+}
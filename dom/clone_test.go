@@ -0,0 +1,206 @@
+package dom_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/dom"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloneNode(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root xmlns:x="http://x"><x:item a="1" x:b="2"><child/>text</x:item></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	root, err := doc.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+	item, err := root.(types.Element).FirstChildElement()
+	if !assert.NoError(t, err, "FirstChildElement should succeed") {
+		return
+	}
+
+	t.Run("deep", func(t *testing.T) {
+		n, err := item.CloneNode(true)
+		if !assert.NoError(t, err, "CloneNode should succeed") {
+			return
+		}
+		defer n.Free()
+
+		if !assert.Equal(t, `<x:item xmlns:x="http://x" a="1" x:b="2"><child/>text</x:item>`, n.String(), "subtree is copied") {
+			return
+		}
+		if !assert.False(t, n.IsSameNode(item), "copy is a new node") {
+			return
+		}
+		for range n.Ancestors() {
+			t.Errorf("copy should not be attached")
+			return
+		}
+	})
+	t.Run("shallow", func(t *testing.T) {
+		n, err := item.CloneNode(false)
+		if !assert.NoError(t, err, "CloneNode should succeed") {
+			return
+		}
+		defer n.Free()
+
+		if !assert.Equal(t, `<x:item xmlns:x="http://x" a="1" x:b="2"/>`, n.String(), "only the element is copied") {
+			return
+		}
+	})
+	t.Run("WithoutAttributes", func(t *testing.T) {
+		n, err := root.CloneNode(true, dom.WithoutAttributes())
+		if !assert.NoError(t, err, "CloneNode should succeed") {
+			return
+		}
+		defer n.Free()
+
+		if !assert.Equal(t, `<root xmlns:x="http://x"><x:item><child/>text</x:item></root>`, n.String(), "attributes are dropped") {
+			return
+		}
+	})
+	t.Run("WithoutNamespaces", func(t *testing.T) {
+		n, err := root.CloneNode(true, dom.WithoutNamespaces())
+		if !assert.NoError(t, err, "CloneNode should succeed") {
+			return
+		}
+		defer n.Free()
+
+		if !assert.Equal(t, `<root><item a="1" b="2"><child/>text</item></root>`, n.String(), "namespaces are dropped") {
+			return
+		}
+	})
+	t.Run("text", func(t *testing.T) {
+		n, err := item.LastChild()
+		if !assert.NoError(t, err, "LastChild should succeed") {
+			return
+		}
+		c, err := n.CloneNode(false, dom.WithoutAttributes())
+		if !assert.NoError(t, err, "CloneNode should succeed") {
+			return
+		}
+		defer c.Free()
+
+		if !assert.Equal(t, "text", c.NodeValue(), "text is copied") {
+			return
+		}
+	})
+}
+
+func TestDocumentClone(t *testing.T) {
+	doc, err := libxml2.ParseString(`<?xml version="1.0" encoding="ISO-8859-1" standalone="yes"?>
+<!DOCTYPE root [
+  <!ELEMENT root (item*)>
+  <!ELEMENT item EMPTY>
+  <!ATTLIST item key ID #REQUIRED>
+]>
+<root><item key="a"/></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	//nolint:forcetypeassert
+	d := doc.(*dom.Document)
+	clone, err := d.Clone()
+	if !assert.NoError(t, err, "Clone should succeed") {
+		return
+	}
+	defer clone.Free()
+
+	if !assert.Equal(t, d.String(), clone.String(), "documents serialize the same") {
+		return
+	}
+	if !assert.Equal(t, "ISO-8859-1", clone.Encoding(), "encoding is preserved") {
+		return
+	}
+	if !assert.Equal(t, "1.0", clone.Version(), "version is preserved") {
+		return
+	}
+	if !assert.Equal(t, d.URI(), clone.URI(), "URI is preserved") {
+		return
+	}
+	if !assert.Equal(t, d.Standalone(), clone.Standalone(), "standalone flag is preserved") {
+		return
+	}
+
+	if _, err := clone.GetElementByID("a"); !assert.NoError(t, err, "IDs are registered in the copy") {
+		return
+	}
+
+	empty, err := d.CloneNode(false)
+	if !assert.NoError(t, err, "CloneNode should succeed") {
+		return
+	}
+	defer empty.Free()
+
+	if _, err := empty.(types.Document).DocumentElement(); !assert.Error(t, err, "shallow copy has no document element") {
+		return
+	}
+}
+
+func TestDocumentCloneConcurrent(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root><item/></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	//nolint:forcetypeassert
+	template := doc.(*dom.Document)
+
+	const count = 16
+	results := make([]string, count)
+	errs := make([]error, count)
+
+	var wg sync.WaitGroup
+	for i := range count {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			clone, err := template.Clone()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer clone.Free()
+
+			root, err := clone.DocumentElement()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if err := root.(types.Element).SetAttribute("n", fmt.Sprint(i)); err != nil {
+				errs[i] = err
+				return
+			}
+			results[i] = root.String()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range count {
+		if !assert.NoError(t, errs[i], "Clone should succeed") {
+			return
+		}
+		if !assert.Equal(t, fmt.Sprintf(`<root n="%d"><item/></root>`, i), results[i], "each copy is modified independently") {
+			return
+		}
+	}
+	root, err := template.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+	if !assert.Equal(t, `<root><item/></root>`, root.String(), "template is unchanged") {
+		return
+	}
+}
@@ -3,6 +3,8 @@ package dom
 import (
 	"sync"
 
+	"github.com/lestrrat-go/libxml2/internal/nodewrap"
+	"github.com/lestrrat-go/libxml2/types"
 	"github.com/lestrrat-go/libxml2/xpath"
 )
 
@@ -18,6 +20,12 @@ func init() {
 
 func SetupXPathCallback() {
 	xpath.WrapNodeFunc = WrapNode
+	nodewrap.Document = func(ptr uintptr) types.Node {
+		return WrapDocument(ptr)
+	}
+	nodewrap.Namespace = func(ptr uintptr) types.Node {
+		return wrapNamespaceNode(ptr)
+	}
 }
 
 func WrapDocument(n uintptr) *Document {
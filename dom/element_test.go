@@ -0,0 +1,137 @@
+package dom_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/dom"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func nodeNames(list types.NodeList) []string {
+	ret := make([]string, len(list))
+	for i, n := range list {
+		ret[i] = n.NodeName()
+	}
+	return ret
+}
+
+func TestGetElementsByTagName(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root xmlns:x="http://example.com/x">
+  <item id="1"><name>a</name></item>
+  <!-- comment -->
+  <x:item id="2"><name>b</name></x:item>
+  <group><item id="3"/></group>
+</root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	list, err := doc.GetElementsByTagName("item")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+		return
+	}
+	if !assert.Equal(t, []string{"item", "item"}, nodeNames(list), "unprefixed items are found") {
+		return
+	}
+
+	list, err = doc.GetElementsByTagName("x:item")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+		return
+	}
+	if !assert.Len(t, list, 1, "prefixed items are found") {
+		return
+	}
+
+	list, err = doc.GetElementsByTagNameNS("http://example.com/x", "item")
+	if !assert.NoError(t, err, "GetElementsByTagNameNS should succeed") {
+		return
+	}
+	if !assert.Equal(t, []string{"x:item"}, nodeNames(list), "namespaced items are found") {
+		return
+	}
+
+	list, err = doc.GetElementsByTagNameNS("*", "item")
+	if !assert.NoError(t, err, "GetElementsByTagNameNS should succeed") {
+		return
+	}
+	if !assert.Equal(t, []string{"item", "x:item", "item"}, nodeNames(list), "items in any namespace are found in document order") {
+		return
+	}
+
+	list, err = doc.GetElementsByTagName("*")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+		return
+	}
+	if !assert.Len(t, list, 7, "all elements are found") {
+		return
+	}
+
+	root, err := doc.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+
+	//nolint:forcetypeassert
+	elem := root.(*dom.Element)
+	list, err = elem.GetElementsByTagName("root")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+		return
+	}
+	if !assert.Len(t, list, 0, "element itself is not included") {
+		return
+	}
+}
+
+func TestChildElements(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root>text<a/><!-- comment --><b/>more<a/></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	first, err := doc.FirstChildElement()
+	if !assert.NoError(t, err, "FirstChildElement should succeed") {
+		return
+	}
+	if !assert.Equal(t, "root", first.NodeName(), "document element is returned") {
+		return
+	}
+
+	children, err := first.ChildElements()
+	if !assert.NoError(t, err, "ChildElements should succeed") {
+		return
+	}
+	if !assert.Equal(t, []string{"a", "b", "a"}, nodeNames(children), "only elements are returned") {
+		return
+	}
+
+	children, err = first.GetChildrenByTagName("a")
+	if !assert.NoError(t, err, "GetChildrenByTagName should succeed") {
+		return
+	}
+	if !assert.Len(t, children, 2, "matching children are returned") {
+		return
+	}
+
+	var names []string
+	for e, err := first.FirstChildElement(); err == nil; e, err = e.NextElementSibling() {
+		names = append(names, e.NodeName())
+	}
+	if !assert.Equal(t, []string{"a", "b", "a"}, names, "siblings are traversed") {
+		return
+	}
+
+	last := children[1].(types.Element)
+	_, err = last.NextElementSibling()
+	if !assert.ErrorIs(t, err, dom.ErrNodeNotFound, "no more siblings") {
+		return
+	}
+
+	_, err = last.FirstChildElement()
+	if !assert.ErrorIs(t, err, dom.ErrNodeNotFound, "no children") {
+		return
+	}
+}
@@ -0,0 +1,151 @@
+package dom_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/dom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetElementByID(t *testing.T) {
+	t.Run("DTD", func(t *testing.T) {
+		doc, err := libxml2.ParseString(`<?xml version="1.0"?>
+<!DOCTYPE root [
+  <!ELEMENT root (item*)>
+  <!ELEMENT item (#PCDATA)>
+  <!ATTLIST item key ID #REQUIRED>
+]>
+<root><item key="a">A</item><item key="b">B</item></root>`)
+		if !assert.NoError(t, err, "ParseString should succeed") {
+			return
+		}
+		defer doc.Free()
+
+		e, err := doc.GetElementByID("b")
+		if !assert.NoError(t, err, "GetElementByID should succeed") {
+			return
+		}
+		if !assert.Equal(t, "B", e.TextContent(), "element is found") {
+			return
+		}
+
+		_, err = doc.GetElementByID("c")
+		if !assert.ErrorIs(t, err, dom.ErrNodeNotFound, "unknown IDs are not found") {
+			return
+		}
+	})
+	t.Run("xml:id", func(t *testing.T) {
+		doc, err := libxml2.ParseString(`<root><item xml:id="a">A</item><item xml:id="b">B</item></root>`)
+		if !assert.NoError(t, err, "ParseString should succeed") {
+			return
+		}
+		defer doc.Free()
+
+		e, err := doc.GetElementByID("a")
+		if !assert.NoError(t, err, "GetElementByID should succeed") {
+			return
+		}
+		if !assert.Equal(t, "A", e.TextContent(), "element is found") {
+			return
+		}
+	})
+	t.Run("custom ID attributes", func(t *testing.T) {
+		doc, err := libxml2.ParseString(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:wsu="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd">
+  <soap:Header><Signature Id="sig"/></soap:Header>
+  <soap:Body wsu:Id="body"/>
+</soap:Envelope>`)
+		if !assert.NoError(t, err, "ParseString should succeed") {
+			return
+		}
+		defer doc.Free()
+
+		_, err = doc.GetElementByID("body")
+		if !assert.Error(t, err, "GetElementByID should fail before registration") {
+			return
+		}
+
+		//nolint:forcetypeassert
+		d := doc.(*dom.Document)
+		if !assert.NoError(t, d.RegisterIDAttribute("Id"), "RegisterIDAttribute should succeed") {
+			return
+		}
+		if !assert.NoError(t, d.RegisterIDAttributeNS("http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd", "Id"), "RegisterIDAttributeNS should succeed") {
+			return
+		}
+
+		for id, name := range map[string]string{"sig": "Signature", "body": "soap:Body"} {
+			e, err := doc.GetElementByID(id)
+			if !assert.NoError(t, err, "GetElementByID(%s) should succeed", id) {
+				return
+			}
+			if !assert.Equal(t, name, e.NodeName(), "element is found") {
+				return
+			}
+		}
+	})
+	t.Run("SetIDAttribute", func(t *testing.T) {
+		doc, err := libxml2.ParseString(`<root><item ref="a"/></root>`)
+		if !assert.NoError(t, err, "ParseString should succeed") {
+			return
+		}
+		defer doc.Free()
+
+		list, err := doc.GetElementsByTagName("item")
+		if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+			return
+		}
+
+		//nolint:forcetypeassert
+		item := list[0].(*dom.Element)
+		if !assert.NoError(t, item.SetIDAttribute("ref", true), "SetIDAttribute should succeed") {
+			return
+		}
+
+		e, err := doc.GetElementByID("a")
+		if !assert.NoError(t, err, "GetElementByID should succeed") {
+			return
+		}
+		if !assert.True(t, item.IsSameNode(e), "element is found") {
+			return
+		}
+
+		attr, err := item.GetAttribute("ref")
+		if !assert.NoError(t, err, "GetAttribute should succeed") {
+			return
+		}
+		//nolint:forcetypeassert
+		if !assert.True(t, attr.(*dom.Attribute).IsID(), "attribute is an ID") {
+			return
+		}
+
+		if !assert.NoError(t, item.SetIDAttribute("ref", false), "SetIDAttribute should succeed") {
+			return
+		}
+		_, err = doc.GetElementByID("a")
+		if !assert.Error(t, err, "GetElementByID should fail after removal") {
+			return
+		}
+	})
+	t.Run("DOM built", func(t *testing.T) {
+		doc := dom.CreateDocument()
+		defer doc.Free()
+
+		root, err := doc.CreateElement("root")
+		if !assert.NoError(t, err, "CreateElement should succeed") {
+			return
+		}
+		_ = doc.SetDocumentElement(root)
+		if !assert.NoError(t, root.SetAttribute("xml:id", "r"), "SetAttribute should succeed") {
+			return
+		}
+
+		e, err := doc.GetElementByID("r")
+		if !assert.NoError(t, err, "GetElementByID should succeed") {
+			return
+		}
+		if !assert.True(t, root.IsSameNode(e), "element is found") {
+			return
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 var (
 	ErrAttributeNotFound = clib.ErrAttributeNotFound
+	ErrNodeNotFound      = clib.ErrNodeNotFound
 	ErrInvalidNodeType   = errors.New("invalid node type")
 )
 
@@ -38,6 +39,18 @@ const (
 	DocbDocumentNode = clib.DocbDocumentNode
 )
 
+// DocumentPosition is the bitmask returned by CompareDocumentPosition
+type DocumentPosition = clib.DocumentPosition
+
+const (
+	DocumentPositionDisconnected           = clib.DocumentPositionDisconnected
+	DocumentPositionPreceding              = clib.DocumentPositionPreceding
+	DocumentPositionFollowing              = clib.DocumentPositionFollowing
+	DocumentPositionContains               = clib.DocumentPositionContains
+	DocumentPositionContainedBy            = clib.DocumentPositionContainedBy
+	DocumentPositionImplementationSpecific = clib.DocumentPositionImplementationSpecific
+)
+
 type XMLNode struct {
 	ptr    uintptr // *C.xmlNode
 	mortal bool
@@ -76,6 +89,40 @@ type Namespace struct {
 	XMLNode
 }
 
+// DTD represents a document type declaration, i.e. the internal
+// or external subset of a document
+type DTD struct {
+	XMLNode
+}
+
+// ElementDeclaration represents an <!ELEMENT> declaration in a DTD
+type ElementDeclaration struct {
+	XMLNode
+}
+
+// AttributeDeclaration represents a single attribute declared
+// in an <!ATTLIST> declaration in a DTD
+type AttributeDeclaration struct {
+	XMLNode
+}
+
+// EntityDeclaration represents an <!ENTITY> declaration in a DTD
+type EntityDeclaration struct {
+	XMLNode
+}
+
+// EntityType identifies the kind of an entity declaration
+type EntityType = clib.EntityType
+
+const (
+	InternalGeneralEntity         = clib.InternalGeneralEntity
+	ExternalGeneralParsedEntity   = clib.ExternalGeneralParsedEntity
+	ExternalGeneralUnparsedEntity = clib.ExternalGeneralUnparsedEntity
+	InternalParameterEntity       = clib.InternalParameterEntity
+	ExternalParameterEntity       = clib.ExternalParameterEntity
+	InternalPredefinedEntity      = clib.InternalPredefinedEntity
+)
+
 type Serializer interface {
 	Serialize(interface{}) (string, error)
 }
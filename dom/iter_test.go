@@ -0,0 +1,113 @@
+package dom_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/dom"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterators(t *testing.T) {
+	doc, err := libxml2.ParseString(`<!-- head --><root a="1" b="2"><x><y/></x>text<z/></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	var names []string
+	for n := range doc.Children() {
+		names = append(names, n.NodeName())
+	}
+	if !assert.Equal(t, []string{"#comment", "root"}, names, "document children") {
+		return
+	}
+
+	names = nil
+	for n := range doc.Descendants() {
+		names = append(names, n.NodeName())
+	}
+	if !assert.Equal(t, []string{"#comment", "root", "x", "y", "#text", "z"}, names, "document descendants") {
+		return
+	}
+
+	ys, err := doc.GetElementsByTagName("y")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+		return
+	}
+
+	names = nil
+	for n := range ys[0].Ancestors() {
+		names = append(names, n.NodeName())
+	}
+	if !assert.Equal(t, []string{"x", "root", ""}, names, "ancestors up to the document") {
+		return
+	}
+
+	xs, err := doc.GetElementsByTagName("x")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+		return
+	}
+
+	names = nil
+	for n := range xs[0].FollowingSiblings() {
+		names = append(names, n.NodeName())
+	}
+	if !assert.Equal(t, []string{"#text", "z"}, names, "following siblings") {
+		return
+	}
+
+	zs, err := doc.GetElementsByTagName("z")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+		return
+	}
+
+	names = nil
+	for n := range zs[0].PrecedingSiblings() {
+		names = append(names, n.NodeName())
+		break
+	}
+	if !assert.Equal(t, []string{"#text"}, names, "iteration stops early") {
+		return
+	}
+
+	root, err := doc.FirstChildElement()
+	if !assert.NoError(t, err, "FirstChildElement should succeed") {
+		return
+	}
+
+	names = nil
+	for attr := range root.AttributeIter() {
+		names = append(names, attr.NodeName()+"="+attr.Value())
+	}
+	if !assert.Equal(t, []string{"a=1", "b=2"}, names, "attributes") {
+		return
+	}
+}
+
+func TestWalkSkip(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root><skip><a/><b/></skip><keep><c/></keep><stop/><never/></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	var names []string
+	err = dom.Walk(doc, func(n types.Node) error {
+		names = append(names, n.NodeName())
+		switch n.NodeName() {
+		case "skip":
+			return dom.SkipChildren
+		case "stop":
+			return dom.SkipAll
+		}
+		return nil
+	})
+	if !assert.NoError(t, err, "Walk should succeed") {
+		return
+	}
+	if !assert.Equal(t, []string{"", "root", "skip", "keep", "c", "stop"}, names, "subtrees are skipped") {
+		return
+	}
+}
@@ -0,0 +1,82 @@
+package dom_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/parser"
+	"github.com/lestrrat-go/libxml2/xpath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeLocation(t *testing.T) {
+	doc, err := libxml2.ParseString(`<order>
+  <item><price>1</price></item>
+  <item><price>2</price></item>
+  <item>
+    <price>3</price>
+  </item>
+</order>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	nodes := xpath.NodeList(doc.Find(`//price[. = 3]`))
+	if !assert.Len(t, nodes, 1, "price found") {
+		return
+	}
+
+	if !assert.Equal(t, "/order/item[3]/price", nodes[0].Path(), "Path matches") {
+		return
+	}
+	if !assert.Equal(t, 5, nodes[0].Line(), "Line matches") {
+		return
+	}
+	if !assert.Equal(t, "/", doc.Path(), "document Path matches") {
+		return
+	}
+}
+
+func TestNodeBigLines(t *testing.T) {
+	src := "<root>" + strings.Repeat("\n", 70000) + "<item/></root>"
+	for _, opt := range []parser.Option{parser.XMLParseEmptyOption, parser.XMLParseBigLines} {
+		doc, err := libxml2.ParseString(src, opt)
+		if !assert.NoError(t, err, "ParseString should succeed") {
+			return
+		}
+		defer doc.Free()
+
+		nodes := xpath.NodeList(doc.Find(`//item`))
+		if !assert.Len(t, nodes, 1, "item found") {
+			return
+		}
+
+		if opt == parser.XMLParseBigLines {
+			if !assert.Equal(t, 70001, nodes[0].Line(), "Line matches") {
+				return
+			}
+		} else {
+			if !assert.Equal(t, 65535, nodes[0].Line(), "Line is capped") {
+				return
+			}
+		}
+	}
+}
+
+func TestNodeBaseURI(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root xml:base="http://example.com/dir/"><item xml:base="sub/"><leaf/></item></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	nodes := xpath.NodeList(doc.Find(`//leaf`))
+	if !assert.Len(t, nodes, 1, "leaf found") {
+		return
+	}
+	if !assert.Equal(t, "http://example.com/dir/sub/", nodes[0].BaseURI(), "BaseURI matches") {
+		return
+	}
+}
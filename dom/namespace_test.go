@@ -0,0 +1,198 @@
+package dom_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/dom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeclareNamespace(t *testing.T) {
+	doc := dom.CreateDocument()
+	defer doc.Free()
+
+	root, err := doc.CreateElement("root")
+	if !assert.NoError(t, err, "CreateElement should succeed") {
+		return
+	}
+	_ = doc.SetDocumentElement(root)
+
+	if !assert.NoError(t, root.DeclareNamespace("x", "http://x"), "DeclareNamespace should succeed") {
+		return
+	}
+	if !assert.Error(t, root.DeclareNamespace("x", "http://y"), "DeclareNamespace with the same prefix should fail") {
+		return
+	}
+
+	if !assert.Equal(t, "", root.NamespaceURI(), "element namespace is unchanged") {
+		return
+	}
+	if !assert.Equal(t, `<root xmlns:x="http://x"/>`, root.String(), "namespace is declared") {
+		return
+	}
+}
+
+type rawPtr uintptr
+
+func (p rawPtr) Pointer() uintptr {
+	return uintptr(p)
+}
+
+func TestReconcileNamespaces(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root><a xmlns:x="http://x"/><b/></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	as, err := doc.GetElementsByTagName("a")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+		return
+	}
+	bs, err := doc.GetElementsByTagName("b")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+		return
+	}
+
+	// Put b in a namespace that is only declared on its sibling
+	ns, err := clib.XMLSearchNs(doc, as[0], "x")
+	if !assert.NoError(t, err, "XMLSearchNs should succeed") {
+		return
+	}
+	if !assert.NoError(t, clib.XMLSetNs(bs[0], rawPtr(ns)), "XMLSetNs should succeed") {
+		return
+	}
+	if !assert.Equal(t, `<x:b/>`, bs[0].String(), "namespace is not declared") {
+		return
+	}
+
+	if !assert.NoError(t, doc.ReconcileNamespaces(), "ReconcileNamespaces should succeed") {
+		return
+	}
+	root, err := doc.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+	if !assert.Equal(t, `<root xmlns:x="http://x"><a xmlns:x="http://x"/><x:b/></root>`, root.String(), "namespace is declared") {
+		return
+	}
+}
+
+func TestRenameNamespace(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root xmlns="urn:v1" xmlns:a="urn:v1"><item a:attr="1"/><sub xmlns="urn:other"/></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	if !assert.NoError(t, doc.RenameNamespace("urn:v1", "urn:v2"), "RenameNamespace should succeed") {
+		return
+	}
+
+	list, err := doc.GetElementsByTagNameNS("urn:v2", "*")
+	if !assert.NoError(t, err, "GetElementsByTagNameNS should succeed") {
+		return
+	}
+	if !assert.Equal(t, []string{"root", "item"}, nodeNames(list), "elements are moved to the new namespace") {
+		return
+	}
+
+	root, err := doc.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+	if !assert.Equal(t, `<root xmlns="urn:v2" xmlns:a="urn:v2"><item a:attr="1"/><sub xmlns="urn:other"/></root>`, root.String(), "declarations are renamed") {
+		return
+	}
+}
+
+func TestRenameNamespaceDeclaredOnAncestor(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root xmlns:a="urn:v1"><a:item a:attr="1"><a:leaf/></a:item><a:other/></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	list, err := doc.GetElementsByTagName("a:item")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") || !assert.Len(t, list, 1, "one element found") {
+		return
+	}
+	//nolint:forcetypeassert
+	item := list[0].(*dom.Element)
+	if !assert.NoError(t, item.RenameNamespace("urn:v1", "urn:v2"), "RenameNamespace should succeed") {
+		return
+	}
+
+	root, err := doc.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+	if !assert.Equal(t, `<root xmlns:a="urn:v1"><a:item xmlns:a="urn:v2" a:attr="1"><a:leaf/></a:item><a:other/></root>`, root.String(), "namespace is declared on the element") {
+		return
+	}
+
+	list, err = doc.GetElementsByTagNameNS("urn:v2", "*")
+	if !assert.NoError(t, err, "GetElementsByTagNameNS should succeed") {
+		return
+	}
+	if !assert.Equal(t, []string{"a:item", "a:leaf"}, nodeNames(list), "descendants are moved to the new namespace") {
+		return
+	}
+	list, err = doc.GetElementsByTagNameNS("urn:v1", "*")
+	if !assert.NoError(t, err, "GetElementsByTagNameNS should succeed") {
+		return
+	}
+	if !assert.Equal(t, []string{"a:other"}, nodeNames(list), "nodes outside the element are left alone") {
+		return
+	}
+}
+
+func TestStripNamespaces(t *testing.T) {
+	doc, err := libxml2.ParseString(`<x:root xmlns:x="http://x" xmlns="http://d" xml:lang="en"><item x:id="1" id="2" x:other="3"><x:leaf/></item></x:root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	root, err := doc.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+	nsptr, err := clib.XMLSearchNs(doc, root, "x")
+	if !assert.NoError(t, err, "XMLSearchNs should succeed") {
+		return
+	}
+
+	if !assert.NoError(t, doc.StripNamespaces(), "StripNamespaces should succeed") {
+		return
+	}
+
+	if !assert.Equal(t, `<root xml:lang="en"><item id="2" other="3"><leaf/></item></root>`, root.String(), "namespaces are stripped") {
+		return
+	}
+	if !assert.Equal(t, "http://x", clib.XMLNamespaceHref(rawPtr(nsptr)), "removed declarations outlive the elements") {
+		return
+	}
+}
+
+func TestRemoveUnusedNamespaceDeclarations(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root xmlns:a="http://a" xmlns:b="http://b" xmlns:c="http://c"><a:item c:attr="1" xmlns:d="http://d"/></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	if !assert.NoError(t, doc.RemoveUnusedNamespaceDeclarations(), "RemoveUnusedNamespaceDeclarations should succeed") {
+		return
+	}
+
+	root, err := doc.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+	if !assert.Equal(t, `<root xmlns:a="http://a" xmlns:c="http://c"><a:item c:attr="1"/></root>`, root.String(), "unused declarations are removed") {
+		return
+	}
+}
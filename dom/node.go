@@ -79,6 +79,28 @@ func (n *XMLNode) IsSameNode(other types.Node) bool {
 	return n.Pointer() == other.Pointer()
 }
 
+// IsEqualNode returns true if the two nodes are structurally equal,
+// as defined by DOM Level 3: they must have the same type, name,
+// namespace, value, attributes and namespace declarations (in any
+// order), and equal child nodes (in the same order)
+func (n *XMLNode) IsEqualNode(other types.Node) bool {
+	return clib.XMLIsEqualNode(n, other)
+}
+
+// CompareDocumentPosition returns the position of other relative
+// to this node, as a combination of the DocumentPosition flags
+func (n *XMLNode) CompareDocumentPosition(other types.Node) DocumentPosition {
+	return clib.XMLCompareDocumentPosition(n, other)
+}
+
+// Hash returns a value that identifies the underlying node. Two
+// Node values wrapping the same node, for example when returned by
+// separate XPath queries, return the same hash. The value is stable
+// for as long as the node is not freed.
+func (n *XMLNode) Hash() uint64 {
+	return uint64(n.ptr)
+}
+
 // Copy creates a copy of the node
 func (n *XMLNode) Copy() (types.Node, error) {
 	doc, err := n.OwnerDocument()
@@ -92,6 +114,26 @@ func (n *XMLNode) Copy() (types.Node, error) {
 	return WrapNode(nptr)
 }
 
+// ReconcileNamespaces makes sure that the namespaces used by this
+// element and its descendants are declared within the subtree.
+// Missing declarations are added to this element. Call this after
+// moving nodes between trees. It is a no op for non-element nodes.
+func (n *XMLNode) ReconcileNamespaces() error {
+	return clib.XMLReconciliateNs(n)
+}
+
+// Normalize merges adjacent text nodes and removes empty text nodes
+// within this node and its descendants, as defined by DOM Level 3.
+// Text nodes that are merged into their previous sibling are freed,
+// so you must not use references to them afterwards.
+func (n *XMLNode) Normalize() error {
+	switch n.NodeType() {
+	case ElementNode, DocumentFragNode:
+		return clib.XMLNormalize(n)
+	}
+	return nil
+}
+
 // SetDocument sets the document of this node and its descendants
 func (n *XMLNode) SetDocument(d types.Document) error {
 	return clib.XMLSetTreeDoc(n, d)
@@ -220,6 +262,44 @@ func (n *XMLNode) TextContent() string {
 	return clib.XMLTextContent(n)
 }
 
+// Path returns an XPath expression that uniquely identifies this
+// node within its document, such as "/order/item[3]/price"
+func (n *XMLNode) Path() string {
+	s, err := clib.XMLGetNodePath(n)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// Line returns the line number where this node was found when the
+// document was parsed, or 0 if it is not known (e.g. the node was
+// created programmatically). Line numbers above 65535 are only
+// available if the document was parsed with parser.XMLParseBigLines
+func (n *XMLNode) Line() int {
+	return clib.XMLGetLineNo(n)
+}
+
+// BaseURI returns the base URI of this node, as resolved from the
+// xml:base attributes of the node and its ancestors, and the URI
+// of the document
+func (n *XMLNode) BaseURI() string {
+	return clib.XMLNodeGetBase(n)
+}
+
+// Lang returns the language of this node, as declared by the
+// xml:lang attribute of the node or its nearest ancestor. An empty
+// string is returned if no language is declared
+func (n *XMLNode) Lang() string {
+	return clib.XMLNodeGetLang(n)
+}
+
+// SpacePreserved returns true if this node is in the scope of an
+// xml:space="preserve" attribute
+func (n *XMLNode) SpacePreserved() bool {
+	return clib.XMLNodeGetSpacePreserve(n) == 1
+}
+
 // ToString returns the string representation. (But it should probably
 // be deprecated)
 func (n *XMLNode) ToString(format int, docencoding bool) string {
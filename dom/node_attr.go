@@ -24,3 +24,9 @@ func (n *Attribute) Value() string {
 	}
 	return v
 }
+
+// IsID returns true if the attribute is registered as an ID in
+// the document's ID table
+func (n *Attribute) IsID() bool {
+	return clib.XMLIsID(n)
+}
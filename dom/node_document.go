@@ -45,6 +45,22 @@ func (d *Document) IsSameNode(n types.Node) bool {
 	return d.ptr == n.Pointer()
 }
 
+// IsEqualNode returns true if the two documents are structurally equal
+func (d *Document) IsEqualNode(n types.Node) bool {
+	return clib.XMLIsEqualNode(d, n)
+}
+
+// CompareDocumentPosition returns the position of n relative
+// to the document, as a combination of the DocumentPosition flags
+func (d *Document) CompareDocumentPosition(n types.Node) DocumentPosition {
+	return clib.XMLCompareDocumentPosition(d, n)
+}
+
+// Hash returns a value that identifies the underlying document
+func (d *Document) Hash() uint64 {
+	return uint64(d.ptr)
+}
+
 // HasChildNodes returns true if the document node is available
 func (d *Document) HasChildNodes() bool {
 	_, err := d.DocumentElement()
@@ -146,10 +162,9 @@ func (d *Document) ChildNodes() (types.NodeList, error) {
 	return []types.Node{root}, nil
 }
 
-// Copy is currently unimplemented
+// Copy creates a deep copy of the document. See Clone
 func (d *Document) Copy() (types.Node, error) {
-	// Unimplemented
-	return nil, errors.New("unimplemented")
+	return d.Clone()
 }
 
 // AddChild is a no op for Document
@@ -305,6 +320,31 @@ func (d *Document) URI() string {
 	return clib.XMLDocumentURI(d)
 }
 
+// Path always returns "/" for Document
+func (d *Document) Path() string {
+	return "/"
+}
+
+// Line always returns 0 for Document
+func (d *Document) Line() int {
+	return 0
+}
+
+// BaseURI returns the base URI of the document
+func (d *Document) BaseURI() string {
+	return clib.XMLNodeGetBase(d)
+}
+
+// Lang always returns an empty string for Document
+func (d *Document) Lang() string {
+	return ""
+}
+
+// SpacePreserved always returns false for Document
+func (d *Document) SpacePreserved() bool {
+	return false
+}
+
 // Version returns the version of the document
 func (d *Document) Version() string {
 	return clib.XMLDocumentVersion(d)
@@ -348,3 +388,139 @@ func (d *Document) RemoveChild(n types.Node) error {
 	}
 	return root.RemoveChild(n)
 }
+
+// GetElementsByTagName returns all elements in the document whose
+// qualified name matches name, in document order. The special
+// name "*" matches all elements.
+func (d *Document) GetElementsByTagName(name string) (types.NodeList, error) {
+	list, err := clib.XMLGetElementsByTagName(d, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get elements")
+	}
+	return wrapElementList(list), nil
+}
+
+// GetElementsByTagNameNS returns all elements in the document that
+// belong to the namespace nsuri and have the local name local, in
+// document order. The special value "*" matches any namespace or
+// local name.
+func (d *Document) GetElementsByTagNameNS(nsuri, local string) (types.NodeList, error) {
+	list, err := clib.XMLGetElementsByTagNameNS(d, nsuri, local)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get elements")
+	}
+	return wrapElementList(list), nil
+}
+
+// GetChildrenByTagName returns the top level elements whose qualified
+// name matches name. As a document can only contain one element,
+// this is either empty or the document element.
+func (d *Document) GetChildrenByTagName(name string) (types.NodeList, error) {
+	list, err := clib.XMLChildElements(d, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get child elements")
+	}
+	return wrapElementList(list), nil
+}
+
+// ChildElements returns the top level elements of the document
+func (d *Document) ChildElements() (types.NodeList, error) {
+	return d.GetChildrenByTagName("")
+}
+
+// FirstChildElement returns the document element.
+// ErrNodeNotFound is returned if there is no such node
+func (d *Document) FirstChildElement() (types.Element, error) {
+	ptr, err := clib.XMLFirstElementChild(d)
+	if err != nil {
+		return nil, err
+	}
+	return wrapElementNode(ptr), nil
+}
+
+// GetElementByID returns the element that carries the given ID.
+//
+// The lookup uses the document's ID table, which is populated by the
+// parser with attributes declared as ID in the DTD as well as `xml:id`
+// attributes (and `id` attributes in HTML documents). For documents
+// without a DTD, use RegisterIDAttribute to mark additional attributes
+// as IDs. ErrNodeNotFound is returned if no element carries the ID
+func (d *Document) GetElementByID(id string) (types.Element, error) {
+	ptr, err := clib.XMLGetID(d, id)
+	if err != nil {
+		return nil, err
+	}
+	return wrapElementNode(ptr), nil
+}
+
+// RegisterIDAttribute registers the values of all attributes in the
+// document whose qualified name matches name (e.g. "Id" or "wsu:Id")
+// as IDs, so that they can be looked up using GetElementByID.
+//
+// Only the attributes present when this method is called are
+// registered. Attributes added to the document afterwards must
+// be registered separately, either by calling this method again
+// or by using Element.SetIDAttribute
+func (d *Document) RegisterIDAttribute(name string) error {
+	if err := clib.XMLRegisterIDAttribute(d, name); err != nil {
+		return errors.Wrap(err, "failed to register ID attribute")
+	}
+	return nil
+}
+
+// RegisterIDAttributeNS is the same as RegisterIDAttribute, but
+// matches attributes by their namespace URI and local name
+func (d *Document) RegisterIDAttributeNS(nsuri, local string) error {
+	if err := clib.XMLRegisterIDAttributeNS(d, nsuri, local); err != nil {
+		return errors.Wrap(err, "failed to register ID attribute")
+	}
+	return nil
+}
+
+// ReconcileNamespaces makes sure that the namespaces used in the
+// document are declared. Missing declarations are added to the
+// document element
+func (d *Document) ReconcileNamespaces() error {
+	root, err := d.DocumentElement()
+	if err != nil {
+		return errors.Wrap(err, "failed to get document element")
+	}
+	return root.ReconcileNamespaces()
+}
+
+// RenameNamespace changes the URI of all declarations of the
+// namespace oldURI in the document to newURI
+func (d *Document) RenameNamespace(oldURI, newURI string) error {
+	return clib.XMLRenameNamespace(d, oldURI, newURI)
+}
+
+// StripNamespaces removes all namespaces and namespace declarations
+// from the document. See Element.StripNamespaces for details
+func (d *Document) StripNamespaces() error {
+	return clib.XMLStripNamespaces(d)
+}
+
+// RemoveUnusedNamespaceDeclarations removes the namespace declarations
+// in the document which are not used by any element or attribute name.
+// See Element.RemoveUnusedNamespaceDeclarations for details
+func (d *Document) RemoveUnusedNamespaceDeclarations() error {
+	return clib.XMLRemoveUnusedNamespaceDeclarations(d)
+}
+
+// Normalize merges adjacent text nodes and removes empty text
+// nodes in the document. See XMLNode.Normalize for details
+func (d *Document) Normalize() error {
+	return clib.XMLNormalize(d)
+}
+
+// StripBlankText removes the text nodes that only contain whitespace
+// from the document. See Element.StripBlankText for details
+func (d *Document) StripBlankText() error {
+	return clib.XMLStripBlankText(d)
+}
+
+// NormalizeWhitespace collapses each run of whitespace in the text
+// nodes of the document. See Element.NormalizeWhitespace for details
+func (d *Document) NormalizeWhitespace() error {
+	return clib.XMLNormalizeWhitespace(d)
+}
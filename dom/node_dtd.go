@@ -0,0 +1,265 @@
+package dom
+
+import (
+	"iter"
+
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/pkg/errors"
+)
+
+// Name returns the name of the document type, i.e. the name of the
+// document element it declares
+func (n *DTD) Name() string {
+	return n.NodeName()
+}
+
+// PublicID returns the public identifier of the external subset,
+// or an empty string if there is none
+func (n *DTD) PublicID() string {
+	return clib.XMLDtdPublicID(n)
+}
+
+// SystemID returns the system identifier of the external subset,
+// or an empty string if there is none
+func (n *DTD) SystemID() string {
+	return clib.XMLDtdSystemID(n)
+}
+
+// ElementDeclarations returns an iterator over the element
+// declarations in this DTD, in the order they were declared
+func (n *DTD) ElementDeclarations() iter.Seq[*ElementDeclaration] {
+	return func(yield func(*ElementDeclaration) bool) {
+		for c := firstChildPtr(n.ptr); c != 0; c = nextSiblingPtr(c) {
+			if clib.XMLGetNodeTypeRaw(c) != clib.ElementDecl {
+				continue
+			}
+			if !yield(wrapElementDeclarationNode(c)) {
+				return
+			}
+		}
+	}
+}
+
+// AttributeDeclarations returns an iterator over the attribute
+// declarations in this DTD, in the order they were declared. Each
+// attribute in an <!ATTLIST> declaration is reported separately
+func (n *DTD) AttributeDeclarations() iter.Seq[*AttributeDeclaration] {
+	return func(yield func(*AttributeDeclaration) bool) {
+		for c := firstChildPtr(n.ptr); c != 0; c = nextSiblingPtr(c) {
+			if clib.XMLGetNodeTypeRaw(c) != clib.AttributeDecl {
+				continue
+			}
+			if !yield(wrapAttributeDeclarationNode(c)) {
+				return
+			}
+		}
+	}
+}
+
+// EntityDeclarations returns an iterator over the general and
+// parameter entity declarations in this DTD, in the order they
+// were declared
+func (n *DTD) EntityDeclarations() iter.Seq[*EntityDeclaration] {
+	return func(yield func(*EntityDeclaration) bool) {
+		for c := firstChildPtr(n.ptr); c != 0; c = nextSiblingPtr(c) {
+			if clib.XMLGetNodeTypeRaw(c) != clib.EntityDecl {
+				continue
+			}
+			if !yield(wrapEntityDeclarationNode(c)) {
+				return
+			}
+		}
+	}
+}
+
+// GetElementDeclaration returns the declaration of the named element
+func (n *DTD) GetElementDeclaration(name string) (*ElementDeclaration, error) {
+	ptr, err := clib.XMLGetDtdElementDesc(n, name)
+	if err != nil {
+		return nil, err
+	}
+	return wrapElementDeclarationNode(ptr), nil
+}
+
+// GetAttributeDeclaration returns the declaration of the named
+// attribute of the named element
+func (n *DTD) GetAttributeDeclaration(elem, name string) (*AttributeDeclaration, error) {
+	ptr, err := clib.XMLGetDtdAttrDesc(n, elem, name)
+	if err != nil {
+		return nil, err
+	}
+	return wrapAttributeDeclarationNode(ptr), nil
+}
+
+// GetEntity returns the declaration of the named general entity
+// declared in this DTD
+func (n *DTD) GetEntity(name string) (*EntityDeclaration, error) {
+	for e := range n.EntityDeclarations() {
+		if e.IsParameter() || e.Name() != name {
+			continue
+		}
+		return e, nil
+	}
+	return nil, ErrNodeNotFound
+}
+
+// Name returns the name of the declared element
+func (n *ElementDeclaration) Name() string {
+	return n.NodeName()
+}
+
+// ContentModel returns the allowed content of the element as written
+// in the DTD, e.g. "EMPTY", "ANY", "(#PCDATA)" or "(title , para*)"
+func (n *ElementDeclaration) ContentModel() string {
+	s, err := clib.XMLElementDeclContent(n)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// Name returns the name of the declared attribute
+func (n *AttributeDeclaration) Name() string {
+	return n.NodeName()
+}
+
+// ElementName returns the name of the element the attribute belongs to
+func (n *AttributeDeclaration) ElementName() string {
+	s, err := clib.XMLAttributeDeclElement(n)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// AttributeType returns the declared type of the attribute, such as
+// "CDATA", "ID", "NMTOKENS" or "ENUMERATION"
+func (n *AttributeDeclaration) AttributeType() string {
+	s, err := clib.XMLAttributeDeclType(n)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// DefaultDecl returns "#REQUIRED", "#IMPLIED" or "#FIXED" according to
+// the declaration, or an empty string if only a default value is given
+func (n *AttributeDeclaration) DefaultDecl() string {
+	s, err := clib.XMLAttributeDeclDefault(n)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// DefaultValue returns the default (or fixed) value of the attribute
+func (n *AttributeDeclaration) DefaultValue() string {
+	s, err := clib.XMLAttributeDeclDefaultValue(n)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// Name returns the name of the declared entity
+func (n *EntityDeclaration) Name() string {
+	return n.NodeName()
+}
+
+// Content returns the replacement text of an internal entity, or
+// the notation name of an unparsed external entity
+func (n *EntityDeclaration) Content() string {
+	return n.NodeValue()
+}
+
+// PublicID returns the public identifier of an external entity
+func (n *EntityDeclaration) PublicID() string {
+	s, err := clib.XMLEntityPublicID(n)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// SystemID returns the system identifier of an external entity
+func (n *EntityDeclaration) SystemID() string {
+	s, err := clib.XMLEntitySystemID(n)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// EntityType returns the kind of the entity
+func (n *EntityDeclaration) EntityType() EntityType {
+	typ, err := clib.XMLEntityType(n)
+	if err != nil {
+		return 0
+	}
+	return typ
+}
+
+// IsParameter returns true if this is a parameter entity
+func (n *EntityDeclaration) IsParameter() bool {
+	switch n.EntityType() {
+	case InternalParameterEntity, ExternalParameterEntity:
+		return true
+	}
+	return false
+}
+
+// Doctype returns the document type declaration of the document,
+// or ErrNodeNotFound if it does not have one
+func (d *Document) Doctype() (*DTD, error) {
+	ptr, err := clib.XMLGetIntSubset(d)
+	if err != nil {
+		return nil, err
+	}
+	return wrapDTDNode(ptr), nil
+}
+
+// CreateDTD creates a document type declaration with the given name,
+// public identifier and system identifier, and adds it to the
+// document before the document element. Either identifier may be
+// empty. It is an error to call this if the document already has a
+// document type declaration; use SetInternalSubset to replace it
+func (d *Document) CreateDTD(name, publicID, systemID string) (*DTD, error) {
+	ptr, err := clib.XMLCreateIntSubset(d, name, publicID, systemID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create DTD")
+	}
+	return wrapDTDNode(ptr), nil
+}
+
+// SetInternalSubset replaces the document type declaration of the
+// document with a copy of dtd, along with all of its declarations.
+// dtd may belong to another document, and is not modified. The
+// previous declaration is detached but only freed along with the
+// document, as entity references and DTD values may still use it
+func (d *Document) SetInternalSubset(dtd *DTD) error {
+	if dtd == nil {
+		return clib.ErrInvalidNode
+	}
+	return clib.XMLSetIntSubset(d, dtd)
+}
+
+// GetEntity looks up a general entity by name. The internal and
+// external subsets are searched, followed by the predefined entities
+// such as "amp" and "lt"
+func (d *Document) GetEntity(name string) (*EntityDeclaration, error) {
+	ptr, err := clib.XMLGetDocEntity(d, name)
+	if err != nil {
+		return nil, err
+	}
+	return wrapEntityDeclarationNode(ptr), nil
+}
+
+// GetParameterEntity looks up a parameter entity by name in the
+// internal and external subsets
+func (d *Document) GetParameterEntity(name string) (*EntityDeclaration, error) {
+	ptr, err := clib.XMLGetParameterEntity(d, name)
+	if err != nil {
+		return nil, err
+	}
+	return wrapEntityDeclarationNode(ptr), nil
+}
@@ -2,11 +2,11 @@ package dom
 
 import (
 	"bytes"
-	"errors"
 	"strings"
 
 	"github.com/lestrrat-go/libxml2/clib"
 	"github.com/lestrrat-go/libxml2/types"
+	"github.com/pkg/errors"
 )
 
 // SetNamespace sets up a new namespace on the given node.
@@ -58,6 +58,67 @@ func (n *Element) SetNamespace(uri, prefix string, activate ...bool) error {
 	return nil
 }
 
+// DeclareNamespace declares the namespace uri with the given prefix
+// on this element, without changing the namespace of the element
+// itself. An empty prefix declares the default namespace. This is
+// useful to declare namespaces up front on a common ancestor, so
+// that they are not repeated on each descendant that uses them.
+func (n *Element) DeclareNamespace(prefix, uri string) error {
+	if uri == "" {
+		return errors.New("missing uri for DeclareNamespace")
+	}
+
+	_, err := clib.XMLDeclareNs(n, uri, prefix)
+	return err
+}
+
+// RenameNamespace changes the URI of all declarations of the
+// namespace oldURI within this element and its descendants to
+// newURI. Elements and attributes in that namespace are moved
+// to the new namespace, keeping their prefixes. If the namespace
+// is declared on an ancestor of this element, that declaration is
+// left alone and newURI is declared on this element instead.
+func (n *Element) RenameNamespace(oldURI, newURI string) error {
+	return clib.XMLRenameNamespace(n, oldURI, newURI)
+}
+
+// StripNamespaces removes the namespaces from this element, its
+// attributes and all of its descendants, along with all namespace
+// declarations. Attributes in the XML namespace such as xml:lang are
+// kept. If removing the namespace from an attribute would produce a
+// duplicate attribute, the namespaced attribute is dropped. The
+// removed declarations are released when the document is freed.
+func (n *Element) StripNamespaces() error {
+	return clib.XMLStripNamespaces(n)
+}
+
+// RemoveUnusedNamespaceDeclarations removes the namespace declarations
+// on this element and its descendants which are not used by any
+// element or attribute name. Note that namespaces that are only
+// referenced from attribute values or text (e.g. QNames in xsi:type)
+// are considered unused. The removed declarations are released when
+// the document is freed.
+func (n *Element) RemoveUnusedNamespaceDeclarations() error {
+	return clib.XMLRemoveUnusedNamespaceDeclarations(n)
+}
+
+// StripBlankText removes the text nodes that only contain whitespace
+// from this element and its descendants, except within the scope of
+// an xml:space="preserve" attribute. This is similar to parsing with
+// parser.XMLParseNoBlanks. The removed nodes are freed.
+func (n *Element) StripBlankText() error {
+	return clib.XMLStripBlankText(n)
+}
+
+// NormalizeWhitespace collapses each run of whitespace in the text
+// nodes of this element and its descendants into a single space,
+// except within the scope of an xml:space="preserve" attribute.
+// Whitespace only text nodes are collapsed, not removed: use
+// StripBlankText to remove them.
+func (n *Element) NormalizeWhitespace() error {
+	return clib.XMLNormalizeWhitespace(n)
+}
+
 // AppendText adds a new text node
 func (n *Element) AppendText(s string) error {
 	return clib.XMLAppendText(n, s)
@@ -68,6 +129,25 @@ func (n *Element) SetAttribute(name, value string) error {
 	return clib.XMLSetProp(n, name, value)
 }
 
+// SetTextContent replaces all children of the element with a single
+// text node containing the given string. The string is used as is,
+// without interpreting entity references. An empty string removes
+// all children
+func (n *Element) SetTextContent(s string) error {
+	return clib.XMLSetTextContent(n, s)
+}
+
+// SetLang sets the xml:lang attribute of the element
+func (n *Element) SetLang(lang string) error {
+	return clib.XMLNodeSetLang(n, lang)
+}
+
+// SetBase sets the xml:base attribute of the element, which changes
+// the base URI of the element and its descendants
+func (n *Element) SetBase(uri string) error {
+	return clib.XMLElementSetBase(n, uri)
+}
+
 // GetAttribute retrieves the value of an attribute
 func (n *Element) GetAttribute(name string) (types.Attribute, error) {
 	attrNode, err := clib.XMLElementGetAttributeNode(n, name)
@@ -144,3 +224,93 @@ func (n Element) Literal() (string, error) {
 	}
 	return buf.String(), nil
 }
+
+func wrapElementList(list []uintptr) types.NodeList {
+	ret := make(types.NodeList, len(list))
+	for i, ptr := range list {
+		ret[i] = wrapElementNode(ptr)
+	}
+	return ret
+}
+
+// GetElementsByTagName returns the descendant elements whose qualified
+// name matches name, in document order. The special name "*" matches
+// all elements. The element itself is not included.
+func (n *Element) GetElementsByTagName(name string) (types.NodeList, error) {
+	list, err := clib.XMLGetElementsByTagName(n, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get elements")
+	}
+	return wrapElementList(list), nil
+}
+
+// GetElementsByTagNameNS returns the descendant elements that belong
+// to the namespace nsuri and have the local name local, in document
+// order. The special value "*" matches any namespace or local name.
+func (n *Element) GetElementsByTagNameNS(nsuri, local string) (types.NodeList, error) {
+	list, err := clib.XMLGetElementsByTagNameNS(n, nsuri, local)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get elements")
+	}
+	return wrapElementList(list), nil
+}
+
+// GetChildrenByTagName returns the child elements whose qualified
+// name matches name. Unlike GetElementsByTagName, only the direct
+// children of this element are considered.
+func (n *Element) GetChildrenByTagName(name string) (types.NodeList, error) {
+	list, err := clib.XMLChildElements(n, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get child elements")
+	}
+	return wrapElementList(list), nil
+}
+
+// ChildElements returns the child nodes that are elements, skipping
+// text, comments and other non-element nodes
+func (n *Element) ChildElements() (types.NodeList, error) {
+	list, err := clib.XMLChildElements(n, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get child elements")
+	}
+	return wrapElementList(list), nil
+}
+
+// FirstChildElement returns the first child node that is an element.
+// ErrNodeNotFound is returned if there is no such node
+func (n *Element) FirstChildElement() (types.Element, error) {
+	ptr, err := clib.XMLFirstElementChild(n)
+	if err != nil {
+		return nil, err
+	}
+	return wrapElementNode(ptr), nil
+}
+
+// NextElementSibling returns the next sibling that is an element.
+// ErrNodeNotFound is returned if there is no such node
+func (n *Element) NextElementSibling() (types.Element, error) {
+	ptr, err := clib.XMLNextElementSibling(n)
+	if err != nil {
+		return nil, err
+	}
+	return wrapElementNode(ptr), nil
+}
+
+// SetIDAttribute declares the attribute with the given name to be
+// (or not to be) an ID, registering its value in the document's
+// ID table so that it can be found by Document.GetElementByID
+func (n *Element) SetIDAttribute(name string, isID bool) error {
+	attr, err := n.GetAttribute(name)
+	if err != nil {
+		return err
+	}
+
+	if isID {
+		return clib.XMLAddID(attr)
+	}
+
+	if !clib.XMLIsID(attr) {
+		return nil
+	}
+	return clib.XMLRemoveID(attr)
+}
@@ -0,0 +1,258 @@
+package dom
+
+import (
+	"errors"
+	"iter"
+
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/types"
+)
+
+var (
+	// SkipChildren can be returned from the callback given to Walk
+	// to skip the descendants of the current node. Walking resumes
+	// from the node's next sibling.
+	//
+	//nolint:errname,revive,stylecheck
+	SkipChildren = errors.New("skip children")
+
+	// SkipAll can be returned from the callback given to Walk
+	// to stop walking. Walk then returns nil.
+	//
+	//nolint:errname,revive,stylecheck
+	SkipAll = errors.New("skip all")
+)
+
+// nodePtr allows a raw C pointer obtained while traversing the
+// tree to be passed to clib, without wrapping it in a Node first
+type nodePtr uintptr
+
+func (p nodePtr) Pointer() uintptr {
+	return uintptr(p)
+}
+
+func firstChildPtr(p uintptr) uintptr {
+	switch clib.XMLGetNodeTypeRaw(p) {
+	case clib.NamespaceDecl, clib.EntityRefNode:
+		// Namespaces are not xmlNode structs, and the children of
+		// entity references belong to the entity declaration
+		return 0
+	}
+
+	return clib.XMLFirstChildPtr(nodePtr(p))
+}
+
+func nextSiblingPtr(p uintptr) uintptr {
+	if clib.XMLGetNodeTypeRaw(p) == clib.NamespaceDecl {
+		return 0
+	}
+
+	ptr, err := clib.XMLNextSibling(nodePtr(p))
+	if err != nil {
+		return 0
+	}
+	return ptr
+}
+
+func previousSiblingPtr(p uintptr) uintptr {
+	if clib.XMLGetNodeTypeRaw(p) == clib.NamespaceDecl {
+		return 0
+	}
+
+	ptr, err := clib.XMLPreviousSibling(nodePtr(p))
+	if err != nil {
+		return 0
+	}
+	return ptr
+}
+
+func parentPtr(p uintptr) uintptr {
+	if clib.XMLGetNodeTypeRaw(p) == clib.NamespaceDecl {
+		return 0
+	}
+
+	ptr, err := clib.XMLParentNode(nodePtr(p))
+	if err != nil {
+		return 0
+	}
+	return ptr
+}
+
+// wrapAny wraps the pointer in a Node, including Document nodes
+// which WrapNode does not handle
+func wrapAny(p uintptr) (types.Node, error) {
+	switch clib.XMLGetNodeTypeRaw(p) {
+	case clib.DocumentNode, clib.HTMLDocumentNode:
+		return WrapDocument(p), nil
+	}
+	return WrapNode(p)
+}
+
+// nextPreorderPtr returns the node following p in document order,
+// without descending below p if skipChildren is true, and without
+// leaving the subtree rooted at root
+func nextPreorderPtr(root, p uintptr, skipChildren bool) uintptr {
+	if !skipChildren {
+		if c := firstChildPtr(p); c != 0 {
+			return c
+		}
+	}
+
+	for p != root {
+		if next := nextSiblingPtr(p); next != 0 {
+			return next
+		}
+		p = parentPtr(p)
+		if p == 0 {
+			break
+		}
+	}
+	return 0
+}
+
+func siblingSeq(start uintptr, next func(uintptr) uintptr) iter.Seq[types.Node] {
+	return func(yield func(types.Node) bool) {
+		for p := next(start); p != 0; p = next(p) {
+			n, err := wrapAny(p)
+			if err != nil {
+				continue
+			}
+			if !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+func childrenSeq(p uintptr) iter.Seq[types.Node] {
+	return func(yield func(types.Node) bool) {
+		for c := firstChildPtr(p); c != 0; c = nextSiblingPtr(c) {
+			n, err := wrapAny(c)
+			if err != nil {
+				continue
+			}
+			if !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+func descendantsSeq(root uintptr) iter.Seq[types.Node] {
+	return func(yield func(types.Node) bool) {
+		for p := firstChildPtr(root); p != 0; p = nextPreorderPtr(root, p, false) {
+			n, err := wrapAny(p)
+			if err != nil {
+				continue
+			}
+			if !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+// Children returns an iterator over the child nodes. The children
+// are wrapped lazily, one at a time, as the iteration progresses.
+// Nodes which cannot be represented as a types.Node are skipped.
+func (n *XMLNode) Children() iter.Seq[types.Node] {
+	return childrenSeq(n.ptr)
+}
+
+// Descendants returns an iterator over all nodes below this node
+// in document order. The node itself is not included.
+func (n *XMLNode) Descendants() iter.Seq[types.Node] {
+	return descendantsSeq(n.ptr)
+}
+
+// Ancestors returns an iterator over the parent of this node,
+// its parent, and so on up to and including the document.
+func (n *XMLNode) Ancestors() iter.Seq[types.Node] {
+	return siblingSeq(n.ptr, parentPtr)
+}
+
+// FollowingSiblings returns an iterator over the siblings that
+// follow this node, in document order.
+func (n *XMLNode) FollowingSiblings() iter.Seq[types.Node] {
+	return siblingSeq(n.ptr, nextSiblingPtr)
+}
+
+// PrecedingSiblings returns an iterator over the siblings that
+// precede this node, in reverse document order (i.e. nearest first).
+func (n *XMLNode) PrecedingSiblings() iter.Seq[types.Node] {
+	return siblingSeq(n.ptr, previousSiblingPtr)
+}
+
+// AttributeIter returns an iterator over the attributes of the
+// element. Unlike Attributes, the attribute list is not materialized
+// up front.
+func (n *Element) AttributeIter() iter.Seq[types.Attribute] {
+	return func(yield func(types.Attribute) bool) {
+		for p := clib.XMLFirstAttributePtr(n); p != 0; p = nextSiblingPtr(p) {
+			if !yield(wrapAttributeNode(p)) {
+				return
+			}
+		}
+	}
+}
+
+// Children returns an iterator over the top level nodes of the
+// document, including the document type declaration, comments
+// and processing instructions.
+func (d *Document) Children() iter.Seq[types.Node] {
+	return childrenSeq(d.ptr)
+}
+
+// Descendants returns an iterator over all nodes in the document,
+// in document order.
+func (d *Document) Descendants() iter.Seq[types.Node] {
+	return descendantsSeq(d.ptr)
+}
+
+// Ancestors returns an empty iterator, as documents do not have parents.
+func (d *Document) Ancestors() iter.Seq[types.Node] {
+	return func(func(types.Node) bool) {}
+}
+
+// FollowingSiblings returns an empty iterator, as documents do
+// not have siblings.
+func (d *Document) FollowingSiblings() iter.Seq[types.Node] {
+	return func(func(types.Node) bool) {}
+}
+
+// PrecedingSiblings returns an empty iterator, as documents do
+// not have siblings.
+func (d *Document) PrecedingSiblings() iter.Seq[types.Node] {
+	return func(func(types.Node) bool) {}
+}
+
+// Walk traverses n and all of its descendants in document order,
+// calling fn on each node. Unlike the Walk method, nodes are visited
+// lazily without materializing the list of children at each level.
+//
+// If fn returns SkipChildren, the descendants of the current node
+// are skipped. If fn returns SkipAll, the traversal stops and Walk
+// returns nil. Any other error stops the traversal and is returned.
+func Walk(n types.Node, fn func(types.Node) error) error {
+	root := n.Pointer()
+	if root == 0 {
+		return clib.ErrInvalidNode
+	}
+
+	for p := root; p != 0; {
+		var skip bool
+		if cur, err := wrapAny(p); err == nil {
+			switch err := fn(cur); {
+			case err == nil:
+			case errors.Is(err, SkipChildren):
+				skip = true
+			case errors.Is(err, SkipAll):
+				return nil
+			default:
+				return err
+			}
+		}
+		p = nextPreorderPtr(root, p, skip)
+	}
+	return nil
+}
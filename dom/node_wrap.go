@@ -51,6 +51,30 @@ func wrapPiNode(ptr uintptr) *Pi {
 	return &n
 }
 
+func wrapDTDNode(ptr uintptr) *DTD {
+	var n DTD
+	n.ptr = ptr
+	return &n
+}
+
+func wrapElementDeclarationNode(ptr uintptr) *ElementDeclaration {
+	var n ElementDeclaration
+	n.ptr = ptr
+	return &n
+}
+
+func wrapAttributeDeclarationNode(ptr uintptr) *AttributeDeclaration {
+	var n AttributeDeclaration
+	n.ptr = ptr
+	return &n
+}
+
+func wrapEntityDeclarationNode(ptr uintptr) *EntityDeclaration {
+	var n EntityDeclaration
+	n.ptr = ptr
+	return &n
+}
+
 // WrapNode is a function created with the sole purpose of allowing
 // go-libxml2 consumers that can generate a C.xmlNode pointer to
 // create libxml2.Node types, e.g. go-xmlsec.
@@ -68,6 +92,14 @@ func WrapNode(n uintptr) (types.Node, error) {
 		return wrapTextNode(n), nil
 	case clib.PiNode:
 		return wrapPiNode(n), nil
+	case clib.DTDNode:
+		return wrapDTDNode(n), nil
+	case clib.ElementDecl:
+		return wrapElementDeclarationNode(n), nil
+	case clib.AttributeDecl:
+		return wrapAttributeDeclarationNode(n), nil
+	case clib.EntityDecl:
+		return wrapEntityDeclarationNode(n), nil
 	default:
 		return nil, fmt.Errorf("unknown node: %d", typ)
 	}
@@ -0,0 +1,99 @@
+package dom_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/dom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	doc := dom.CreateDocument()
+	defer doc.Free()
+
+	root, err := doc.CreateElement("root")
+	if !assert.NoError(t, err, "CreateElement should succeed") {
+		return
+	}
+	_ = doc.SetDocumentElement(root)
+
+	for _, s := range []string{"a", "b", ""} {
+		if !assert.NoError(t, root.AppendText(s), "AppendText should succeed") {
+			return
+		}
+	}
+	child, err := doc.CreateElement("child")
+	if !assert.NoError(t, err, "CreateElement should succeed") {
+		return
+	}
+	_ = root.AddChild(child)
+	empty, err := doc.CreateTextNode("")
+	if !assert.NoError(t, err, "CreateTextNode should succeed") {
+		return
+	}
+	_ = root.AddChild(empty)
+
+	if !assert.NoError(t, doc.Normalize(), "Normalize should succeed") {
+		return
+	}
+
+	children, err := root.ChildNodes()
+	if !assert.NoError(t, err, "ChildNodes should succeed") {
+		return
+	}
+	if !assert.Equal(t, []string{"#text", "child"}, nodeNames(children), "text nodes are merged and empty ones dropped") {
+		return
+	}
+	if !assert.Equal(t, "ab", children[0].NodeValue(), "text is merged") {
+		return
+	}
+}
+
+func TestWhitespace(t *testing.T) {
+	const src = `<root>
+  <p>Hello,
+     world <b>!</b></p>
+  <pre xml:space="preserve">  keep
+  this  </pre>
+</root>`
+
+	t.Run("StripBlankText", func(t *testing.T) {
+		doc, err := libxml2.ParseString(src)
+		if !assert.NoError(t, err, "ParseString should succeed") {
+			return
+		}
+		defer doc.Free()
+
+		if !assert.NoError(t, doc.StripBlankText(), "StripBlankText should succeed") {
+			return
+		}
+
+		root, err := doc.DocumentElement()
+		if !assert.NoError(t, err, "DocumentElement should succeed") {
+			return
+		}
+		if !assert.Equal(t, "<root><p>Hello,\n     world <b>!</b></p><pre xml:space=\"preserve\">  keep\n  this  </pre></root>", root.String(), "blank text is stripped") {
+			return
+		}
+	})
+	t.Run("NormalizeWhitespace", func(t *testing.T) {
+		doc, err := libxml2.ParseString(src)
+		if !assert.NoError(t, err, "ParseString should succeed") {
+			return
+		}
+		defer doc.Free()
+
+		if !assert.NoError(t, doc.NormalizeWhitespace(), "NormalizeWhitespace should succeed") {
+			return
+		}
+
+		root, err := doc.DocumentElement()
+		if !assert.NoError(t, err, "DocumentElement should succeed") {
+			return
+		}
+		if !assert.Equal(t, "<root> <p>Hello, world <b>!</b></p> <pre xml:space=\"preserve\">  keep\n  this  </pre> </root>", root.String(), "whitespace is collapsed") {
+			return
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package dom
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/types"
+)
+
+// SortDocumentOrder sorts the nodes in the list in place, in
+// document order. This is useful when merging the results of
+// several XPath queries. Nodes that do not belong to the same
+// tree are grouped by tree, and the trees are ordered consistently,
+// but arbitrarily.
+func SortDocumentOrder(list types.NodeList) {
+	slices.SortStableFunc(list, func(a, b types.Node) int {
+		if c := cmp.Compare(clib.XMLTreeRoot(a), clib.XMLTreeRoot(b)); c != 0 {
+			return c
+		}
+
+		c, err := clib.XMLXPathCmpNodes(a, b)
+		if err != nil {
+			return 0
+		}
+		return c
+	})
+}
+
+// UniqueNodes returns a list containing the nodes in list, with
+// duplicates (i.e. nodes that wrap the same underlying node) removed.
+// The order of the first occurrence of each node is preserved
+func UniqueNodes(list types.NodeList) types.NodeList {
+	seen := make(map[uint64]struct{}, len(list))
+	ret := make(types.NodeList, 0, len(list))
+	for _, n := range list {
+		h := n.Hash()
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+		ret = append(ret, n)
+	}
+	return ret
+}
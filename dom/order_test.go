@@ -0,0 +1,143 @@
+package dom_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/dom"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/lestrrat-go/libxml2/xpath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsEqualNode(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root xmlns:a="http://a" xmlns:b="http://a">
+<x:item xmlns:x="http://x" id="1" type="t">text<!--c--></x:item>
+<x:item xmlns:x="http://x" type="t" id="1">text<!--c--></x:item>
+<x:item xmlns:x="http://x" type="t" id="2">text<!--c--></x:item>
+<y:item xmlns:y="http://x" type="t" id="1">text<!--c--></y:item>
+<x:item xmlns:x="http://x" type="t" id="1">text</x:item>
+<item a:attr="v"/>
+<item b:attr="v"/>
+</root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	items, err := doc.GetElementsByTagNameNS("*", "item")
+	if !assert.NoError(t, err, "GetElementsByTagNameNS should succeed") {
+		return
+	}
+	if !assert.Len(t, items, 7, "items are found") {
+		return
+	}
+
+	if !assert.True(t, items[0].IsEqualNode(items[1]), "attribute order does not matter") {
+		return
+	}
+	if !assert.False(t, items[0].IsEqualNode(items[2]), "attribute values matter") {
+		return
+	}
+	if !assert.False(t, items[0].IsEqualNode(items[3]), "prefixes matter") {
+		return
+	}
+	if !assert.False(t, items[0].IsEqualNode(items[4]), "children matter") {
+		return
+	}
+	if !assert.False(t, items[5].IsEqualNode(items[6]), "attribute prefixes matter") {
+		return
+	}
+
+	other, err := libxml2.ParseString(`<x:item xmlns:x="http://x" id="1" type="t">text<!--c--></x:item>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer other.Free()
+
+	root, err := other.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+	if !assert.True(t, root.IsEqualNode(items[0]), "nodes in different documents can be equal") {
+		return
+	}
+}
+
+func TestDocumentOrder(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root><a><b/></a><c/></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	find := func(name string) types.Node {
+		list, err := doc.GetElementsByTagName(name)
+		if !assert.NoError(t, err, "GetElementsByTagName should succeed") || !assert.Len(t, list, 1, "one element found") {
+			t.FailNow()
+		}
+		return list[0]
+	}
+	root, a, b, c := find("root"), find("a"), find("b"), find("c")
+
+	if !assert.Equal(t, dom.DocumentPosition(0), a.CompareDocumentPosition(a), "same node") {
+		return
+	}
+	if !assert.Equal(t, dom.DocumentPositionFollowing, a.CompareDocumentPosition(c), "c follows a") {
+		return
+	}
+	if !assert.Equal(t, dom.DocumentPositionPreceding, c.CompareDocumentPosition(b), "b precedes c") {
+		return
+	}
+	if !assert.Equal(t, dom.DocumentPositionContainedBy|dom.DocumentPositionFollowing, root.CompareDocumentPosition(b), "root contains b") {
+		return
+	}
+	if !assert.Equal(t, dom.DocumentPositionContains|dom.DocumentPositionPreceding, b.CompareDocumentPosition(a), "a contains b") {
+		return
+	}
+
+	other, err := libxml2.ParseString(`<root/>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer other.Free()
+	if !assert.NotZero(t, a.CompareDocumentPosition(other)&dom.DocumentPositionDisconnected, "nodes are disconnected") {
+		return
+	}
+	if !assert.Equal(t, dom.DocumentPositionDisconnected, a.CompareDocumentPosition(nil), "nil node is disconnected") {
+		return
+	}
+
+	// Merge the results of two queries
+	list := append(xpath.NodeList(doc.Find(`//c | //a`)), xpath.NodeList(doc.Find(`//b | //a`))...)
+	if !assert.Len(t, list, 4, "four nodes found") {
+		return
+	}
+
+	list = dom.UniqueNodes(list)
+	dom.SortDocumentOrder(list)
+	if !assert.Equal(t, []string{"a", "b", "c"}, nodeNames(list), "nodes are deduplicated and sorted") {
+		return
+	}
+	if !assert.Equal(t, a.Hash(), list[0].Hash(), "hash identifies the node") {
+		return
+	}
+
+	// Nodes from different documents are grouped by document
+	root2, err := other.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+	mixed := types.NodeList{b, root2, a, root}
+	dom.SortDocumentOrder(mixed)
+	hashes := make([]uint64, len(mixed))
+	for i, n := range mixed {
+		hashes[i] = n.Hash()
+	}
+	if !assert.Contains(t, [][]uint64{
+		{root.Hash(), a.Hash(), b.Hash(), root2.Hash()},
+		{root2.Hash(), root.Hash(), a.Hash(), b.Hash()},
+	}, hashes, "nodes are grouped by document, in document order") {
+		return
+	}
+}
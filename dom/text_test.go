@@ -0,0 +1,149 @@
+package dom_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetTextContent(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root><a>old<b/>text</a></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	root, err := doc.FirstChildElement()
+	if !assert.NoError(t, err, "FirstChildElement should succeed") {
+		return
+	}
+	a, err := root.FirstChildElement()
+	if !assert.NoError(t, err, "FirstChildElement should succeed") {
+		return
+	}
+
+	if !assert.NoError(t, a.SetTextContent("fish & <chips>"), "SetTextContent should succeed") {
+		return
+	}
+	if !assert.Equal(t, "fish & <chips>", a.TextContent(), "text is set literally") {
+		return
+	}
+	if !assert.Equal(t, `<a>fish &amp; &lt;chips&gt;</a>`, a.String(), "children are replaced") {
+		return
+	}
+
+	if !assert.NoError(t, a.SetTextContent(""), "SetTextContent should succeed") {
+		return
+	}
+	if !assert.False(t, a.HasChildNodes(), "children are removed") {
+		return
+	}
+}
+
+func TestLang(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root xml:lang="en"><p>hello<span xml:lang="fr">bonjour</span></p></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	list, err := doc.GetElementsByTagName("*")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+		return
+	}
+
+	expected := map[string]string{"root": "en", "p": "en", "span": "fr"}
+	for _, n := range list {
+		if !assert.Equal(t, expected[n.NodeName()], n.Lang(), "language of %s", n.NodeName()) {
+			return
+		}
+	}
+
+	//nolint:forcetypeassert
+	p := list[1].(types.Element)
+	text, err := p.FirstChild()
+	if !assert.NoError(t, err, "FirstChild should succeed") {
+		return
+	}
+	if !assert.Equal(t, "en", text.Lang(), "text nodes inherit the language") {
+		return
+	}
+
+	if !assert.NoError(t, p.SetLang("de"), "SetLang should succeed") {
+		return
+	}
+	if !assert.Equal(t, "de", text.Lang(), "language is changed") {
+		return
+	}
+	if !assert.Equal(t, `<p xml:lang="de">hello<span xml:lang="fr">bonjour</span></p>`, p.String(), "xml:lang is set") {
+		return
+	}
+	if !assert.Equal(t, "", doc.Lang(), "document has no language") {
+		return
+	}
+}
+
+func TestSpacePreserved(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root><pre xml:space="preserve"><code> x </code><p xml:space="default"> y </p></pre></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	list, err := doc.GetElementsByTagName("*")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+		return
+	}
+
+	expected := map[string]bool{"root": false, "pre": true, "code": true, "p": false}
+	for _, n := range list {
+		if !assert.Equal(t, expected[n.NodeName()], n.SpacePreserved(), "space preservation of %s", n.NodeName()) {
+			return
+		}
+
+		text, err := n.FirstChild()
+		if err != nil || text.NodeType() != clib.TextNode {
+			continue
+		}
+		if !assert.Equal(t, expected[n.NodeName()], text.SpacePreserved(), "text in %s follows its parent", n.NodeName()) {
+			return
+		}
+	}
+}
+
+func TestSetBase(t *testing.T) {
+	doc, err := libxml2.ParseString(`<root><a><b/></a></root>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	root, err := doc.FirstChildElement()
+	if !assert.NoError(t, err, "FirstChildElement should succeed") {
+		return
+	}
+	a, err := root.FirstChildElement()
+	if !assert.NoError(t, err, "FirstChildElement should succeed") {
+		return
+	}
+	b, err := a.FirstChildElement()
+	if !assert.NoError(t, err, "FirstChildElement should succeed") {
+		return
+	}
+
+	if !assert.NoError(t, root.SetBase("http://example.com/docs/"), "SetBase should succeed") {
+		return
+	}
+	if !assert.NoError(t, a.SetBase("guide/"), "SetBase should succeed") {
+		return
+	}
+	if !assert.Equal(t, "http://example.com/docs/guide/", b.BaseURI(), "base URI is resolved") {
+		return
+	}
+	if !assert.Equal(t, `<a xml:base="guide/"><b/></a>`, a.String(), "xml:base is set") {
+		return
+	}
+}
@@ -0,0 +1,98 @@
+// Package dtd contains the tools available from libxml2 that allow
+// you to validate your XML against a DTD
+//
+// To validate against a separately maintained DTD:
+//
+//	d, err := dtd.ParseFile("book.dtd")
+//	if err != nil {
+//	    panic(err)
+//	}
+//	defer d.Free()
+//	if err := d.Validate(doc); err != nil {
+//	    for _, e := range err.(dtd.ValidationError).Errors() {
+//	         println(e.Error())
+//	    }
+//	}
+//
+// To validate against the DTD declared by the document itself,
+// use dtd.ValidateDocument(doc)
+package dtd
+
+import (
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/pkg/errors"
+)
+
+// Parse parses the declarations in buf, as found in an external
+// subset, to produce a DTD instance. Make sure to call Free() on
+// the instance when you are done with it.
+func Parse(buf []byte) (*DTD, error) {
+	ptr, err := clib.XMLParseDTD(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse input")
+	}
+	return &DTD{ptr: ptr}, nil
+}
+
+// ParseFile parses the DTD stored in the given file. Make sure to
+// call Free() on the instance when you are done with it.
+func ParseFile(path string) (*DTD, error) {
+	ptr, err := clib.XMLParseDTDFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse input from file")
+	}
+	return &DTD{ptr: ptr}, nil
+}
+
+// Pointer returns the underlying C struct
+func (d *DTD) Pointer() uintptr {
+	return d.ptr
+}
+
+// Free frees the underlying C struct
+func (d *DTD) Free() {
+	if err := clib.XMLFreeDtd(d); err != nil {
+		return
+	}
+	d.ptr = 0
+}
+
+// Validate validates the document against the DTD. Any DTD declared
+// by the document itself is ignored. If there are any problems, a
+// ValidationError is returned.
+func (d *DTD) Validate(doc types.Document) error {
+	if errs := clib.XMLValidateDtd(d, doc); errs != nil {
+		return ValidationError{errors: errs}
+	}
+	return nil
+}
+
+// ValidateElement validates the element and its descendants against
+// the DTD. If there are any problems, a ValidationError is returned.
+func (d *DTD) ValidateElement(elem types.Element) error {
+	if errs := clib.XMLValidateDtdElement(d, elem); errs != nil {
+		return ValidationError{errors: errs}
+	}
+	return nil
+}
+
+// ValidateDocument validates the document against the DTD it declares
+// in its internal subset, and the external subset it references.
+// If there are any problems, a ValidationError is returned.
+func ValidateDocument(doc types.Document) error {
+	if errs := clib.XMLValidateDocument(doc); errs != nil {
+		return ValidationError{errors: errs}
+	}
+	return nil
+}
+
+// Error method fulfils the error interface
+func (ve ValidationError) Error() string {
+	return "dtd validation failed"
+}
+
+// Errors returns the list of errors found
+func (ve ValidationError) Errors() []error {
+	return ve.errors
+}
@@ -0,0 +1,30 @@
+package dtd
+
+import "github.com/lestrrat-go/libxml2/clib"
+
+// DTD represents a document type definition which was parsed
+// independently of any document, i.e. an external subset.
+type DTD struct {
+	ptr uintptr // *C.xmlDtd
+}
+
+// ValidationError is returned when the Validate() function
+// finds errors. When there are multiple errors, you may access
+// them using the Errors() method
+type ValidationError struct {
+	errors []error
+}
+
+// Error holds the details of a single validation error. The values
+// returned by ValidationError.Errors() are of this type
+type Error = clib.StructuredError
+
+// ErrorLevel is the severity of an Error
+type ErrorLevel = clib.ErrorLevel
+
+const (
+	ErrorLevelNone    = clib.ErrorLevelNone
+	ErrorLevelWarning = clib.ErrorLevelWarning
+	ErrorLevelError   = clib.ErrorLevelError
+	ErrorLevelFatal   = clib.ErrorLevelFatal
+)
@@ -0,0 +1,166 @@
+package libxml2_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/dtd"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTD(t *testing.T) {
+	dtdfile := filepath.Join("test", "dtd", "book.dtd")
+
+	t.Run("ParseFile", func(t *testing.T) {
+		d, err := dtd.ParseFile(dtdfile)
+		if !assert.NoError(t, err, "dtd.ParseFile should succeed") {
+			return
+		}
+		defer d.Free()
+	})
+	t.Run("Parse errors", func(t *testing.T) {
+		_, err := dtd.Parse([]byte(`<!ELEMENT book (title`))
+		if !assert.Error(t, err, "dtd.Parse should fail") {
+			return
+		}
+		t.Logf("err (OK): '%s'", err)
+	})
+
+	buf, err := os.ReadFile(dtdfile)
+	if !assert.NoError(t, err, "reading DTD") {
+		return
+	}
+
+	d, err := dtd.Parse(buf)
+	if !assert.NoError(t, err, "dtd.Parse should succeed") {
+		return
+	}
+	defer d.Free()
+
+	t.Run("valid", func(t *testing.T) {
+		doc, err := libxml2.ParseString(`<book id="b1"><title>T</title><chapter>one <em>two</em></chapter></book>`)
+		if !assert.NoError(t, err, "parsing XML") {
+			return
+		}
+		defer doc.Free()
+
+		if !assert.NoError(t, d.Validate(doc), "d.Validate should pass") {
+			return
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		doc, err := libxml2.ParseString(`<book>
+<title>T</title>
+<chapter ref="nowhere"><title>oops</title></chapter>
+</book>`)
+		if !assert.NoError(t, err, "parsing XML") {
+			return
+		}
+		defer doc.Free()
+
+		err = d.Validate(doc)
+		if !assert.Error(t, err, "d.Validate should fail") {
+			return
+		}
+
+		verr, ok := err.(dtd.ValidationError)
+		if !assert.True(t, ok, "error is dtd.ValidationError") {
+			return
+		}
+		if !assert.NotEmpty(t, verr.Errors(), "errors are reported") {
+			return
+		}
+
+		var paths []string
+		for _, e := range verr.Errors() {
+			t.Logf("err (OK): '%s'", e)
+			derr, ok := e.(dtd.Error)
+			if !assert.True(t, ok, "each error is a dtd.Error") {
+				return
+			}
+			if !assert.Equal(t, dtd.ErrorLevelError, derr.Level, "level is error") {
+				return
+			}
+			paths = append(paths, derr.Path)
+			if derr.Path == "/book/chapter" && !assert.Equal(t, 3, derr.Line, "line is reported") {
+				return
+			}
+		}
+		if !assert.Contains(t, paths, "/book", "missing ID attribute is reported") {
+			return
+		}
+		if !assert.Contains(t, paths, "/book/chapter", "invalid content is reported") {
+			return
+		}
+	})
+	t.Run("ValidateElement", func(t *testing.T) {
+		doc, err := libxml2.ParseString(`<book><title>T</title><chapter>one <em>two</em></chapter><chapter><em><b/></em></chapter></book>`)
+		if !assert.NoError(t, err, "parsing XML") {
+			return
+		}
+		defer doc.Free()
+
+		chapters, err := doc.GetElementsByTagName("chapter")
+		if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+			return
+		}
+
+		//nolint:forcetypeassert
+		if !assert.NoError(t, d.ValidateElement(chapters[0].(types.Element)), "first chapter is valid") {
+			return
+		}
+		//nolint:forcetypeassert
+		if !assert.Error(t, d.ValidateElement(chapters[1].(types.Element)), "second chapter is invalid") {
+			return
+		}
+	})
+}
+
+func TestDTDValidateDocument(t *testing.T) {
+	t.Run("internal subset", func(t *testing.T) {
+		doc, err := libxml2.ParseString(`<!DOCTYPE note [
+<!ELEMENT note (to, body)>
+<!ELEMENT to (#PCDATA)>
+<!ELEMENT body (#PCDATA)>
+]>
+<note><to>you</to></note>`)
+		if !assert.NoError(t, err, "parsing XML") {
+			return
+		}
+		defer doc.Free()
+
+		err = dtd.ValidateDocument(doc)
+		if !assert.Error(t, err, "dtd.ValidateDocument should fail") {
+			return
+		}
+		//nolint:forcetypeassert
+		for _, e := range err.(dtd.ValidationError).Errors() {
+			t.Logf("err (OK): '%s'", e)
+		}
+	})
+	t.Run("external subset", func(t *testing.T) {
+		doc, err := libxml2.ParseString(`<!DOCTYPE book SYSTEM "test/dtd/book.dtd"><book id="b1"><title>T</title><chapter/></book>`)
+		if !assert.NoError(t, err, "parsing XML") {
+			return
+		}
+		defer doc.Free()
+
+		if !assert.NoError(t, dtd.ValidateDocument(doc), "dtd.ValidateDocument should pass") {
+			return
+		}
+	})
+	t.Run("no DTD", func(t *testing.T) {
+		doc, err := libxml2.ParseString(`<book/>`)
+		if !assert.NoError(t, err, "parsing XML") {
+			return
+		}
+		defer doc.Free()
+
+		if !assert.Error(t, dtd.ValidateDocument(doc), "dtd.ValidateDocument should fail") {
+			return
+		}
+	})
+}
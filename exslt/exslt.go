@@ -0,0 +1,224 @@
+// Package exslt enables the EXSLT extensions (http://exslt.org)
+// implemented by libexslt. Importing it, which requires libexslt,
+// makes the extensions available to all XSLT stylesheets, and allows
+// xpath.Context.RegisterEXSLT() to register them with XPath contexts:
+//
+//	import _ "github.com/lestrrat-go/libxml2/exslt"
+//
+// The common, math, sets, strings, dates and times and dynamic
+// modules are available to both. Stylesheets may also use the
+// functions and crypto modules. Note that libexslt does not implement
+// the regular expressions module.
+package exslt
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+#include <libxml/tree.h>
+#include <libxml/xpath.h>
+#include <libxml/xpathInternals.h>
+#include <libxslt/xsltInternals.h>
+#include <libxslt/transform.h>
+#include <libxslt/extensions.h>
+#include <libexslt/exslt.h>
+
+// Some EXSLT functions, such as str:tokenize() and exsl:node-set(),
+// build result tree fragments, which requires a transformation
+// context. XPath contexts get an empty one, which holds on to the
+// fragments until it is freed
+static
+int
+MY_exsltPrepareXPath(uintptr_t ctx) {
+	xmlXPathContextPtr ctxt = (xmlXPathContextPtr) ctx;
+	xsltStylesheetPtr style;
+	xsltTransformContextPtr tctxt;
+	xmlDocPtr doc;
+
+	if (ctxt->extra != NULL) {
+		return 0;
+	}
+
+	style = xsltNewStylesheet();
+	if (style == NULL) {
+		return -1;
+	}
+	doc = xmlNewDoc((const xmlChar *) "1.0");
+	if (doc == NULL) {
+		xsltFreeStylesheet(style);
+		return -1;
+	}
+	tctxt = xsltNewTransformContext(style, doc);
+	if (tctxt == NULL) {
+		xmlFreeDoc(doc);
+		xsltFreeStylesheet(style);
+		return -1;
+	}
+
+	ctxt->extra = tctxt;
+	return 0;
+}
+
+static
+void
+MY_exsltReleaseXPath(uintptr_t ctx) {
+	xmlXPathContextPtr ctxt = (xmlXPathContextPtr) ctx;
+	xsltTransformContextPtr tctxt = (xsltTransformContextPtr) ctxt->extra;
+	xsltStylesheetPtr style;
+	xmlDocPtr doc;
+
+	if (tctxt == NULL) {
+		return;
+	}
+
+	style = tctxt->style;
+	doc = tctxt->document->doc;
+	xsltFreeTransformContext(tctxt);
+	xsltFreeStylesheet(style);
+	xmlFreeDoc(doc);
+	ctxt->extra = NULL;
+}
+
+// MY_exsltRegisterFunction registers the EXSLT function with the
+// XPath context. It returns 1 if libexslt does not implement it
+static
+int
+MY_exsltRegisterFunction(uintptr_t ctx, const char *name, const char *ns) {
+	xmlXPathFunction f;
+
+	f = xsltExtModuleFunctionLookup((const xmlChar *) name, (const xmlChar *) ns);
+	if (f == NULL) {
+		return 1;
+	}
+	return xmlXPathRegisterFuncNS((xmlXPathContextPtr) ctx, (const xmlChar *) name, (const xmlChar *) ns, f);
+}
+
+static
+int
+MY_exsltRegisterNs(uintptr_t ctx, const char *prefix, const char *ns) {
+	return xmlXPathRegisterNs((xmlXPathContextPtr) ctx, (const xmlChar *) prefix, (const xmlChar *) ns);
+}
+*/
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/lestrrat-go/libxml2/internal/exslt"
+	"github.com/pkg/errors"
+)
+
+// Namespaces of the EXSLT modules
+const (
+	CommonNamespace    = `http://exslt.org/common`
+	CryptoNamespace    = `http://exslt.org/crypto`
+	MathNamespace      = `http://exslt.org/math`
+	SetsNamespace      = `http://exslt.org/sets`
+	FunctionsNamespace = `http://exslt.org/functions`
+	StringsNamespace   = `http://exslt.org/strings`
+	DatesNamespace     = `http://exslt.org/dates-and-times`
+	DynamicNamespace   = `http://exslt.org/dynamic`
+)
+
+// module lists the functions of an EXSLT module, along with the
+// prefix they are bound to in XPath contexts
+type module struct {
+	prefix    string
+	namespace string
+	functions []string
+}
+
+var xpathModules = []module{
+	{
+		prefix:    "exsl",
+		namespace: CommonNamespace,
+		functions: []string{"node-set", "object-type"},
+	},
+	{
+		prefix:    "math",
+		namespace: MathNamespace,
+		functions: []string{
+			"min", "max", "highest", "lowest", "constant", "random",
+			"abs", "sqrt", "power", "log", "sin", "cos", "tan", "asin",
+			"acos", "atan", "atan2", "exp",
+		},
+	},
+	{
+		prefix:    "set",
+		namespace: SetsNamespace,
+		functions: []string{"difference", "intersection", "distinct", "has-same-node", "leading", "trailing"},
+	},
+	{
+		prefix:    "str",
+		namespace: StringsNamespace,
+		functions: []string{"tokenize", "split", "encode-uri", "decode-uri", "padding", "align", "concat", "replace"},
+	},
+	{
+		prefix:    "date",
+		namespace: DatesNamespace,
+		functions: []string{
+			"add", "add-duration", "date", "date-time", "day-abbreviation",
+			"day-in-month", "day-in-week", "day-in-year", "day-name",
+			"day-of-week-in-month", "difference", "duration", "hour-in-day",
+			"leap-year", "minute-in-hour", "month-abbreviation",
+			"month-in-year", "month-name", "second-in-minute", "seconds",
+			"sum", "time", "week-in-month", "week-in-year", "year",
+		},
+	},
+	{
+		prefix:    "dyn",
+		namespace: DynamicNamespace,
+		functions: []string{"evaluate", "map"},
+	},
+}
+
+// prepared holds the XPath contexts that were given a transformation
+// context, which must be released when they are freed
+var prepared sync.Map
+
+func init() {
+	C.exsltRegisterAll()
+	exslt.RegisterXPath = registerXPath
+	exslt.ReleaseXPath = releaseXPath
+}
+
+func registerXPath(ctx uintptr) error {
+	if ctx == 0 {
+		return errors.New("invalid xpath context")
+	}
+
+	if C.MY_exsltPrepareXPath(C.uintptr_t(ctx)) != 0 {
+		return errors.New("failed to create transformation context")
+	}
+	prepared.Store(ctx, struct{}{})
+
+	for _, m := range xpathModules {
+		cprefix := C.CString(m.prefix)
+		cns := C.CString(m.namespace)
+		res := C.MY_exsltRegisterNs(C.uintptr_t(ctx), cprefix, cns)
+		for _, name := range m.functions {
+			if res != 0 {
+				break
+			}
+			cname := C.CString(name)
+			// Functions libexslt does not implement are skipped
+			if C.MY_exsltRegisterFunction(C.uintptr_t(ctx), cname, cns) < 0 {
+				res = -1
+			}
+			C.free(unsafe.Pointer(cname))
+		}
+		C.free(unsafe.Pointer(cprefix))
+		C.free(unsafe.Pointer(cns))
+
+		if res != 0 {
+			return errors.Errorf("failed to register EXSLT module %s", m.namespace)
+		}
+	}
+	return nil
+}
+
+func releaseXPath(ctx uintptr) {
+	if _, ok := prepared.LoadAndDelete(ctx); ok {
+		C.MY_exsltReleaseXPath(C.uintptr_t(ctx))
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !static_build
+// +build !static_build
+
+package exslt
+
+// #cgo pkg-config: libexslt
+import "C"
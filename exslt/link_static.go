@@ -0,0 +1,8 @@
+//go:build static_build
+// +build static_build
+
+package exslt
+
+// #cgo pkg-config: --static libexslt
+// #cgo LDFLAGS: -static
+import "C"
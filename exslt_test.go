@@ -0,0 +1,107 @@
+package libxml2_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	_ "github.com/lestrrat-go/libxml2/exslt"
+	"github.com/lestrrat-go/libxml2/xpath"
+	"github.com/lestrrat-go/libxml2/xslt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEXSLTXPath(t *testing.T) {
+	doc, err := libxml2.ParseString(`<orders>
+  <order date="2024-01-31" tags="red,blue"><customer>alice</customer></order>
+  <order date="2024-03-01" tags="green"><customer>bob</customer></order>
+  <order date="2024-03-02" tags=""><customer>alice</customer></order>
+</orders>`)
+	if !assert.NoError(t, err, "parsing document") {
+		return
+	}
+	defer doc.Free()
+
+	root, err := doc.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+
+	ctx, err := xpath.NewContext(root)
+	if !assert.NoError(t, err, "NewContext should succeed") {
+		return
+	}
+	defer ctx.Free()
+
+	if !assert.NoError(t, ctx.RegisterEXSLT(), "RegisterEXSLT should succeed") {
+		return
+	}
+
+	var tokens []string
+	for _, n := range xpath.NodeList(ctx.Find(`str:tokenize(order[1]/@tags, ',')`)) {
+		tokens = append(tokens, n.TextContent())
+	}
+	if !assert.Equal(t, []string{"red", "blue"}, tokens, "str:tokenize works") {
+		return
+	}
+
+	if !assert.True(t, xpath.Bool(ctx.Find(`date:difference(order[1]/@date, order[2]/@date) = 'P30D'`)), "date:difference works") {
+		return
+	}
+
+	if !assert.Len(t, xpath.NodeList(ctx.Find(`set:distinct(order/customer)`)), 2, "set:distinct works") {
+		return
+	}
+
+	if !assert.Equal(t, float64(2), xpath.Number(ctx.Find(`math:max(str:split('1 2', ' '))`)), "modules can be combined") {
+		return
+	}
+
+	if !assert.True(t, xpath.Bool(ctx.Find(`dyn:evaluate('count(order)') = 3 and exsl:object-type(exsl:node-set('a')) = 'node-set'`)), "dynamic and common modules work") {
+		return
+	}
+}
+
+func TestEXSLTStylesheet(t *testing.T) {
+	style, err := xslt.Parse([]byte(`<xsl:stylesheet version="1.0"
+    xmlns:xsl="http://www.w3.org/1999/XSL/Transform"
+    xmlns:exsl="http://exslt.org/common"
+    xmlns:str="http://exslt.org/strings"
+    xmlns:dyn="http://exslt.org/dynamic"
+    extension-element-prefixes="exsl str dyn">
+  <xsl:output method="text"/>
+  <xsl:template match="/">
+    <xsl:variable name="items">
+      <xsl:for-each select="str:tokenize(/list, ' ')">
+        <item><xsl:value-of select="."/></item>
+      </xsl:for-each>
+    </xsl:variable>
+    <xsl:value-of select="count(exsl:node-set($items)/item)"/>
+    <xsl:text>:</xsl:text>
+    <xsl:value-of select="dyn:evaluate(/list/@expr)"/>
+  </xsl:template>
+</xsl:stylesheet>`))
+	if !assert.NoError(t, err, "parsing stylesheet should succeed") {
+		return
+	}
+	defer style.Free()
+
+	doc, err := libxml2.ParseString(`<list expr="1 + 2">a b c</list>`)
+	if !assert.NoError(t, err, "parsing document") {
+		return
+	}
+	defer doc.Free()
+
+	result, err := style.Transform(doc, nil)
+	if !assert.NoError(t, err, "Transform should succeed") {
+		return
+	}
+	defer result.Free()
+
+	out, err := style.Output(result)
+	if !assert.NoError(t, err, "Output should succeed") {
+		return
+	}
+	if !assert.Equal(t, "3:3", string(out), "EXSLT functions are available") {
+		return
+	}
+}
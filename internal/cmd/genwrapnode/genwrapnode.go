@@ -39,6 +39,18 @@ func _main() error {
 		`Element`,
 		`Text`,
 		`Pi`,
+		`DTD`,
+		`ElementDeclaration`,
+		`AttributeDeclaration`,
+		`EntityDeclaration`,
+	}
+
+	// Node types whose clib constant is not simply the type name
+	// followed by "Node"
+	nodeConstants := map[string]string{
+		`ElementDeclaration`:   `ElementDecl`,
+		`AttributeDeclaration`: `AttributeDecl`,
+		`EntityDeclaration`:    `EntityDecl`,
 	}
 
 	for _, typ := range nodeTypes {
@@ -61,12 +73,16 @@ func _main() error {
 		if typ == "Namespace" {
 			continue
 		}
-		fmt.Fprintf(&buf, "\ncase clib.%sNode:", typ)
+		constant, ok := nodeConstants[typ]
+		if !ok {
+			constant = typ + "Node"
+		}
+		fmt.Fprintf(&buf, "\ncase clib.%s:", constant)
 		fmt.Fprintf(&buf, "\nreturn wrap%sNode(n), nil", typ)
 	}
 
 	buf.WriteString("\ndefault:")
-	buf.WriteString("\nreturn nil, fmt.Errorf(\"unknown node: %%d\", typ)")
+	buf.WriteString("\nreturn nil, fmt.Errorf(\"unknown node: %d\", typ)")
 	buf.WriteString("\n}")
 	buf.WriteString("\n}")
 
@@ -0,0 +1,13 @@
+// Package exslt connects the xpath package to the EXSLT support,
+// which lives in its own package so that only its users need to link
+// against libexslt. The hooks are nil unless
+// github.com/lestrrat-go/libxml2/exslt is linked in
+package exslt
+
+// RegisterXPath registers the EXSLT functions with the given
+// xmlXPathContext
+var RegisterXPath func(ctx uintptr) error
+
+// ReleaseXPath releases what RegisterXPath allocated for the given
+// xmlXPathContext, which is about to be freed
+var ReleaseXPath func(ctx uintptr)
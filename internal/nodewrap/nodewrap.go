@@ -0,0 +1,12 @@
+// Package nodewrap connects the xpath package to the wrappers of the
+// node types that dom.WrapNode does not handle, as xpath cannot import
+// dom. The hooks are set when the dom package is initialized
+package nodewrap
+
+import "github.com/lestrrat-go/libxml2/types"
+
+// Document wraps a pointer to a document node
+var Document func(ptr uintptr) types.Node
+
+// Namespace wraps a pointer to a namespace node
+var Namespace func(ptr uintptr) types.Node
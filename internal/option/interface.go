@@ -1,5 +1,15 @@
 package option
 
 const (
-	OptKeyWithURI = `with-uri`
+	OptKeyWithURI           = `with-uri`
+	OptKeyWithoutNamespaces = `without-namespaces`
+	OptKeyWithoutAttributes = `without-attributes`
+
+	OptKeyWithEncoding          = `with-encoding`
+	OptKeyWithParserOptions     = `with-parser-options`
+	OptKeyWithDefaultAttributes = `with-default-attributes`
+	OptKeyWithMaxErrors         = `with-max-errors`
+	OptKeyWithWarningsAsErrors  = `with-warnings-as-errors`
+	OptKeyWithResolver          = `with-resolver`
+	OptKeyWithForbiddenAccess   = `with-forbidden-access`
 )
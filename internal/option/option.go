@@ -1,5 +1,11 @@
 package option
 
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
 type Interface interface {
 	Name() string
 	Value() interface{}
@@ -23,3 +29,19 @@ func (o *Option) Name() string {
 func (o *Option) Value() interface{} {
 	return o.value
 }
+
+// PathURI returns the file URI of path, which is made absolute using
+// the current directory if needed. It is shared by the WithPath
+// options of the parsers
+func PathURI(path string) string {
+	if !filepath.IsAbs(path) {
+		if curdir, err := os.Getwd(); err == nil {
+			path = filepath.Join(curdir, path)
+		}
+	}
+
+	return (&url.URL{
+		Scheme: `file`,
+		Path:   path,
+	}).String()
+}
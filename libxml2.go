@@ -1,4 +1,5 @@
 //go:generate go run internal/cmd/genwrapnode/genwrapnode.go -- dom/node_wrap.go
+//go:generate go run ./cmd/xsdgen -package catalog -o test/xsdgen/catalog/catalog.go test/schema/catalog.xsd
 
 /*
 Package libxml2 is an interface to libxml2 library, providing XML and HTML parsers
@@ -0,0 +1,24 @@
+package relaxng
+
+import (
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/internal/option"
+)
+
+// Schema represents a RELAX NG schema.
+type Schema struct {
+	ptr uintptr // *C.xmlRelaxNG
+}
+
+// SchemaValidationError is returned when the Validate() function
+// finds errors. When there are multiple errors, you may access
+// them using the Errors() method
+type SchemaValidationError struct {
+	errors []error
+}
+
+// Error holds the details of a single validation error. The values
+// returned by SchemaValidationError.Errors() are of this type
+type Error = clib.StructuredError
+
+type Option = option.Interface
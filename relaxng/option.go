@@ -0,0 +1,17 @@
+package relaxng
+
+import "github.com/lestrrat-go/libxml2/internal/option"
+
+// WithPath sets the URI of the schema to the location of the file at
+// path, so that relative references such as `<include href="common.rng"/>`
+// can be resolved when the schema is parsed with Parse(). Relative
+// paths are taken from the current directory.
+func WithPath(path string) Option {
+	return WithURI(option.PathURI(path))
+}
+
+// WithURI sets the URI of the schema, against which the grammars it
+// includes or refers to with externalRef are resolved.
+func WithURI(v string) Option {
+	return option.New(option.OptKeyWithURI, v)
+}
@@ -0,0 +1,83 @@
+// Package relaxng contains the tools available from libxml2 that
+// allow you to validate your XML against a RELAX NG schema. Only
+// the XML syntax is supported; schemas written in the compact
+// syntax must be converted first (e.g. using trang)
+//
+// This is basically all you need to do:
+//
+//	schema, err := relaxng.Parse(rngsrc)
+//	if err != nil {
+//	    panic(err)
+//	}
+//	defer schema.Free()
+//	if err := schema.Validate(doc); err != nil{
+//	    for _, e := range err.(relaxng.SchemaValidationError).Errors() {
+//	         println(e.Error())
+//	    }
+//	}
+package relaxng
+
+import (
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/pkg/errors"
+)
+
+// Parse is used to parse a RELAX NG schema to produce a Schema
+// instance. Make sure to call Free() on the instance when you
+// are done with it.
+func Parse(buf []byte, options ...Option) (*Schema, error) {
+	sptr, err := clib.XMLRelaxNGParse(buf, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse input")
+	}
+
+	return &Schema{ptr: sptr}, nil
+}
+
+// ParseFromFile is used to parse a RELAX NG schema using only the
+// file path. Make sure to call Free() on the instance when you are
+// done with it.
+func ParseFromFile(path string) (*Schema, error) {
+	sptr, err := clib.XMLRelaxNGParseFromFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse input from file")
+	}
+
+	return &Schema{ptr: sptr}, nil
+}
+
+// Pointer returns the underlying C struct
+func (s *Schema) Pointer() uintptr {
+	return s.ptr
+}
+
+// Free frees the underlying C struct
+func (s *Schema) Free() {
+	if err := clib.XMLRelaxNGFree(s); err != nil {
+		return
+	}
+	s.ptr = 0
+}
+
+// Validate takes in a XML document and validates it against
+// the schema. If there are any problems, and error is
+// returned.
+func (s *Schema) Validate(d types.Document) error {
+	errs := clib.XMLRelaxNGValidateDocument(s, d)
+	if errs == nil {
+		return nil
+	}
+
+	return SchemaValidationError{errors: errs}
+}
+
+// Error method fulfils the error interface
+func (sve SchemaValidationError) Error() string {
+	return "schema validation failed"
+}
+
+// Errors returns the list of errors found
+func (sve SchemaValidationError) Errors() []error {
+	return sve.errors
+}
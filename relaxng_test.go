@@ -0,0 +1,105 @@
+package libxml2_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/relaxng"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelaxNG(t *testing.T) {
+	schemafile := filepath.Join("test", "relaxng", "address.rng")
+
+	const validsrc = `<addressBook>
+  <card age="42"><name>John Smith</name><email>js@example.com</email></card>
+</addressBook>`
+	const invalidsrc = `<addressBook>
+  <card age="old"><name>John Smith</name></card>
+</addressBook>`
+
+	validate := func(t *testing.T, s *relaxng.Schema) {
+		func() {
+			d, err := libxml2.ParseString(validsrc)
+			if !assert.NoError(t, err, "parsing XML") {
+				return
+			}
+			defer d.Free()
+
+			if !assert.NoError(t, s.Validate(d), "s.Validate should pass") {
+				return
+			}
+		}()
+
+		func() {
+			d, err := libxml2.ParseString(invalidsrc)
+			if !assert.NoError(t, err, "parsing XML") {
+				return
+			}
+			defer d.Free()
+
+			err = s.Validate(d)
+			if !assert.Error(t, err, "s.Validate should fail") {
+				return
+			}
+
+			serr, ok := err.(relaxng.SchemaValidationError)
+			if !assert.True(t, ok, "error is relaxng.SchemaValidationError") {
+				return
+			}
+			if !assert.NotEmpty(t, serr.Errors(), "errors are reported") {
+				return
+			}
+			for _, e := range serr.Errors() {
+				t.Logf("err (OK): '%s'", e)
+				if !assert.IsType(t, relaxng.Error{}, e, "error is a relaxng.Error") {
+					return
+				}
+			}
+		}()
+	}
+
+	t.Run("ParseFromFile", func(t *testing.T) {
+		s, err := relaxng.ParseFromFile(schemafile)
+		if !assert.NoError(t, err, "relaxng.ParseFromFile should succeed") {
+			return
+		}
+		defer s.Free()
+
+		validate(t, s)
+	})
+	t.Run("Parse with path", func(t *testing.T) {
+		buf, err := os.ReadFile(schemafile)
+		if !assert.NoError(t, err, "reading schema") {
+			return
+		}
+
+		s, err := relaxng.Parse(buf, relaxng.WithPath(schemafile))
+		if !assert.NoError(t, err, "relaxng.Parse should succeed") {
+			return
+		}
+		defer s.Free()
+
+		validate(t, s)
+	})
+	t.Run("Parse without path", func(t *testing.T) {
+		buf, err := os.ReadFile(schemafile)
+		if !assert.NoError(t, err, "reading schema") {
+			return
+		}
+
+		_, err = relaxng.Parse(buf)
+		if !assert.Error(t, err, "relaxng.Parse should fail to resolve the include") {
+			return
+		}
+	})
+	t.Run("Parse errors", func(t *testing.T) {
+		_, err := relaxng.Parse([]byte(`<element xmlns="http://relaxng.org/ns/structure/1.0" name="foo"><bogus/></element>`))
+		if !assert.Error(t, err, "relaxng.Parse should fail") {
+			return
+		}
+		t.Logf("err (OK): '%s'", err)
+	})
+}
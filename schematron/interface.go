@@ -0,0 +1,65 @@
+package schematron
+
+import (
+	"github.com/lestrrat-go/libxml2/internal/option"
+	"github.com/lestrrat-go/libxml2/types"
+)
+
+// Schema represents a Schematron schema.
+type Schema struct {
+	ptr   uintptr        // *C.xmlSchematron
+	doc   schemaDocument // the schema document, referenced by ptr
+	tests []Test
+}
+
+// schemaDocument is the document the schema was parsed from. It is
+// owned by the schema and never handed out, so that only Free()
+// frees it
+type schemaDocument uintptr
+
+func (d schemaDocument) Pointer() uintptr {
+	return uintptr(d)
+}
+
+// Test describes an assert or a report in the schema
+type Test struct {
+	// Report is true for <report> elements, and false for <assert>
+	Report bool
+	// Pattern is the id of the enclosing pattern, or its name if it
+	// has no id
+	Pattern string
+	// Context is the context expression of the enclosing rule
+	Context string
+	// Test is the XPath expression being tested
+	Test string
+	// ID is the value of the id attribute, if any
+	ID string
+	// Role is the value of the role attribute, if any
+	Role string
+}
+
+// Result describes an assert that failed, or a report that fired,
+// while validating a document. It implements the error interface
+type Result struct {
+	Test
+	// Message is the text of the assert or report, with the name and
+	// value-of elements evaluated against the node
+	Message string
+	// Path is an XPath expression locating the node being tested
+	Path string
+	// Line is the line number of the node being tested, or 0
+	Line int
+	// Node is the node being tested
+	Node types.Node
+}
+
+// SchemaValidationError is returned when the Validate() function
+// finds errors. The failed asserts and fired reports are available
+// from the Results() method, and all problems (including the results)
+// from the Errors() method
+type SchemaValidationError struct {
+	results []Result
+	errors  []error
+}
+
+type Option = option.Interface
@@ -0,0 +1,17 @@
+package schematron
+
+import "github.com/lestrrat-go/libxml2/internal/option"
+
+// WithPath sets the URI of the schema to the location of the file at
+// path, so that `<include href="rules.sch"/>` and other relative
+// references can be resolved when the schema is parsed with Parse().
+// Relative paths are taken from the current directory.
+func WithPath(path string) Option {
+	return WithURI(option.PathURI(path))
+}
+
+// WithURI sets the URI of the schema, against which the files it
+// includes are resolved.
+func WithURI(v string) Option {
+	return option.New(option.OptKeyWithURI, v)
+}
@@ -0,0 +1,143 @@
+// Package schematron contains the tools available from libxml2 that
+// allow you to validate your XML against a Schematron schema. Both
+// the ISO namespace (http://purl.oclc.org/dsdl/schematron) and the
+// older Schematron 1.5 namespace are accepted.
+//
+// Unlike the other validators, each failed assert and fired report is
+// returned as a Result, which describes the rule that triggered it:
+//
+//	schema, err := schematron.Parse(schsrc)
+//	if err != nil {
+//	    panic(err)
+//	}
+//	defer schema.Free()
+//	if err := schema.Validate(doc); err != nil{
+//	    for _, r := range err.(schematron.SchemaValidationError).Results() {
+//	         println(r.Path, r.Test.Test, r.Message)
+//	    }
+//	}
+package schematron
+
+import (
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/dom"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/pkg/errors"
+)
+
+func newSchema(sptr, dptr uintptr, tests []clib.SchematronTest) *Schema {
+	s := &Schema{
+		ptr:   sptr,
+		doc:   schemaDocument(dptr),
+		tests: make([]Test, len(tests)),
+	}
+	for i, t := range tests {
+		s.tests[i] = Test(t)
+	}
+	return s
+}
+
+// Parse is used to parse a Schematron schema to produce a Schema
+// instance. Make sure to call Free() on the instance when you
+// are done with it.
+func Parse(buf []byte, options ...Option) (*Schema, error) {
+	sptr, dptr, tests, err := clib.XMLSchematronParse(buf, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse input")
+	}
+
+	return newSchema(sptr, dptr, tests), nil
+}
+
+// ParseFromFile is used to parse a Schematron schema using only the
+// file path. Make sure to call Free() on the instance when you are
+// done with it.
+func ParseFromFile(path string) (*Schema, error) {
+	sptr, dptr, tests, err := clib.XMLSchematronParseFromFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse input from file")
+	}
+
+	return newSchema(sptr, dptr, tests), nil
+}
+
+// Pointer returns the underlying C struct
+func (s *Schema) Pointer() uintptr {
+	return s.ptr
+}
+
+// Free frees the underlying C struct
+func (s *Schema) Free() {
+	_ = clib.XMLSchematronFree(s, s.doc)
+	s.ptr = 0
+	s.doc = 0
+}
+
+// Tests returns the asserts and reports declared in the schema, in
+// document order. Those pulled in by <include> are not listed
+func (s *Schema) Tests() []Test {
+	return s.tests
+}
+
+// Validate takes in a XML document and validates it against
+// the schema. If any assert fails or any report fires, a
+// SchemaValidationError is returned.
+func (s *Schema) Validate(d types.Document) error {
+	raw, errs := clib.XMLSchematronValidateDocument(s, d)
+	if raw == nil && errs == nil {
+		return nil
+	}
+
+	var sve SchemaValidationError
+	for _, r := range raw {
+		result := Result{
+			Message: r.Message,
+			Path:    r.Path,
+			Line:    r.Line,
+		}
+		if r.Test >= 0 && r.Test < len(s.tests) {
+			result.Test = s.tests[r.Test]
+		}
+		if r.Node != 0 {
+			if n, err := dom.WrapNode(r.Node); err == nil {
+				result.Node = n
+			}
+		}
+		sve.results = append(sve.results, result)
+		sve.errors = append(sve.errors, result)
+	}
+	sve.errors = append(sve.errors, errs...)
+	return sve
+}
+
+// Error method fulfils the error interface
+func (r Result) Error() string {
+	kind := "assert"
+	if r.Report {
+		kind = "report"
+	}
+
+	msg := r.Message
+	if msg == "" {
+		msg = "node failed " + kind
+	}
+	if r.Path == "" {
+		return msg
+	}
+	return r.Path + ": " + msg
+}
+
+// Error method fulfils the error interface
+func (sve SchemaValidationError) Error() string {
+	return "schema validation failed"
+}
+
+// Results returns the failed asserts and fired reports
+func (sve SchemaValidationError) Results() []Result {
+	return sve.results
+}
+
+// Errors returns the list of errors found
+func (sve SchemaValidationError) Errors() []error {
+	return sve.errors
+}
@@ -0,0 +1,148 @@
+package libxml2_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/schematron"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchematron(t *testing.T) {
+	schemafile := filepath.Join("test", "schematron", "order.sch")
+
+	const validsrc = `<order id="o1"><item qty="2"/><item qty="5"/></order>`
+	const invalidsrc = `<order id="o2">
+  <item qty="0"/>
+  <item qty="500"/>
+</order>`
+
+	validate := func(t *testing.T, s *schematron.Schema) {
+		if !assert.Len(t, s.Tests(), 4, "asserts and reports are listed") {
+			return
+		}
+
+		func() {
+			d, err := libxml2.ParseString(validsrc)
+			if !assert.NoError(t, err, "parsing XML") {
+				return
+			}
+			defer d.Free()
+
+			if !assert.NoError(t, s.Validate(d), "s.Validate should pass") {
+				return
+			}
+		}()
+
+		func() {
+			d, err := libxml2.ParseString(invalidsrc)
+			if !assert.NoError(t, err, "parsing XML") {
+				return
+			}
+			defer d.Free()
+
+			err = s.Validate(d)
+			if !assert.Error(t, err, "s.Validate should fail") {
+				return
+			}
+
+			serr, ok := err.(schematron.SchemaValidationError)
+			if !assert.True(t, ok, "error is schematron.SchemaValidationError") {
+				return
+			}
+			results := serr.Results()
+			if !assert.Len(t, results, 2, "one assert fails and one report fires") {
+				return
+			}
+			for _, r := range results {
+				t.Logf("result (OK): '%s'", r)
+			}
+
+			r := results[0]
+			if !assert.False(t, r.Report, "first result is an assert") {
+				return
+			}
+			if !assert.Equal(t, "totals", r.Pattern, "pattern matches") {
+				return
+			}
+			if !assert.Equal(t, "item", r.Context, "rule context matches") {
+				return
+			}
+			if !assert.Equal(t, "number(@qty) > 0", r.Test.Test, "test expression matches") {
+				return
+			}
+			if !assert.Equal(t, "error", r.Role, "role matches") {
+				return
+			}
+			if !assert.Equal(t, "Quantity of item must be positive", r.Message, "message is evaluated") {
+				return
+			}
+			if !assert.Equal(t, "/order/item[1]", r.Path, "path matches") {
+				return
+			}
+			if !assert.Equal(t, 2, r.Line, "line matches") {
+				return
+			}
+			if !assert.NotNil(t, r.Node, "node is reported") {
+				return
+			}
+			if !assert.Equal(t, "item", r.Node.NodeName(), "node matches") {
+				return
+			}
+
+			r = results[1]
+			if !assert.True(t, r.Report, "second result is a report") {
+				return
+			}
+			if !assert.Equal(t, "Large quantity", r.Message, "message matches") {
+				return
+			}
+			if !assert.Equal(t, "/order/item[2]", r.Path, "path matches") {
+				return
+			}
+		}()
+	}
+
+	t.Run("ParseFromFile", func(t *testing.T) {
+		s, err := schematron.ParseFromFile(schemafile)
+		if !assert.NoError(t, err, "schematron.ParseFromFile should succeed") {
+			return
+		}
+		defer s.Free()
+
+		validate(t, s)
+	})
+	t.Run("Parse", func(t *testing.T) {
+		buf, err := os.ReadFile(schemafile)
+		if !assert.NoError(t, err, "reading schema") {
+			return
+		}
+
+		s, err := schematron.Parse(buf, schematron.WithPath(schemafile))
+		if !assert.NoError(t, err, "schematron.Parse should succeed") {
+			return
+		}
+		defer s.Free()
+
+		validate(t, s)
+
+		s.Free()
+		if !assert.Zero(t, s.Pointer(), "Free clears the schema") {
+			return
+		}
+	})
+	t.Run("Parse errors", func(t *testing.T) {
+		for _, src := range []string{
+			`<schema xmlns="http://www.w3.org/2001/XMLSchema"/>`,
+			`<schema xmlns="http://purl.oclc.org/dsdl/schematron"/>`,
+		} {
+			_, err := schematron.Parse([]byte(src))
+			if !assert.Error(t, err, "schematron.Parse should fail") {
+				return
+			}
+			t.Logf("err (OK): '%s'", err)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+// Code generated by xsdgen from catalog.xsd. DO NOT EDIT.
+
+package catalog
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sync"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/xsd"
+)
+
+var (
+	schemasMu sync.RWMutex
+	schemas   = make(map[string]*xsd.Schema)
+)
+
+// SetSchema registers the schema used by the Validate methods of the
+// elements in its target namespace. The schema must not be freed
+// while it is registered
+func SetSchema(s *xsd.Schema) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	schemas[s.TargetNamespace()] = s
+}
+
+func validate(namespace string, v interface{}) error {
+	schemasMu.RLock()
+	s := schemas[namespace]
+	schemasMu.RUnlock()
+	if s == nil {
+		return fmt.Errorf("no schema registered for namespace %q", namespace)
+	}
+
+	buf, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	doc, err := libxml2.Parse(buf)
+	if err != nil {
+		return err
+	}
+	defer doc.Free()
+	return s.Validate(doc)
+}
+
+// Catalog is generated from element "catalog"
+//
+// A list of products.
+type Catalog struct {
+	XMLName xml.Name  `xml:"urn:example:catalog catalog"`
+	Product []Product `xml:"urn:example:catalog product,omitempty"`
+	Version *string   `xml:"version,attr,omitempty"`
+}
+
+// Validate validates v against the schema registered with SetSchema
+func (v *Catalog) Validate() error {
+	return validate("urn:example:catalog", v)
+}
+
+// ColorType is generated from simple type "ColorType"
+//
+// Available colors.
+type ColorType string
+
+const (
+	ColorTypeRed   ColorType = "red"
+	ColorTypeGreen ColorType = "green"
+	ColorTypeBlue  ColorType = "blue"
+)
+
+// PriceType is generated from complex type "PriceType"
+type PriceType struct {
+	Value    float64 `xml:",chardata"`
+	Currency string  `xml:"currency,attr"`
+}
+
+// Product is generated from element "product"
+type Product struct {
+	XMLName xml.Name `xml:"urn:example:catalog product"`
+	ProductType
+}
+
+// Validate validates v against the schema registered with SetSchema
+func (v *Product) Validate() error {
+	return validate("urn:example:catalog", v)
+}
+
+// ProductType is generated from complex type "ProductType"
+//
+// A product for sale.
+type ProductType struct {
+	Name   string     `xml:"urn:example:catalog name"`
+	Price  PriceType  `xml:"urn:example:catalog price"`
+	Color  *ColorType `xml:"urn:example:catalog color,omitempty"`
+	Size   *SizeType  `xml:"urn:example:catalog size,omitempty"`
+	Sku    SkuType    `xml:"sku,attr"`
+	Status *string    `xml:"status,attr,omitempty"`
+}
+
+// SizeType is generated from simple type "SizeType"
+type SizeType int64
+
+// SkuType is generated from simple type "SkuType"
+type SkuType string
@@ -0,0 +1,20 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<xsl:stylesheet version="1.0"
+                xmlns:xsl="http://www.w3.org/1999/XSL/Transform"
+                xmlns:c="urn:example:catalog">
+  <xsl:import href="common.xsl"/>
+  <xsl:output method="xml" encoding="ISO-8859-1" indent="yes"/>
+  <xsl:param name="title" select="'Catalog'"/>
+
+  <xsl:template match="/c:catalog">
+    <products title="{$title}">
+      <xsl:for-each select="c:product">
+        <product sku="{@sku}">
+          <xsl:call-template name="price">
+            <xsl:with-param name="value" select="c:price"/>
+          </xsl:call-template>
+        </product>
+      </xsl:for-each>
+    </products>
+  </xsl:template>
+</xsl:stylesheet>
@@ -0,0 +1,7 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<xsl:stylesheet version="1.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform">
+  <xsl:template name="price">
+    <xsl:param name="value"/>
+    <xsl:value-of select="format-number($value, '0.00')"/>
+  </xsl:template>
+</xsl:stylesheet>
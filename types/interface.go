@@ -1,6 +1,10 @@
 package types
 
-import "github.com/lestrrat-go/libxml2/clib"
+import (
+	"iter"
+
+	"github.com/lestrrat-go/libxml2/clib"
+)
 
 // PtrSource defines the interface for things that is backed by
 // a C backend
@@ -15,6 +19,14 @@ type PtrSource interface {
 	Free()
 }
 
+// CloneOption defines the interface for options that change what
+// Node.CloneNode copies. See dom.WithoutNamespaces and
+// dom.WithoutAttributes for the options that are recognized.
+type CloneOption interface {
+	Name() string
+	Value() interface{}
+}
+
 // XPathExpression defines the interface for XPath expression
 type XPathExpression interface {
 	PtrSource
@@ -34,11 +46,21 @@ type XPathResult interface {
 // Document defines the interface for XML document
 type Document interface {
 	Node
+	ChildElements() (NodeList, error)
 	CreateElement(string) (Element, error)
 	CreateElementNS(string, string) (Element, error)
 	DocumentElement() (Node, error)
 	Dump(bool) string
 	Encoding() string
+	FirstChildElement() (Element, error)
+	GetElementByID(string) (Element, error)
+	GetElementsByTagName(string) (NodeList, error)
+	GetElementsByTagNameNS(string, string) (NodeList, error)
+	NormalizeWhitespace() error
+	RemoveUnusedNamespaceDeclarations() error
+	RenameNamespace(string, string) error
+	StripBlankText() error
+	StripNamespaces() error
 }
 
 // Attribute defines the interface for XML attribute
@@ -53,15 +75,31 @@ type Attribute interface {
 type Element interface {
 	Node
 	AppendText(string) error
+	AttributeIter() iter.Seq[Attribute]
 	Attributes() ([]Attribute, error)
+	ChildElements() (NodeList, error)
+	DeclareNamespace(string, string) error
+	FirstChildElement() (Element, error)
 	GetAttribute(string) (Attribute, error)
+	GetChildrenByTagName(string) (NodeList, error)
+	GetElementsByTagName(string) (NodeList, error)
+	GetElementsByTagNameNS(string, string) (NodeList, error)
 	GetNamespaces() ([]Namespace, error)
 	LocalName() string
 	NamespaceURI() string
+	NextElementSibling() (Element, error)
+	NormalizeWhitespace() error
 	Prefix() string
 	RemoveAttribute(string) error
+	RemoveUnusedNamespaceDeclarations() error
+	RenameNamespace(string, string) error
 	SetAttribute(string, string) error
+	SetBase(string) error
+	SetLang(string) error
 	SetNamespace(string, string, ...bool) error
+	SetTextContent(string) error
+	StripBlankText() error
+	StripNamespaces() error
 }
 
 // Namespace defines the interface for XML namespace
@@ -80,14 +118,25 @@ type Node interface {
 	ParseInContext(string, int) (Node, error)
 
 	AddChild(Node) error
+	Ancestors() iter.Seq[Node]
+	BaseURI() string
 	ChildNodes() (NodeList, error)
+	Children() iter.Seq[Node]
+	CloneNode(bool, ...CloneOption) (Node, error)
+	CompareDocumentPosition(Node) clib.DocumentPosition
 	Copy() (Node, error)
+	Descendants() iter.Seq[Node]
 	OwnerDocument() (Document, error)
 	Find(string) (XPathResult, error)
 	FirstChild() (Node, error)
+	FollowingSiblings() iter.Seq[Node]
 	HasChildNodes() bool
+	Hash() uint64
+	IsEqualNode(Node) bool
 	IsSameNode(Node) bool
+	Lang() string
 	LastChild() (Node, error)
+	Line() int
 	// Literal is almost the same as String(), except for things like Element
 	// and Attribute nodes. String() will return the XML stringification of
 	// these, but Literal() will return the "value" associated with them.
@@ -98,12 +147,17 @@ type Node interface {
 	NodeName() string
 	NodeType() clib.XMLNodeType
 	NodeValue() string
+	Normalize() error
 	ParentNode() (Node, error)
+	Path() string
+	PrecedingSiblings() iter.Seq[Node]
 	PreviousSibling() (Node, error)
+	ReconcileNamespaces() error
 	RemoveChild(Node) error
 	SetDocument(d Document) error
 	SetNodeName(string)
 	SetNodeValue(string)
+	SpacePreserved() bool
 	String() string
 	TextContent() string
 	ToString(int, bool) string
@@ -42,3 +42,23 @@ type Expression struct {
 
 // Result is an alias to types.XPathResult
 type Result types.XPathResult
+
+// Value is a value passed to or returned by a Function: a node set,
+// a boolean, a number or a string
+type Value struct {
+	typ    clib.XPathObjectType
+	nodes  types.NodeList
+	bool   bool
+	number float64
+	str    string
+}
+
+// Function is a Go function that can be called from XPath expressions.
+// See Context.RegisterFunc
+type Function func(args []Value) (Value, error)
+
+// VariableResolver is a Go function that resolves the variables
+// referenced by XPath expressions, by namespace URI and name. It
+// returns false if the variable is not defined.
+// See Context.SetVariableResolver
+type VariableResolver func(ns, name string) (Value, bool)
@@ -0,0 +1,242 @@
+package xpath
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/internal/nodewrap"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/pkg/errors"
+)
+
+// NodeSetValue creates a Value holding a node set
+func NodeSetValue(nodes types.NodeList) Value {
+	return Value{typ: NodeSetType, nodes: nodes}
+}
+
+// BoolValue creates a Value holding a boolean
+func BoolValue(b bool) Value {
+	return Value{typ: BooleanType, bool: b}
+}
+
+// NumberValue creates a Value holding a number
+func NumberValue(f float64) Value {
+	return Value{typ: NumberType, number: f}
+}
+
+// StringValue creates a Value holding a string
+func StringValue(s string) Value {
+	return Value{typ: StringType, str: s}
+}
+
+// ValueOf converts v to a Value. v may be a Value, a string, a bool,
+// any integer or floating point number, a types.Node or a
+// types.NodeList
+func ValueOf(v interface{}) (Value, error) {
+	switch v := v.(type) {
+	case Value:
+		return v, nil
+	case string:
+		return StringValue(v), nil
+	case bool:
+		return BoolValue(v), nil
+	case float64:
+		return NumberValue(v), nil
+	case float32:
+		return NumberValue(float64(v)), nil
+	case int:
+		return NumberValue(float64(v)), nil
+	case int8:
+		return NumberValue(float64(v)), nil
+	case int16:
+		return NumberValue(float64(v)), nil
+	case int32:
+		return NumberValue(float64(v)), nil
+	case int64:
+		return NumberValue(float64(v)), nil
+	case uint:
+		return NumberValue(float64(v)), nil
+	case uint8:
+		return NumberValue(float64(v)), nil
+	case uint16:
+		return NumberValue(float64(v)), nil
+	case uint32:
+		return NumberValue(float64(v)), nil
+	case uint64:
+		return NumberValue(float64(v)), nil
+	case types.NodeList:
+		return NodeSetValue(v), nil
+	case types.Node:
+		return NodeSetValue(types.NodeList{v}), nil
+	default:
+		return Value{}, errors.Errorf("unsupported value type %T", v)
+	}
+}
+
+// Type returns the type of the value: NodeSetType, BooleanType,
+// NumberType or StringType
+func (v Value) Type() clib.XPathObjectType {
+	return v.typ
+}
+
+// NodeList returns the nodes of a node set, or nil if the value is
+// not a node set
+func (v Value) NodeList() types.NodeList {
+	return v.nodes
+}
+
+// Bool returns the value converted to a boolean, as the XPath
+// boolean() function does
+func (v Value) Bool() bool {
+	switch v.typ {
+	case NodeSetType:
+		return len(v.nodes) > 0
+	case BooleanType:
+		return v.bool
+	case NumberType:
+		return v.number != 0 && !math.IsNaN(v.number)
+	case StringType:
+		return v.str != ""
+	default:
+		return false
+	}
+}
+
+// Number returns the value converted to a number, as the XPath
+// number() function does
+func (v Value) Number() float64 {
+	switch v.typ {
+	case BooleanType:
+		if v.bool {
+			return 1
+		}
+		return 0
+	case NumberType:
+		return v.number
+	case NodeSetType, StringType:
+		return parseNumber(v.String())
+	default:
+		return math.NaN()
+	}
+}
+
+// String returns the value converted to a string, as the XPath
+// string() function does. For node sets, this is the string value
+// of the first node
+func (v Value) String() string {
+	switch v.typ {
+	case NodeSetType:
+		if len(v.nodes) == 0 {
+			return ""
+		}
+		return v.nodes[0].TextContent()
+	case BooleanType:
+		if v.bool {
+			return "true"
+		}
+		return "false"
+	case NumberType:
+		return formatNumber(v.number)
+	case StringType:
+		return v.str
+	default:
+		return ""
+	}
+}
+
+// parseNumber parses s as an XPath number, which may only contain
+// digits, a decimal point and a leading minus sign
+func parseNumber(s string) float64 {
+	s = strings.TrimSpace(s)
+	digits := strings.TrimPrefix(s, "-")
+	if digits == "" || digits == "." || strings.Trim(digits, "0123456789.") != "" || strings.Count(digits, ".") > 1 {
+		return math.NaN()
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return f
+}
+
+func formatNumber(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == 0:
+		// Includes negative zero
+		return "0"
+	default:
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+}
+
+// wrapArgNode wraps a node passed to a Go function. Unlike
+// WrapNodeFunc, it also handles document and namespace nodes, which
+// may appear in the node sets passed as arguments
+func wrapArgNode(p uintptr) (types.Node, error) {
+	switch clib.XMLGetNodeTypeRaw(p) {
+	case clib.DocumentNode, clib.HTMLDocumentNode:
+		if nodewrap.Document != nil {
+			return nodewrap.Document(p), nil
+		}
+	case clib.NamespaceDecl:
+		if nodewrap.Namespace != nil {
+			return nodewrap.Namespace(p), nil
+		}
+	}
+
+	if WrapNodeFunc == nil {
+		return nil, errors.New("xpath: WrapNodeFunc not initialized")
+	}
+	return WrapNodeFunc(p)
+}
+
+func wrapValue(v clib.XPathValue) (Value, error) {
+	switch v.Type {
+	case NodeSetType:
+		nodes := make(types.NodeList, len(v.Nodes))
+		for i, p := range v.Nodes {
+			n, err := wrapArgNode(p)
+			if err != nil {
+				return Value{}, errors.Wrap(err, "failed to wrap node")
+			}
+			nodes[i] = n
+		}
+		return NodeSetValue(nodes), nil
+	case BooleanType:
+		return BoolValue(v.Bool), nil
+	case NumberType:
+		return NumberValue(v.Number), nil
+	default:
+		return StringValue(v.String), nil
+	}
+}
+
+func unwrapValue(v Value) clib.XPathValue {
+	switch v.typ {
+	case NodeSetType:
+		nodes := make([]uintptr, 0, len(v.nodes))
+		for _, n := range v.nodes {
+			if n != nil {
+				nodes = append(nodes, n.Pointer())
+			}
+		}
+		return clib.XPathValue{Type: NodeSetType, Nodes: nodes}
+	case BooleanType:
+		return clib.XPathValue{Type: BooleanType, Bool: v.bool}
+	case NumberType:
+		return clib.XPathValue{Type: NumberType, Number: v.number}
+	case StringType:
+		return clib.XPathValue{Type: StringType, String: v.str}
+	default:
+		// The zero Value is an empty node set
+		return clib.XPathValue{Type: NodeSetType}
+	}
+}
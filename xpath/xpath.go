@@ -24,6 +24,7 @@ import (
 	"fmt"
 
 	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/internal/exslt"
 	"github.com/lestrrat-go/libxml2/types"
 	"github.com/pkg/errors"
 )
@@ -190,6 +191,9 @@ func (x *Context) Exists(xpath string) bool {
 
 // Free releases the underlying C structs in the XPath
 func (x *Context) Free() {
+	if exslt.ReleaseXPath != nil {
+		exslt.ReleaseXPath(x.ptr)
+	}
 	_ = clib.XMLXPathFreeContext(x)
 }
 
@@ -232,3 +236,105 @@ func (x *Context) LookupNamespaceURI(prefix string) (string, error) {
 func (x *Context) RegisterNS(name, nsuri string) error {
 	return clib.XMLXPathRegisterNS(x, name, nsuri)
 }
+
+// RegisterFunc registers a Go function that can be called from XPath
+// expressions evaluated against the context, as name in the namespace
+// ns. The namespace must be bound to a prefix using RegisterNS() to
+// call the function, unless ns is empty. The function is responsible
+// for checking the number and types of its arguments.
+//
+// Node-set arguments may contain document and namespace nodes as
+// well. Namespace nodes are copies that are only valid during the
+// call.
+//
+// If fn returns an error, the evaluation is aborted and Find()
+// returns that error. Passing a nil fn unregisters the function.
+func (x *Context) RegisterFunc(ns, name string, fn Function) error {
+	if fn == nil {
+		return clib.XMLXPathRegisterFunc(x, ns, name, nil)
+	}
+
+	return clib.XMLXPathRegisterFunc(x, ns, name, func(args []clib.XPathValue) (clib.XPathValue, error) {
+		values := make([]Value, len(args))
+		for i, arg := range args {
+			v, err := wrapValue(arg)
+			if err != nil {
+				return clib.XPathValue{}, err
+			}
+			values[i] = v
+		}
+
+		res, err := fn(values)
+		if err != nil {
+			return clib.XPathValue{}, err
+		}
+		return unwrapValue(res), nil
+	})
+}
+
+// RegisterVariable registers a variable that can be referenced as
+// $name from XPath expressions evaluated against the context. value
+// may be anything accepted by ValueOf(). Passing a nil value
+// unregisters the variable.
+//
+// Values are not evaluated, so variables are a safe way to pass
+// user input to expressions. Compiled expressions see the values
+// registered at the time they are evaluated, so they can be reused:
+//
+//	expr, _ := xpath.NewExpression(`//user[@id = $id]`)
+//	ctx.RegisterVariable("id", "1")
+//	res, _ := ctx.FindExpr(expr)
+//
+// Nodes must not be freed while they are held by a variable.
+func (x *Context) RegisterVariable(name string, value interface{}) error {
+	return x.RegisterVariableNS("", name, value)
+}
+
+// RegisterVariableNS registers a variable in the namespace ns, which
+// must be bound to a prefix using RegisterNS() to reference the
+// variable. See RegisterVariable().
+func (x *Context) RegisterVariableNS(ns, name string, value interface{}) error {
+	if value == nil {
+		return clib.XMLXPathRegisterVariable(x, ns, name, nil)
+	}
+
+	v, err := ValueOf(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to register variable %s", name)
+	}
+	cv := unwrapValue(v)
+	return clib.XMLXPathRegisterVariable(x, ns, name, &cv)
+}
+
+// SetVariableResolver sets a function that resolves the variables
+// which were not registered using RegisterVariable(). It is called
+// every time an expression references such a variable. Passing a nil
+// fn unsets the resolver.
+func (x *Context) SetVariableResolver(fn VariableResolver) error {
+	if fn == nil {
+		return clib.XMLXPathRegisterVariableLookup(x, nil)
+	}
+
+	return clib.XMLXPathRegisterVariableLookup(x, func(ns, name string) (clib.XPathValue, bool) {
+		v, ok := fn(ns, name)
+		if !ok {
+			return clib.XPathValue{}, false
+		}
+		return unwrapValue(v), true
+	})
+}
+
+// RegisterEXSLT registers the functions of the EXSLT common, math,
+// sets, strings, dates and times and dynamic modules, bound to the
+// "exsl", "math", "set", "str", "date" and "dyn" prefixes
+// respectively. The node-sets returned by some of them, such as
+// str:tokenize(), are only valid until the context is freed.
+//
+// This requires the github.com/lestrrat-go/libxml2/exslt package to
+// be imported, which links against libexslt.
+func (x *Context) RegisterEXSLT() error {
+	if exslt.RegisterXPath == nil {
+		return errors.New("EXSLT support is not linked in: import github.com/lestrrat-go/libxml2/exslt")
+	}
+	return exslt.RegisterXPath(x.ptr)
+}
@@ -1,9 +1,14 @@
 package xpath_test
 
 import (
+	"errors"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/types"
 	"github.com/lestrrat-go/libxml2/xpath"
 	"github.com/stretchr/testify/assert"
 )
@@ -186,3 +191,357 @@ func TestXPathContextExpression_Namespaces(t *testing.T) {
 		return
 	}
 }
+
+func TestXPathContextRegisterEXSLT_NotLinked(t *testing.T) {
+	ctx, err := xpath.NewContext()
+	if !assert.NoError(t, err, "NewContext should succeed") {
+		return
+	}
+	defer ctx.Free()
+
+	// The exslt package is not imported by these tests
+	if !assert.Error(t, ctx.RegisterEXSLT(), "RegisterEXSLT should fail") {
+		return
+	}
+}
+
+func TestXPathContextRegisterFunc(t *testing.T) {
+	doc, err := libxml2.ParseString(`<users><user id="1" role="ADMIN">alice</user><user id="2" role="guest">bob</user><user id="3" role="Admin">carol</user></users>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	root, err := doc.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+
+	ctx, err := xpath.NewContext(root)
+	if !assert.NoError(t, err, "NewContext should succeed") {
+		return
+	}
+	defer ctx.Free()
+
+	const nsuri = `urn:example:functions`
+	if !assert.NoError(t, ctx.RegisterNS("fn", nsuri), "RegisterNS should succeed") {
+		return
+	}
+
+	err = ctx.RegisterFunc(nsuri, "lower-case", func(args []xpath.Value) (xpath.Value, error) {
+		if len(args) != 1 {
+			return xpath.Value{}, errors.New("lower-case expects 1 argument")
+		}
+		return xpath.StringValue(strings.ToLower(args[0].String())), nil
+	})
+	if !assert.NoError(t, err, "RegisterFunc should succeed") {
+		return
+	}
+
+	err = ctx.RegisterFunc(nsuri, "is-admin", func(args []xpath.Value) (xpath.Value, error) {
+		return xpath.BoolValue(strings.EqualFold(args[0].String(), "admin")), nil
+	})
+	if !assert.NoError(t, err, "RegisterFunc should succeed") {
+		return
+	}
+
+	// Returns the nodes of the first argument whose text is in the
+	// second argument, a space separated list
+	err = ctx.RegisterFunc(nsuri, "named", func(args []xpath.Value) (xpath.Value, error) {
+		if len(args) != 2 || args[0].Type() != xpath.NodeSetType {
+			return xpath.Value{}, errors.New("named expects a node set and a string")
+		}
+		names := strings.Fields(args[1].String())
+		var nodes types.NodeList
+		for _, n := range args[0].NodeList() {
+			for _, name := range names {
+				if n.TextContent() == name {
+					nodes = append(nodes, n)
+				}
+			}
+		}
+		return xpath.NodeSetValue(nodes), nil
+	})
+	if !assert.NoError(t, err, "RegisterFunc should succeed") {
+		return
+	}
+
+	err = ctx.RegisterFunc("", "fail", func(args []xpath.Value) (xpath.Value, error) {
+		return xpath.Value{}, errors.New("failed on purpose")
+	})
+	if !assert.NoError(t, err, "RegisterFunc should succeed") {
+		return
+	}
+
+	if !assert.Equal(t, 2.0, xpath.Number(ctx.Find(`count(user[fn:lower-case(@role) = 'admin'])`)), "string function") {
+		return
+	}
+
+	nodes := xpath.NodeList(ctx.Find(`user[fn:is-admin(@role)]/@id`))
+	if !assert.Len(t, nodes, 2, "boolean function in a predicate") {
+		return
+	}
+	if !assert.Equal(t, "1", nodes[0].NodeValue(), "first admin matches") {
+		return
+	}
+
+	nodes = xpath.NodeList(ctx.Find(`fn:named(user, 'carol bob')/@id`))
+	if !assert.Len(t, nodes, 2, "node set function") {
+		return
+	}
+	if !assert.Equal(t, "2", nodes[0].NodeValue(), "nodes are in document order") {
+		return
+	}
+
+	_, err = ctx.Find(`user[fail()]`)
+	if !assert.Error(t, err, "Find should fail") {
+		return
+	}
+	if !assert.Contains(t, err.Error(), "failed on purpose", "error is propagated") {
+		return
+	}
+
+	// The context is still usable after an error
+	if !assert.True(t, xpath.Bool(ctx.Find(`fn:is-admin('Admin')`)), "function works after an error") {
+		return
+	}
+
+	if !assert.NoError(t, ctx.RegisterFunc(nsuri, "is-admin", nil), "unregistering should succeed") {
+		return
+	}
+	_, err = ctx.Find(`fn:is-admin('Admin')`)
+	if !assert.Error(t, err, "unregistered function should not be found") {
+		return
+	}
+}
+
+func TestXPathValue(t *testing.T) {
+	if !assert.Equal(t, "3", xpath.NumberValue(3).String(), "integer number to string") {
+		return
+	}
+	if !assert.Equal(t, "0.5", xpath.NumberValue(0.5).String(), "number to string") {
+		return
+	}
+	if !assert.Equal(t, "NaN", xpath.NumberValue(math.NaN()).String(), "NaN to string") {
+		return
+	}
+	if !assert.Equal(t, "-Infinity", xpath.NumberValue(math.Inf(-1)).String(), "infinity to string") {
+		return
+	}
+	if !assert.Equal(t, 12.5, xpath.StringValue(" 12.5 ").Number(), "string to number") {
+		return
+	}
+	if !assert.True(t, math.IsNaN(xpath.StringValue("1e3").Number()), "exponents are not numbers") {
+		return
+	}
+	if !assert.True(t, xpath.StringValue("false").Bool(), "non empty string is true") {
+		return
+	}
+	if !assert.False(t, xpath.NumberValue(math.NaN()).Bool(), "NaN is false") {
+		return
+	}
+	if !assert.Equal(t, 1.0, xpath.BoolValue(true).Number(), "true to number") {
+		return
+	}
+	if !assert.False(t, xpath.NodeSetValue(nil).Bool(), "empty node set is false") {
+		return
+	}
+}
+
+func TestXPathContextRegisterVariable(t *testing.T) {
+	doc, err := libxml2.ParseString(`<users><user id="1" age="30">alice</user><user id="2" age="17">bob</user><user id="3" age="42">carol</user></users>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	root, err := doc.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+
+	ctx, err := xpath.NewContext(root)
+	if !assert.NoError(t, err, "NewContext should succeed") {
+		return
+	}
+	defer ctx.Free()
+
+	expr, err := xpath.NewExpression(`user[@id = $id]`)
+	if !assert.NoError(t, err, "NewExpression should succeed") {
+		return
+	}
+	defer expr.Free()
+
+	for id, name := range map[string]string{"1": "alice", "3": "carol", "' or '1'='1": ""} {
+		if !assert.NoError(t, ctx.RegisterVariable("id", id), "RegisterVariable should succeed") {
+			return
+		}
+		if !assert.Equal(t, name, xpath.NodeList(ctx.FindExpr(expr)).NodeValue(), "compiled expression sees the value of $id") {
+			return
+		}
+	}
+
+	if !assert.NoError(t, ctx.RegisterVariable("min", 18), "RegisterVariable should succeed") {
+		return
+	}
+	if !assert.Equal(t, 2.0, xpath.Number(ctx.Find(`count(user[@age >= $min])`)), "number variable") {
+		return
+	}
+
+	if !assert.NoError(t, ctx.RegisterVariable("yes", true), "RegisterVariable should succeed") {
+		return
+	}
+	if !assert.Equal(t, 3.0, xpath.Number(ctx.Find(`count(user[$yes])`)), "boolean variable") {
+		return
+	}
+
+	users := xpath.NodeList(ctx.Find(`user`))
+	if !assert.Len(t, users, 3, "users are found") {
+		return
+	}
+	if !assert.NoError(t, ctx.RegisterVariable("minors", users[1:2]), "RegisterVariable should succeed") {
+		return
+	}
+	if !assert.Equal(t, "bob", xpath.NodeList(ctx.Find(`$minors`)).NodeValue(), "node set variable") {
+		return
+	}
+
+	const nsuri = `urn:example:variables`
+	if !assert.NoError(t, ctx.RegisterNS("v", nsuri), "RegisterNS should succeed") {
+		return
+	}
+	if !assert.NoError(t, ctx.RegisterVariableNS(nsuri, "id", "2"), "RegisterVariableNS should succeed") {
+		return
+	}
+	if !assert.Equal(t, "bob", xpath.NodeList(ctx.Find(`user[@id = $v:id]`)).NodeValue(), "namespaced variable") {
+		return
+	}
+
+	if !assert.Error(t, ctx.RegisterVariable("invalid", struct{}{}), "unsupported values are rejected") {
+		return
+	}
+
+	if !assert.NoError(t, ctx.RegisterVariable("min", nil), "unregistering should succeed") {
+		return
+	}
+	_, err = ctx.Find(`user[@age >= $min]`)
+	if !assert.Error(t, err, "unregistered variable should not be found") {
+		return
+	}
+}
+
+func TestXPathContextSetVariableResolver(t *testing.T) {
+	doc, err := libxml2.ParseString(`<users><user id="1">alice</user><user id="2">bob</user></users>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	root, err := doc.DocumentElement()
+	if !assert.NoError(t, err, "DocumentElement should succeed") {
+		return
+	}
+
+	ctx, err := xpath.NewContext(root)
+	if !assert.NoError(t, err, "NewContext should succeed") {
+		return
+	}
+	defer ctx.Free()
+
+	var calls int
+	ids := map[string]string{"first": "1", "second": "2"}
+	err = ctx.SetVariableResolver(func(ns, name string) (xpath.Value, bool) {
+		calls++
+		id, ok := ids[name]
+		if !ok || ns != "" {
+			return xpath.Value{}, false
+		}
+		return xpath.StringValue(id), true
+	})
+	if !assert.NoError(t, err, "SetVariableResolver should succeed") {
+		return
+	}
+
+	if !assert.Equal(t, "bob", xpath.NodeList(ctx.Find(`user[@id = $second]`)).NodeValue(), "variable is resolved") {
+		return
+	}
+
+	if !assert.NoError(t, ctx.RegisterVariable("second", "1"), "RegisterVariable should succeed") {
+		return
+	}
+	calls = 0
+	if !assert.Equal(t, "alice", xpath.NodeList(ctx.Find(`user[@id = $second]`)).NodeValue(), "registered variables take precedence") {
+		return
+	}
+	if !assert.Equal(t, 0, calls, "resolver is not called for registered variables") {
+		return
+	}
+
+	_, err = ctx.Find(`user[@id = $third]`)
+	if !assert.Error(t, err, "undefined variable should fail") {
+		return
+	}
+
+	if !assert.NoError(t, ctx.SetVariableResolver(nil), "unsetting the resolver should succeed") {
+		return
+	}
+	_, err = ctx.Find(`user[@id = $first]`)
+	if !assert.Error(t, err, "resolver should be unset") {
+		return
+	}
+}
+
+func TestXPathContextRegisterFunc_NodeKinds(t *testing.T) {
+	doc, err := libxml2.ParseString(`<r xmlns:a="urn:a"><c/></r>`)
+	if !assert.NoError(t, err, "ParseString should succeed") {
+		return
+	}
+	defer doc.Free()
+
+	ctx, err := xpath.NewContext(doc)
+	if !assert.NoError(t, err, "NewContext should succeed") {
+		return
+	}
+	defer ctx.Free()
+
+	var got types.NodeList
+	// Namespace nodes are only valid during the call
+	uris := map[string]bool{}
+	err = ctx.RegisterFunc("", "f", func(args []xpath.Value) (xpath.Value, error) {
+		got = args[0].NodeList()
+		for _, n := range got {
+			if n.NodeType() != clib.NamespaceDecl {
+				continue
+			}
+			ns, ok := n.(interface{ URI() string })
+			if !ok {
+				return xpath.Value{}, errors.New("namespace node has no URI")
+			}
+			uris[ns.URI()] = true
+		}
+		return args[0], nil
+	})
+	if !assert.NoError(t, err, "RegisterFunc should succeed") {
+		return
+	}
+
+	if !assert.Equal(t, 1.0, xpath.Number(ctx.Find(`count(f(/))`)), "document node is passed and returned") {
+		return
+	}
+	if !assert.Len(t, got, 1, "document node is passed") {
+		return
+	}
+	if _, ok := got[0].(types.Document); !assert.True(t, ok, "document node is a types.Document") {
+		return
+	}
+	if !assert.Equal(t, doc.Pointer(), got[0].Pointer(), "passed node is the document") {
+		return
+	}
+
+	if !assert.Equal(t, 2.0, xpath.Number(ctx.Find(`count(f(/r/namespace::*))`)), "namespace nodes are passed and returned") {
+		return
+	}
+	if !assert.Equal(t, map[string]bool{"urn:a": true, "http://www.w3.org/XML/1998/namespace": true}, uris, "namespace URIs match") {
+		return
+	}
+}
@@ -0,0 +1,109 @@
+package xsd
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Cache holds compiled schemas, so that they can be shared instead of
+// being parsed over and over. Compiled schemas are not modified by
+// validation, so a schema obtained from the cache may be used by
+// several goroutines at the same time.
+//
+// Schemas obtained from the cache belong to it, and must not be freed
+// by the caller. Call Free() on the cache once none of them are in use.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	done   chan struct{}
+	schema *Schema
+	err    error
+}
+
+// NewCache creates an empty schema cache
+func NewCache() *Cache {
+	return &Cache{
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// Get returns the schema stored under key, such as the URI or the
+// target namespace of the schema. If there is none, or if it is still
+// being parsed by Load, false is returned
+func (c *Cache) Get(key string) (*Schema, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	select {
+	case <-e.done:
+		return e.schema, e.err == nil
+	default:
+		return nil, false
+	}
+}
+
+// Load returns the schema stored under key. If there is none, parse is
+// called to produce it. When several goroutines load the same key at
+// the same time, parse is only called once, and the others wait for
+// its result. Failures are not cached
+func (c *Cache) Load(key string, parse func() (*Schema, error)) (*Schema, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		<-e.done
+		return e.schema, e.err
+	}
+
+	e := &cacheEntry{done: make(chan struct{})}
+	c.entries[key] = e
+	c.mu.Unlock()
+
+	// Waiters must be released even if parse panics, in which case
+	// they get an error while the panic goes on in this goroutine
+	e.err = errors.New("schema parsing panicked")
+	defer func() {
+		if e.err != nil {
+			c.mu.Lock()
+			if c.entries[key] == e {
+				delete(c.entries, key)
+			}
+			c.mu.Unlock()
+		}
+		close(e.done)
+	}()
+
+	e.schema, e.err = parse()
+	return e.schema, e.err
+}
+
+// LoadFile returns the schema stored under path, parsing it from the
+// file if needed
+func (c *Cache) LoadFile(path string) (*Schema, error) {
+	return c.Load(path, func() (*Schema, error) {
+		return ParseFromFile(path)
+	})
+}
+
+// Free frees all the schemas in the cache, and empties it. None of them
+// may be used afterwards
+func (c *Cache) Free() {
+	c.mu.Lock()
+	entries := c.entries
+	c.entries = make(map[string]*cacheEntry)
+	c.mu.Unlock()
+
+	for _, e := range entries {
+		<-e.done
+		if e.schema != nil {
+			e.schema.Free()
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package xsd
 
-import "github.com/lestrrat-go/libxml2/internal/option"
+import (
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/internal/option"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/pkg/errors"
+)
 
 // Schema represents an XML schema.
 type Schema struct {
@@ -14,4 +19,167 @@ type SchemaValidationError struct {
 	errors []error
 }
 
-type Option = option.Interface
+// ValidationError holds the details of a single validation error,
+// such as its location, libxml2 error code and severity. The values
+// returned by SchemaValidationError.Errors() are of this type
+type ValidationError struct {
+	clib.StructuredError
+	// Node is the offending node, or nil if it is not known
+	Node types.Node
+}
+
+// ErrComponentNotFound is returned when looking up a component that
+// the schema does not declare
+var ErrComponentNotFound = clib.ErrNodeNotFound
+
+// ErrSchemaFreed is returned when accessing the components of a schema
+// that has been freed
+var ErrSchemaFreed = errors.New("schema has been freed")
+
+// ElementDeclaration describes an element declared in a schema. Its
+// methods fail with ErrSchemaFreed once the schema has been freed
+type ElementDeclaration struct {
+	Name      string
+	Namespace string
+	// Global is true for elements declared at the top level of the
+	// schema, and for references to them
+	Global   bool
+	Nillable bool
+	Abstract bool
+	// Default and Fixed hold the value constraint, if any
+	Default       string
+	Fixed         string
+	Documentation string
+	schema        *Schema
+	typ           uintptr // *C.xmlSchemaType
+}
+
+// AttributeDeclaration describes an attribute declared in a schema.
+// Its methods fail with ErrSchemaFreed once the schema has been freed
+type AttributeDeclaration struct {
+	Name      string
+	Namespace string
+	// Default and Fixed hold the value constraint, if any
+	Default       string
+	Fixed         string
+	Documentation string
+	schema        *Schema
+	typ           uintptr // *C.xmlSchemaType
+}
+
+// AttributeUse describes an attribute allowed on a complex type
+type AttributeUse struct {
+	Declaration *AttributeDeclaration
+	Required    bool
+	// Default and Fixed hold the value constraint of the use, or that
+	// of the declaration if the use does not have one
+	Default string
+	Fixed   string
+}
+
+// TypeDefinition describes a simple or complex type defined in a
+// schema, or a built-in type. Its methods fail with ErrSchemaFreed once
+// the schema has been freed
+type TypeDefinition struct {
+	// Name is empty for anonymous types
+	Name      string
+	Namespace string
+	Complex   bool
+	Builtin   bool
+	// ContentType tells what a complex type allows as content
+	ContentType ContentType
+	// Facets holds the facets defined by this type. Facets inherited
+	// from the base type are available from Base()
+	Facets        []Facet
+	Documentation string
+	schema        *Schema
+	base          uintptr // *C.xmlSchemaType
+	content       uintptr // particle
+	simpleContent uintptr // *C.xmlSchemaType
+	attributes    []clib.SchemaAttributeUseInfo
+}
+
+// Particle is a term of a content model, along with the number of
+// times it may occur. The term is either an element declaration, a
+// model group (sequence, choice or all) containing other particles,
+// or a wildcard. Its methods fail with ErrSchemaFreed once the schema
+// has been freed
+type Particle struct {
+	Kind      ParticleKind
+	MinOccurs int
+	// MaxOccurs is -1 if unbounded
+	MaxOccurs int
+	schema    *Schema
+	element   uintptr // *C.xmlSchemaElement
+	particles []uintptr
+}
+
+// ContentType describes what a complex type allows as content
+type ContentType = clib.SchemaContentType
+
+const (
+	ContentUnknown         = clib.SchemaContentUnknown
+	ContentEmpty           = clib.SchemaContentEmpty
+	ContentElements        = clib.SchemaContentElements
+	ContentMixed           = clib.SchemaContentMixed
+	ContentSimple          = clib.SchemaContentSimple
+	ContentMixedOrElements = clib.SchemaContentMixedOrElements
+	ContentBasic           = clib.SchemaContentBasic
+	ContentAny             = clib.SchemaContentAny
+)
+
+// ParticleKind identifies the term of a Particle
+type ParticleKind = clib.SchemaParticleKind
+
+const (
+	ElementParticle  = clib.SchemaElementParticle
+	SequenceParticle = clib.SchemaSequenceParticle
+	ChoiceParticle   = clib.SchemaChoiceParticle
+	AllParticle      = clib.SchemaAllParticle
+	AnyParticle      = clib.SchemaAnyParticle
+)
+
+// Facet is a constraining facet of a simple type, such as an
+// enumerated value, a pattern or a bound
+type Facet = clib.SchemaFacet
+
+// FacetKind identifies the kind of a Facet
+type FacetKind = clib.SchemaFacetKind
+
+const (
+	FacetMinInclusive   = clib.SchemaFacetMinInclusive
+	FacetMinExclusive   = clib.SchemaFacetMinExclusive
+	FacetMaxInclusive   = clib.SchemaFacetMaxInclusive
+	FacetMaxExclusive   = clib.SchemaFacetMaxExclusive
+	FacetTotalDigits    = clib.SchemaFacetTotalDigits
+	FacetFractionDigits = clib.SchemaFacetFractionDigits
+	FacetPattern        = clib.SchemaFacetPattern
+	FacetEnumeration    = clib.SchemaFacetEnumeration
+	FacetWhiteSpace     = clib.SchemaFacetWhiteSpace
+	FacetLength         = clib.SchemaFacetLength
+	FacetMaxLength      = clib.SchemaFacetMaxLength
+	FacetMinLength      = clib.SchemaFacetMinLength
+)
+
+// ErrorLevel is the severity of a ValidationError
+type ErrorLevel = clib.ErrorLevel
+
+const (
+	ErrorLevelNone    = clib.ErrorLevelNone
+	ErrorLevelWarning = clib.ErrorLevelWarning
+	ErrorLevelError   = clib.ErrorLevelError
+	ErrorLevelFatal   = clib.ErrorLevelFatal
+)
+
+// Option is passed to Parse to change how the schema is parsed
+type Option interface {
+	option.Interface
+	xsdOption()
+}
+
+// ValidationOption is passed to the Validate family of methods to
+// change how documents are validated
+type ValidationOption interface {
+	option.Interface
+	xsdValidationOption()
+}
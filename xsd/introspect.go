@@ -0,0 +1,302 @@
+package xsd
+
+import (
+	"sort"
+
+	"github.com/lestrrat-go/libxml2/clib"
+)
+
+// componentPtr points to a component of a compiled schema
+type componentPtr uintptr
+
+func (p componentPtr) Pointer() uintptr {
+	return uintptr(p)
+}
+
+// alive returns an error if the schema has been freed, as the
+// components point into it
+func (s *Schema) alive() error {
+	if s == nil || s.ptr == 0 {
+		return ErrSchemaFreed
+	}
+	return nil
+}
+
+func newElementDeclaration(s *Schema, ptr uintptr) (*ElementDeclaration, error) {
+	if err := s.alive(); err != nil {
+		return nil, err
+	}
+	info, err := clib.XMLSchemaElementInfo(componentPtr(ptr))
+	if err != nil {
+		return nil, err
+	}
+	return &ElementDeclaration{
+		Name:          info.Name,
+		Namespace:     info.Namespace,
+		Global:        info.Global,
+		Nillable:      info.Nillable,
+		Abstract:      info.Abstract,
+		Default:       info.Default,
+		Fixed:         info.Fixed,
+		Documentation: info.Documentation,
+		schema:        s,
+		typ:           info.Type,
+	}, nil
+}
+
+func newAttributeDeclaration(s *Schema, ptr uintptr) (*AttributeDeclaration, error) {
+	if err := s.alive(); err != nil {
+		return nil, err
+	}
+	info, err := clib.XMLSchemaAttributeInfo(componentPtr(ptr))
+	if err != nil {
+		return nil, err
+	}
+	return &AttributeDeclaration{
+		Name:          info.Name,
+		Namespace:     info.Namespace,
+		Default:       info.Default,
+		Fixed:         info.Fixed,
+		Documentation: info.Documentation,
+		schema:        s,
+		typ:           info.Type,
+	}, nil
+}
+
+func newTypeDefinition(s *Schema, ptr uintptr) (*TypeDefinition, error) {
+	if err := s.alive(); err != nil {
+		return nil, err
+	}
+	info, err := clib.XMLSchemaTypeInfo(componentPtr(ptr))
+	if err != nil {
+		return nil, err
+	}
+	return &TypeDefinition{
+		Name:          info.Name,
+		Namespace:     info.Namespace,
+		Complex:       info.Complex,
+		Builtin:       info.Builtin,
+		ContentType:   info.ContentType,
+		Facets:        info.Facets,
+		Documentation: info.Documentation,
+		schema:        s,
+		base:          info.Base,
+		content:       info.Content,
+		simpleContent: info.SimpleContent,
+		attributes:    info.Attributes,
+	}, nil
+}
+
+func newParticle(s *Schema, ptr uintptr) (*Particle, error) {
+	if err := s.alive(); err != nil {
+		return nil, err
+	}
+	info, err := clib.XMLSchemaParticleInfo(componentPtr(ptr))
+	if err != nil {
+		return nil, err
+	}
+	return &Particle{
+		Kind:      info.Kind,
+		MinOccurs: info.MinOccurs,
+		MaxOccurs: info.MaxOccurs,
+		schema:    s,
+		element:   info.Term,
+		particles: info.Particles,
+	}, nil
+}
+
+// TargetNamespace returns the target namespace of the schema
+func (s *Schema) TargetNamespace() string {
+	ns, err := clib.XMLSchemaTargetNamespace(s)
+	if err != nil {
+		return ""
+	}
+	return ns
+}
+
+// globalComponents returns the global components of the given kind,
+// sorted by name
+func globalComponents[T any](s *Schema, kind clib.SchemaComponentKind, wrap func(*Schema, uintptr) (*T, error), name func(*T) string) []*T {
+	ptrs, err := clib.XMLSchemaGlobalComponents(s, kind)
+	if err != nil {
+		return nil
+	}
+
+	list := make([]*T, 0, len(ptrs))
+	for _, ptr := range ptrs {
+		if c, err := wrap(s, ptr); err == nil {
+			list = append(list, c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return name(list[i]) < name(list[j])
+	})
+	return list
+}
+
+// Elements returns the global element declarations of the schema,
+// sorted by name. Only the declarations in the target namespace of
+// the schema are returned, including those of included schemas, but
+// not those of imported schemas
+func (s *Schema) Elements() []*ElementDeclaration {
+	return globalComponents(s, clib.SchemaElementComponent, newElementDeclaration, func(e *ElementDeclaration) string { return e.Name })
+}
+
+// Types returns the global type definitions of the schema, sorted by
+// name. See Elements() for the namespaces covered
+func (s *Schema) Types() []*TypeDefinition {
+	return globalComponents(s, clib.SchemaTypeComponent, newTypeDefinition, func(t *TypeDefinition) string { return t.Name })
+}
+
+// Attributes returns the global attribute declarations of the schema,
+// sorted by name. See Elements() for the namespaces covered
+func (s *Schema) Attributes() []*AttributeDeclaration {
+	return globalComponents(s, clib.SchemaAttributeComponent, newAttributeDeclaration, func(a *AttributeDeclaration) string { return a.Name })
+}
+
+// Element returns the global element declaration with the given name
+// in the target namespace of the schema
+func (s *Schema) Element(name string) (*ElementDeclaration, error) {
+	ptr, err := clib.XMLSchemaLookupComponent(s, clib.SchemaElementComponent, name)
+	if err != nil {
+		return nil, err
+	}
+	return newElementDeclaration(s, ptr)
+}
+
+// Type returns the global type definition with the given name in the
+// target namespace of the schema
+func (s *Schema) Type(name string) (*TypeDefinition, error) {
+	ptr, err := clib.XMLSchemaLookupComponent(s, clib.SchemaTypeComponent, name)
+	if err != nil {
+		return nil, err
+	}
+	return newTypeDefinition(s, ptr)
+}
+
+// Attribute returns the global attribute declaration with the given
+// name in the target namespace of the schema
+func (s *Schema) Attribute(name string) (*AttributeDeclaration, error) {
+	ptr, err := clib.XMLSchemaLookupComponent(s, clib.SchemaAttributeComponent, name)
+	if err != nil {
+		return nil, err
+	}
+	return newAttributeDeclaration(s, ptr)
+}
+
+// Type returns the type of the element, or nil if it has none
+func (e *ElementDeclaration) Type() (*TypeDefinition, error) {
+	if e.typ == 0 {
+		return nil, e.schema.alive()
+	}
+	return newTypeDefinition(e.schema, e.typ)
+}
+
+// Type returns the type of the attribute, or nil if it has none
+func (a *AttributeDeclaration) Type() (*TypeDefinition, error) {
+	if a.typ == 0 {
+		return nil, a.schema.alive()
+	}
+	return newTypeDefinition(a.schema, a.typ)
+}
+
+// Base returns the type this type is derived from, or nil for the
+// root of the type hierarchy
+func (t *TypeDefinition) Base() (*TypeDefinition, error) {
+	if t.base == 0 {
+		return nil, t.schema.alive()
+	}
+	return newTypeDefinition(t.schema, t.base)
+}
+
+// Content returns the content model of a complex type with element
+// or mixed content, or nil
+func (t *TypeDefinition) Content() (*Particle, error) {
+	if t.content == 0 {
+		return nil, t.schema.alive()
+	}
+	return newParticle(t.schema, t.content)
+}
+
+// SimpleContent returns the type of the content of a complex type
+// with simple content, or nil
+func (t *TypeDefinition) SimpleContent() (*TypeDefinition, error) {
+	if t.simpleContent == 0 {
+		return nil, t.schema.alive()
+	}
+	return newTypeDefinition(t.schema, t.simpleContent)
+}
+
+// Attributes returns the attributes allowed on a complex type,
+// including those inherited from its base type
+func (t *TypeDefinition) Attributes() ([]AttributeUse, error) {
+	if err := t.schema.alive(); err != nil {
+		return nil, err
+	}
+
+	uses := make([]AttributeUse, 0, len(t.attributes))
+	for _, info := range t.attributes {
+		decl, err := newAttributeDeclaration(t.schema, info.Declaration)
+		if err != nil {
+			return nil, err
+		}
+		uses = append(uses, AttributeUse{
+			Declaration: decl,
+			Required:    info.Required,
+			Default:     info.Default,
+			Fixed:       info.Fixed,
+		})
+	}
+	return uses, nil
+}
+
+// Enumerations returns the values of the enumeration facets of the
+// type, or of the closest base type that has any
+func (t *TypeDefinition) Enumerations() ([]string, error) {
+	for cur := t; cur != nil; {
+		var values []string
+		for _, f := range cur.Facets {
+			if f.Kind == FacetEnumeration {
+				values = append(values, f.Value)
+			}
+		}
+		if len(values) > 0 {
+			return values, nil
+		}
+		if cur.Builtin {
+			break
+		}
+
+		var err error
+		if cur, err = cur.Base(); err != nil {
+			return nil, err
+		}
+	}
+	return nil, t.schema.alive()
+}
+
+// Element returns the element declaration of an ElementParticle, or
+// nil for other particles
+func (p *Particle) Element() (*ElementDeclaration, error) {
+	if p.element == 0 {
+		return nil, p.schema.alive()
+	}
+	return newElementDeclaration(p.schema, p.element)
+}
+
+// Particles returns the particles of a model group, in order
+func (p *Particle) Particles() ([]*Particle, error) {
+	if err := p.schema.alive(); err != nil {
+		return nil, err
+	}
+
+	list := make([]*Particle, 0, len(p.particles))
+	for _, ptr := range p.particles {
+		c, err := newParticle(p.schema, ptr)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, nil
+}
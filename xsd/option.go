@@ -1,13 +1,69 @@
 package xsd
 
 import (
+	"io/fs"
 	"net/url"
-	"os"
-	"path/filepath"
+	"path"
+	"strings"
 
+	"github.com/lestrrat-go/libxml2/clib"
 	"github.com/lestrrat-go/libxml2/internal/option"
+	"github.com/lestrrat-go/libxml2/parser"
+	"github.com/pkg/errors"
 )
 
+type parseOption struct {
+	*option.Option
+}
+
+func (parseOption) xsdOption() {}
+
+func newOption(name string, value interface{}) Option {
+	return parseOption{Option: option.New(name, value)}
+}
+
+type validationOption struct {
+	*option.Option
+}
+
+func (validationOption) xsdValidationOption() {}
+
+func newValidationOption(name string, value interface{}) ValidationOption {
+	return validationOption{Option: option.New(name, value)}
+}
+
+// validationFlag is the type of the constants that were passed to
+// Validate before ValidationOption was introduced
+type validationFlag int
+
+func (validationFlag) Name() string {
+	return option.OptKeyWithDefaultAttributes
+}
+
+func (v validationFlag) Value() interface{} {
+	return v&ValueVCCreate != 0
+}
+
+func (validationFlag) xsdValidationOption() {}
+
+// parseOptions converts options to the options understood by clib
+func parseOptions(options []Option) []option.Interface {
+	ret := make([]option.Interface, len(options))
+	for i, o := range options {
+		ret[i] = o
+	}
+	return ret
+}
+
+// validationOptions converts options to the options understood by clib
+func validationOptions(options []ValidationOption) []option.Interface {
+	ret := make([]option.Interface, len(options))
+	for i, o := range options {
+		ret[i] = o
+	}
+	return ret
+}
+
 // WithPath provides a hint to the XSD parser as to where the
 // document being parsed is located at.
 //
@@ -22,20 +78,92 @@ import (
 // should be obtainable via `os.Getwd` when this call is made, otherwise
 // path resolution may fail in weird ways.
 func WithPath(path string) Option {
-	if !filepath.IsAbs(path) {
-		if curdir, err := os.Getwd(); err == nil {
-			path = filepath.Join(curdir, path)
-		}
+	return WithURI(option.PathURI(path))
+}
+
+// WithURI specifies the URI of the schema being parsed, which is
+// used to resolve relative references to other schemas.
+func WithURI(v string) Option {
+	return newOption(option.OptKeyWithURI, v)
+}
+
+// WithEncoding specifies the encoding of the schema document, which
+// overrides the encoding declared in the document itself.
+func WithEncoding(encoding string) Option {
+	return newOption(option.OptKeyWithEncoding, encoding)
+}
+
+// WithParserOptions specifies the options used to parse the schema
+// document, such as parser.XMLParseNoNet.
+func WithParserOptions(options ...parser.Option) Option {
+	var v parser.Option
+	for _, o := range options {
+		v |= o
 	}
+	return newOption(option.OptKeyWithParserOptions, int(v))
+}
+
+// Resolver returns the content of a schema included or imported by
+// the schema being parsed. namespace is the target namespace the
+// schema is expected to have (empty for schemas without one), and
+// location is the value of its schemaLocation attribute, resolved
+// against the location of the including schema.
+type Resolver = clib.SchemaResolver
 
-	return WithURI(
-		(&url.URL{
-			Scheme: `file`,
-			Path:   path,
-		}).String(),
-	)
+// WithResolver makes Parse load the schemas referenced by
+// xs:include, xs:import, xs:redefine and xs:override elements using
+// resolver, instead of reading them from disk or the network. If the
+// resolver returns an error, parsing fails with that error.
+//
+// Locations are relative unless the schema was given a URI using
+// WithPath or WithURI, or the referencing element uses an absolute
+// URL, such as http://www.w3.org/2001/xml.xsd.
+func WithResolver(resolver Resolver) Option {
+	return newOption(option.OptKeyWithResolver, resolver)
 }
 
-func WithURI(v string) Option {
-	return option.New(option.OptKeyWithURI, v)
+// WithFS makes Parse load the schemas referenced by the schema from
+// fsys, such as an embed.FS. Locations relative to the schema being
+// parsed are looked up under the root directory, so that the schema is
+// treated as if it were located in root, even if it was given a URI
+// using WithPath or WithURI. Other absolute URLs cannot be resolved.
+func WithFS(fsys fs.FS, root string) Option {
+	return newOption(option.OptKeyWithResolver, clib.RelativeSchemaResolver(func(_, location string) ([]byte, error) {
+		if strings.Contains(location, ":") || path.IsAbs(location) {
+			return nil, errors.Errorf("cannot resolve absolute location %s", location)
+		}
+		name, err := url.PathUnescape(location)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid location %s", location)
+		}
+		return fs.ReadFile(fsys, path.Join(root, name))
+	}))
+}
+
+// WithDefaultAttributes makes Validate add the attributes that are
+// missing from the document, but have a default value in the schema,
+// to the validated tree.
+func WithDefaultAttributes() ValidationOption {
+	return newValidationOption(option.OptKeyWithDefaultAttributes, true)
+}
+
+// ValidationFlags converts the int flags that Validate accepted before
+// ValidationOption was introduced, so that code which computes them at
+// run time keeps working. ValueVCCreate is the only flag.
+//
+// Deprecated: use WithDefaultAttributes instead
+func ValidationFlags(flags int) ValidationOption {
+	return validationFlag(flags)
+}
+
+// WithMaxErrors limits the number of errors reported to n. This does
+// not stop validation early. A value of 0 means no limit.
+func WithMaxErrors(n int) ValidationOption {
+	return newValidationOption(option.OptKeyWithMaxErrors, n)
+}
+
+// WithWarningsAsErrors makes validation fail when only warnings are
+// reported. By default, warnings are only reported along with errors.
+func WithWarningsAsErrors() ValidationOption {
+	return newValidationOption(option.OptKeyWithWarningsAsErrors, true)
 }
@@ -9,19 +9,27 @@
 //	}
 //	defer schema.Free()
 //	if err := schema.Validate(doc); err != nil{
-//	    for _, e := range err.(SchemaValidationErr).Error() {
-//	         println(e.Error())
+//	    for _, e := range err.(xsd.SchemaValidationError).Errors() {
+//	         verr := e.(xsd.ValidationError)
+//	         println(verr.Line, verr.Path, verr.Message)
 //	    }
 //	}
 package xsd
 
 import (
+	"io"
+
 	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/dom"
 	"github.com/lestrrat-go/libxml2/types"
 	"github.com/pkg/errors"
 )
 
-const ValueVCCreate = 1
+// ValueVCCreate fills in the default values of attributes on the
+// validated tree.
+//
+// Deprecated: use WithDefaultAttributes instead
+const ValueVCCreate validationFlag = 1
 
 // Parse is used to parse an XML Schema Document to produce a
 // Schema instance. Make sure to call Free() on the instance
@@ -29,7 +37,7 @@ const ValueVCCreate = 1
 
 func Parse(buf []byte, options ...Option) (*Schema, error) {
 	// xsd.WithURI(...)
-	sptr, err := clib.XMLSchemaParse(buf, options...)
+	sptr, err := clib.XMLSchemaParse(buf, parseOptions(options)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse input")
 	}
@@ -38,9 +46,11 @@ func Parse(buf []byte, options ...Option) (*Schema, error) {
 }
 
 // ParseFromFile is used to parse an XML schema using only the file path.
-// Make sure to call Free() on the instance when you are done with it.
-func ParseFromFile(path string) (*Schema, error) {
-	sptr, err := clib.XMLSchemaParseFromFile(path)
+// Relative references are resolved against path, unless WithURI() or
+// WithPath() is given. Make sure to call Free() on the instance when
+// you are done with it.
+func ParseFromFile(path string, options ...Option) (*Schema, error) {
+	sptr, err := clib.XMLSchemaParseFromFile(path, parseOptions(options)...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse input from file")
 	}
@@ -48,6 +58,20 @@ func ParseFromFile(path string) (*Schema, error) {
 	return &Schema{ptr: sptr}, nil
 }
 
+// FromDocument is used to produce a Schema instance from a document
+// that has already been parsed, such as a schema extracted from the
+// types section of a WSDL document. The document is copied, so it may
+// be freed afterwards. Make sure to call Free() on the instance when
+// you are done with it.
+func FromDocument(d types.Document, options ...Option) (*Schema, error) {
+	sptr, err := clib.XMLSchemaParseDocument(d, parseOptions(options)...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse document")
+	}
+
+	return &Schema{ptr: sptr}, nil
+}
+
 // Pointer returns the underlying C struct
 func (s *Schema) Pointer() uintptr {
 	return s.ptr
@@ -64,13 +88,71 @@ func (s *Schema) Free() {
 // Validate takes in a XML document and validates it against
 // the schema. If there are any problems, and error is
 // returned.
-func (s *Schema) Validate(d types.Document, options ...int) error {
-	errs := clib.XMLSchemaValidateDocument(s, d, options...)
+func (s *Schema) Validate(d types.Document, options ...ValidationOption) error {
+	errs := clib.XMLSchemaValidateDocument(s, d, validationOptions(options)...)
+	if errs == nil {
+		return nil
+	}
+
+	return SchemaValidationError{errors: validationErrors(errs)}
+}
+
+// ValidateElement validates the element and its descendants against
+// the schema, as if it were the root of a document. This is useful
+// to validate a payload embedded in another document, such as the
+// body of a SOAP envelope. The errors are reported as in Validate().
+func (s *Schema) ValidateElement(elem types.Element, options ...ValidationOption) error {
+	errs := clib.XMLSchemaValidateElement(s, elem, validationOptions(options)...)
+	if errs == nil {
+		return nil
+	}
+
+	return SchemaValidationError{errors: validationErrors(errs)}
+}
+
+// ValidateReader validates the XML document read from r against the
+// schema. The document is validated as it is parsed, without building
+// a tree, so that large documents can be validated in constant memory.
+// The errors are reported as in Validate(), except for errors returned
+// by r, which are reported as they are.
+func (s *Schema) ValidateReader(r io.Reader, options ...ValidationOption) error {
+	errs := clib.XMLSchemaValidateStream(s, r, validationOptions(options)...)
 	if errs == nil {
 		return nil
 	}
 
-	return SchemaValidationError{errors: errs}
+	return SchemaValidationError{errors: validationErrors(errs)}
+}
+
+// ValidateFile validates the XML document stored in the given file
+// against the schema, without building a tree. See ValidateReader().
+func (s *Schema) ValidateFile(path string, options ...ValidationOption) error {
+	errs := clib.XMLSchemaValidateFile(s, path, validationOptions(options)...)
+	if errs == nil {
+		return nil
+	}
+
+	return SchemaValidationError{errors: validationErrors(errs)}
+}
+
+// validationErrors converts the structured errors reported by
+// libxml2 to ValidationErrors, resolving the offending nodes
+func validationErrors(errs []error) []error {
+	for i, err := range errs {
+		serr, ok := err.(clib.StructuredError)
+		if !ok {
+			continue
+		}
+
+		verr := ValidationError{StructuredError: serr}
+		if serr.Node != 0 {
+			if n, err := dom.WrapNode(serr.Node); err == nil {
+				verr.Node = n
+			}
+		}
+		errs[i] = verr
+	}
+	return errs
 }
 
 // Error method fulfils the error interface
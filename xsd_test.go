@@ -1,14 +1,23 @@
 package libxml2_test
 
 import (
+	"errors"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
+	"testing/iotest"
 
 	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/parser"
+	"github.com/lestrrat-go/libxml2/types"
 	"github.com/lestrrat-go/libxml2/xsd"
 	"github.com/stretchr/testify/assert"
 )
@@ -243,3 +252,1027 @@ func TestGHIssue67(t *testing.T) {
 		t.Logf("%s", doc.String())
 	})
 }
+
+const personSchema = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="person">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="name" type="xs:string"/>
+        <xs:element name="age" type="xs:positiveInteger"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+
+func TestXSDValidationErrors(t *testing.T) {
+	s, err := xsd.Parse([]byte(personSchema))
+	if !assert.NoError(t, err, "parsing schema") {
+		return
+	}
+	defer s.Free()
+
+	d, err := libxml2.ParseString(`<person>
+  <name>John</name>
+  <age>old</age>
+</person>`)
+	if !assert.NoError(t, err, "parsing XML") {
+		return
+	}
+	defer d.Free()
+
+	err = s.Validate(d)
+	if !assert.Error(t, err, "s.Validate should fail") {
+		return
+	}
+
+	//nolint:forcetypeassert
+	errs := err.(xsd.SchemaValidationError).Errors()
+	if !assert.Len(t, errs, 1, "there's one error") {
+		return
+	}
+
+	verr, ok := errs[0].(xsd.ValidationError)
+	if !assert.True(t, ok, "error is xsd.ValidationError") {
+		return
+	}
+	t.Logf("err (OK): '%s'", verr)
+
+	if !assert.Equal(t, 3, verr.Line, "line matches") {
+		return
+	}
+	if !assert.Equal(t, "/person/age", verr.Path, "path matches") {
+		return
+	}
+	if !assert.Equal(t, xsd.ErrorLevelError, verr.Level, "level matches") {
+		return
+	}
+	if !assert.NotZero(t, verr.Code, "code is reported") {
+		return
+	}
+	if !assert.Contains(t, verr.Message, "'old' is not a valid value", "message matches") {
+		return
+	}
+	if !assert.NotNil(t, verr.Node, "node is reported") {
+		return
+	}
+	if !assert.Equal(t, "age", verr.Node.NodeName(), "node matches") {
+		return
+	}
+}
+
+func TestXSDValidateElement(t *testing.T) {
+	s, err := xsd.Parse([]byte(personSchema))
+	if !assert.NoError(t, err, "parsing schema") {
+		return
+	}
+	defer s.Free()
+
+	d, err := libxml2.ParseString(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <person><name>John</name><age>42</age></person>
+    <person><name>Jane</name></person>
+  </soap:Body>
+</soap:Envelope>`)
+	if !assert.NoError(t, err, "parsing XML") {
+		return
+	}
+	defer d.Free()
+
+	if !assert.Error(t, s.Validate(d), "the envelope is not valid") {
+		return
+	}
+
+	people, err := d.GetElementsByTagName("person")
+	if !assert.NoError(t, err, "GetElementsByTagName should succeed") {
+		return
+	}
+	if !assert.Len(t, people, 2, "there are two payloads") {
+		return
+	}
+
+	//nolint:forcetypeassert
+	if !assert.NoError(t, s.ValidateElement(people[0].(types.Element)), "first payload is valid") {
+		return
+	}
+
+	//nolint:forcetypeassert
+	err = s.ValidateElement(people[1].(types.Element))
+	if !assert.Error(t, err, "second payload is invalid") {
+		return
+	}
+	//nolint:forcetypeassert
+	errs := err.(xsd.SchemaValidationError).Errors()
+	if !assert.Len(t, errs, 1, "there's one error") {
+		return
+	}
+	//nolint:forcetypeassert
+	verr := errs[0].(xsd.ValidationError)
+	t.Logf("err (OK): '%s'", verr)
+	if !assert.Equal(t, "/soap:Envelope/soap:Body/person[2]", verr.Path, "path matches") {
+		return
+	}
+}
+
+// stalledReader never returns any data, nor an error
+type stalledReader struct{}
+
+func (stalledReader) Read([]byte) (int, error) {
+	return 0, nil
+}
+
+func TestXSDValidateStream(t *testing.T) {
+	s, err := xsd.Parse([]byte(personSchema))
+	if !assert.NoError(t, err, "parsing schema") {
+		return
+	}
+	defer s.Free()
+
+	const validsrc = `<person><name>John</name><age>42</age></person>`
+	const invalidsrc = `<person>
+  <name>John</name>
+  <age>old</age>
+</person>`
+
+	t.Run("ValidateReader", func(t *testing.T) {
+		if !assert.NoError(t, s.ValidateReader(strings.NewReader(validsrc)), "s.ValidateReader should pass") {
+			return
+		}
+
+		err := s.ValidateReader(iotest.OneByteReader(strings.NewReader(invalidsrc)))
+		if !assert.Error(t, err, "s.ValidateReader should fail") {
+			return
+		}
+		//nolint:forcetypeassert
+		errs := err.(xsd.SchemaValidationError).Errors()
+		if !assert.Len(t, errs, 1, "there's one error") {
+			return
+		}
+		verr, ok := errs[0].(xsd.ValidationError)
+		if !assert.True(t, ok, "error is xsd.ValidationError") {
+			return
+		}
+		t.Logf("err (OK): '%s'", verr)
+		if !assert.Equal(t, 3, verr.Line, "line matches") {
+			return
+		}
+		if !assert.Nil(t, verr.Node, "there is no tree to point to") {
+			return
+		}
+	})
+	t.Run("ValidateReader with malformed XML", func(t *testing.T) {
+		err := s.ValidateReader(strings.NewReader(`<person><name>John</person>`))
+		if !assert.Error(t, err, "s.ValidateReader should fail") {
+			return
+		}
+		//nolint:forcetypeassert
+		for _, e := range err.(xsd.SchemaValidationError).Errors() {
+			t.Logf("err (OK): '%s'", e)
+		}
+	})
+	t.Run("ValidateReader with failing reader", func(t *testing.T) {
+		rerr := errors.New("connection reset")
+		r := io.MultiReader(strings.NewReader(`<person><name>`), iotest.ErrReader(rerr))
+		err := s.ValidateReader(r)
+		if !assert.Error(t, err, "s.ValidateReader should fail") {
+			return
+		}
+		//nolint:forcetypeassert
+		errs := err.(xsd.SchemaValidationError).Errors()
+		if !assert.NotEmpty(t, errs, "errors are reported") {
+			return
+		}
+		if !assert.ErrorIs(t, errs[0], rerr, "read error is reported") {
+			return
+		}
+	})
+	t.Run("ValidateReader with stalled reader", func(t *testing.T) {
+		r := io.MultiReader(strings.NewReader(`<person><name>`), stalledReader{})
+		err := s.ValidateReader(r)
+		if !assert.Error(t, err, "s.ValidateReader should fail") {
+			return
+		}
+		//nolint:forcetypeassert
+		errs := err.(xsd.SchemaValidationError).Errors()
+		if !assert.NotEmpty(t, errs, "errors are reported") {
+			return
+		}
+		if !assert.ErrorIs(t, errs[0], io.ErrNoProgress, "lack of progress is reported") {
+			return
+		}
+	})
+	t.Run("ValidateFile", func(t *testing.T) {
+		dir := t.TempDir()
+		valid := filepath.Join(dir, "valid.xml")
+		invalid := filepath.Join(dir, "invalid.xml")
+		if !assert.NoError(t, os.WriteFile(valid, []byte(validsrc), 0o600), "writing file") {
+			return
+		}
+		if !assert.NoError(t, os.WriteFile(invalid, []byte(invalidsrc), 0o600), "writing file") {
+			return
+		}
+
+		if !assert.NoError(t, s.ValidateFile(valid), "s.ValidateFile should pass") {
+			return
+		}
+
+		err := s.ValidateFile(invalid)
+		if !assert.Error(t, err, "s.ValidateFile should fail") {
+			return
+		}
+		//nolint:forcetypeassert
+		verr := err.(xsd.SchemaValidationError).Errors()[0].(xsd.ValidationError)
+		if !assert.Equal(t, 3, verr.Line, "line matches") {
+			return
+		}
+
+		if !assert.Error(t, s.ValidateFile(filepath.Join(dir, "missing.xml")), "s.ValidateFile should fail") {
+			return
+		}
+	})
+}
+
+func TestXSDOptions(t *testing.T) {
+	t.Run("WithEncoding", func(t *testing.T) {
+		// "café" in ISO-8859-1, without an encoding declaration
+		src := []byte("<xs:schema xmlns:xs=\"http://www.w3.org/2001/XMLSchema\"><xs:annotation><xs:documentation>caf\xe9</xs:documentation></xs:annotation><xs:element name=\"a\"/></xs:schema>")
+
+		_, err := xsd.Parse(src)
+		if !assert.Error(t, err, "xsd.Parse should fail without the encoding") {
+			return
+		}
+
+		s, err := xsd.Parse(src, xsd.WithEncoding("ISO-8859-1"))
+		if !assert.NoError(t, err, "xsd.Parse should succeed with the encoding") {
+			return
+		}
+		s.Free()
+	})
+	t.Run("WithParserOptions", func(t *testing.T) {
+		src := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a"/>`)
+
+		_, err := xsd.Parse(src)
+		if !assert.Error(t, err, "xsd.Parse should fail on a truncated schema") {
+			return
+		}
+
+		s, err := xsd.Parse(src, xsd.WithParserOptions(parser.XMLParseRecover, parser.XMLParseNoError))
+		if !assert.NoError(t, err, "xsd.Parse should recover") {
+			return
+		}
+		s.Free()
+	})
+
+	s, err := xsd.Parse([]byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="list">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="item" maxOccurs="unbounded">
+          <xs:complexType>
+            <xs:attribute name="qty" type="xs:positiveInteger" default="1"/>
+          </xs:complexType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`))
+	if !assert.NoError(t, err, "parsing schema") {
+		return
+	}
+	defer s.Free()
+
+	t.Run("WithDefaultAttributes", func(t *testing.T) {
+		for _, defaults := range []bool{false, true} {
+			d, err := libxml2.ParseString(`<list><item/><item qty="3"/></list>`)
+			if !assert.NoError(t, err, "parsing XML") {
+				return
+			}
+			defer d.Free()
+
+			var options []xsd.ValidationOption
+			expected := `<list><item/><item qty="3"/></list>`
+			if defaults {
+				options = append(options, xsd.WithDefaultAttributes())
+				expected = `<list><item qty="1"/><item qty="3"/></list>`
+			}
+			if !assert.NoError(t, s.Validate(d, options...), "s.Validate should pass") {
+				return
+			}
+
+			root, err := d.DocumentElement()
+			if !assert.NoError(t, err, "DocumentElement should succeed") {
+				return
+			}
+			if !assert.Equal(t, expected, root.String(), "default attributes are added when requested") {
+				return
+			}
+		}
+	})
+	t.Run("ValidationFlags", func(t *testing.T) {
+		for _, flags := range []int{0, int(xsd.ValueVCCreate)} {
+			d, err := libxml2.ParseString(`<list><item/></list>`)
+			if !assert.NoError(t, err, "parsing XML") {
+				return
+			}
+			defer d.Free()
+
+			if !assert.NoError(t, s.Validate(d, xsd.ValidationFlags(flags)), "s.Validate should pass") {
+				return
+			}
+
+			root, err := d.DocumentElement()
+			if !assert.NoError(t, err, "DocumentElement should succeed") {
+				return
+			}
+			expected := `<list><item/></list>`
+			if flags != 0 {
+				expected = `<list><item qty="1"/></list>`
+			}
+			if !assert.Equal(t, expected, root.String(), "default attributes are added for ValueVCCreate") {
+				return
+			}
+		}
+	})
+	t.Run("WithMaxErrors", func(t *testing.T) {
+		d, err := libxml2.ParseString(`<list><item qty="a"/><item qty="b"/><item qty="c"/></list>`)
+		if !assert.NoError(t, err, "parsing XML") {
+			return
+		}
+		defer d.Free()
+
+		//nolint:forcetypeassert
+		errs := s.Validate(d).(xsd.SchemaValidationError).Errors()
+		if !assert.Len(t, errs, 3, "all errors are reported") {
+			return
+		}
+
+		//nolint:forcetypeassert
+		errs = s.Validate(d, xsd.WithMaxErrors(2)).(xsd.SchemaValidationError).Errors()
+		if !assert.Len(t, errs, 2, "errors are limited") {
+			return
+		}
+	})
+	t.Run("WithWarningsAsErrors", func(t *testing.T) {
+		d, err := libxml2.ParseString(`<list><item/></list>`)
+		if !assert.NoError(t, err, "parsing XML") {
+			return
+		}
+		defer d.Free()
+
+		if !assert.NoError(t, s.Validate(d, xsd.WithWarningsAsErrors()), "s.Validate should pass without warnings") {
+			return
+		}
+	})
+	t.Run("option kinds", func(t *testing.T) {
+		var o interface{} = xsd.WithDefaultAttributes()
+		if _, ok := o.(xsd.Option); !assert.False(t, ok, "validation options are not parse options") {
+			return
+		}
+		o = xsd.WithURI("schema.xsd")
+		if _, ok := o.(xsd.ValidationOption); !assert.False(t, ok, "parse options are not validation options") {
+			return
+		}
+	})
+}
+
+func TestXSDResolver(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/common/types.xsd": &fstest.MapFile{Data: []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:order">
+  <xs:include schemaLocation="units.xsd"/>
+  <xs:simpleType name="QtyType">
+    <xs:restriction base="xs:positiveInteger"/>
+  </xs:simpleType>
+</xs:schema>`)},
+		"schemas/common/units.xsd": &fstest.MapFile{Data: []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:order">
+  <xs:simpleType name="UnitType">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="kg"/>
+      <xs:enumeration value="pcs"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`)},
+		"schemas/party/party.xsd": &fstest.MapFile{Data: []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:party" elementFormDefault="qualified">
+  <xs:element name="buyer" type="xs:string"/>
+</xs:schema>`)},
+	}
+	const src = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:o="urn:order" xmlns:p="urn:party" targetNamespace="urn:order" elementFormDefault="qualified">
+  <xs:include schemaLocation="common/types.xsd"/>
+  <xs:import namespace="urn:party" schemaLocation="party/party.xsd"/>
+  <xs:element name="order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element ref="p:buyer"/>
+        <xs:element name="qty" type="o:QtyType"/>
+        <xs:element name="unit" type="o:UnitType"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+
+	validate := func(t *testing.T, s *xsd.Schema) {
+		d, err := libxml2.ParseString(`<order xmlns="urn:order" xmlns:p="urn:party"><p:buyer>ACME</p:buyer><qty>3</qty><unit>kg</unit></order>`)
+		if !assert.NoError(t, err, "parsing XML") {
+			return
+		}
+		defer d.Free()
+
+		if !assert.NoError(t, s.Validate(d), "s.Validate should pass") {
+			return
+		}
+	}
+
+	t.Run("WithFS", func(t *testing.T) {
+		s, err := xsd.Parse([]byte(src), xsd.WithFS(fsys, "schemas"))
+		if !assert.NoError(t, err, "xsd.Parse should succeed") {
+			return
+		}
+		defer s.Free()
+
+		validate(t, s)
+	})
+	t.Run("WithFS and WithPath", func(t *testing.T) {
+		s, err := xsd.Parse([]byte(src), xsd.WithPath(filepath.Join("test", "order.xsd")), xsd.WithFS(fsys, "schemas"))
+		if !assert.NoError(t, err, "xsd.Parse should succeed") {
+			return
+		}
+		defer s.Free()
+
+		validate(t, s)
+	})
+	t.Run("WithResolver", func(t *testing.T) {
+		resolved := map[string]string{}
+		s, err := xsd.Parse([]byte(src), xsd.WithResolver(func(ns, location string) ([]byte, error) {
+			resolved[location] = ns
+			return fs.ReadFile(fsys, "schemas/"+location)
+		}))
+		if !assert.NoError(t, err, "xsd.Parse should succeed") {
+			return
+		}
+		defer s.Free()
+
+		expected := map[string]string{
+			"common/types.xsd": "urn:order",
+			"common/units.xsd": "urn:order",
+			"party/party.xsd":  "urn:party",
+		}
+		if !assert.Equal(t, expected, resolved, "locations and namespaces are passed to the resolver") {
+			return
+		}
+
+		validate(t, s)
+	})
+	t.Run("WithResolver error", func(t *testing.T) {
+		rerr := errors.New("not allowed")
+		_, err := xsd.Parse([]byte(src), xsd.WithResolver(func(_, location string) ([]byte, error) {
+			if location == "party/party.xsd" {
+				return nil, rerr
+			}
+			return fs.ReadFile(fsys, "schemas/"+location)
+		}))
+		if !assert.ErrorIs(t, err, rerr, "xsd.Parse should fail with the resolver's error") {
+			return
+		}
+		t.Logf("err (OK): '%s'", err)
+	})
+	t.Run("WithResolver empty content", func(t *testing.T) {
+		_, err := xsd.Parse([]byte(src), xsd.WithResolver(func(_, location string) ([]byte, error) {
+			if location == "party/party.xsd" {
+				return []byte{}, nil
+			}
+			return fs.ReadFile(fsys, "schemas/"+location)
+		}))
+		if !assert.Error(t, err, "xsd.Parse should fail") {
+			return
+		}
+		if !assert.Contains(t, err.Error(), "Failed to parse the XML resource", "empty content is parsed rather than reported as not loaded") {
+			return
+		}
+	})
+	t.Run("ParseFromFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "order.xsd")
+		if !assert.NoError(t, os.WriteFile(path, []byte(src), 0o600), "writing schema") {
+			return
+		}
+
+		s, err := xsd.ParseFromFile(path, xsd.WithURI("order.xsd"), xsd.WithFS(fsys, "schemas"))
+		if !assert.NoError(t, err, "xsd.ParseFromFile should succeed") {
+			return
+		}
+		defer s.Free()
+
+		validate(t, s)
+	})
+	t.Run("WithFS missing file", func(t *testing.T) {
+		_, err := xsd.Parse([]byte(src), xsd.WithFS(fsys, "elsewhere"))
+		if !assert.ErrorIs(t, err, fs.ErrNotExist, "xsd.Parse should fail") {
+			return
+		}
+	})
+}
+
+func TestXSDIntrospection(t *testing.T) {
+	s, err := xsd.ParseFromFile(filepath.Join("test", "schema", "catalog.xsd"))
+	if !assert.NoError(t, err, "parsing schema") {
+		return
+	}
+	defer s.Free()
+
+	if !assert.Equal(t, "urn:example:catalog", s.TargetNamespace(), "target namespace matches") {
+		return
+	}
+
+	var names []string
+	for _, e := range s.Elements() {
+		names = append(names, e.Name)
+	}
+	if !assert.Equal(t, []string{"catalog", "product"}, names, "global elements are listed") {
+		return
+	}
+
+	names = nil
+	for _, typ := range s.Types() {
+		names = append(names, typ.Name)
+	}
+	if !assert.Equal(t, []string{"ColorType", "PriceType", "ProductType", "SizeType", "SkuType"}, names, "global types are listed") {
+		return
+	}
+
+	t.Run("elements", func(t *testing.T) {
+		catalog, err := s.Element("catalog")
+		if !assert.NoError(t, err, "Element should succeed") {
+			return
+		}
+		if !assert.Equal(t, "A list of products.", catalog.Documentation, "documentation matches") {
+			return
+		}
+
+		typ, err := catalog.Type()
+		if !assert.NoError(t, err, "Type should succeed") {
+			return
+		}
+		if !assert.Equal(t, "", typ.Name, "type is anonymous") {
+			return
+		}
+		if !assert.Equal(t, xsd.ContentElements, typ.ContentType, "type has element content") {
+			return
+		}
+
+		attrs, err := typ.Attributes()
+		if !assert.NoError(t, err, "Attributes should succeed") {
+			return
+		}
+		if !assert.Len(t, attrs, 1, "one attribute is allowed") {
+			return
+		}
+		if !assert.Equal(t, "version", attrs[0].Declaration.Name, "attribute name matches") {
+			return
+		}
+		if !assert.Equal(t, "1.0", attrs[0].Fixed, "fixed value matches") {
+			return
+		}
+
+		content, err := typ.Content()
+		if !assert.NoError(t, err, "Content should succeed") {
+			return
+		}
+		if !assert.Equal(t, xsd.SequenceParticle, content.Kind, "content is a sequence") {
+			return
+		}
+		particles, err := content.Particles()
+		if !assert.NoError(t, err, "Particles should succeed") {
+			return
+		}
+		if !assert.Len(t, particles, 1, "sequence has one particle") {
+			return
+		}
+		if !assert.Equal(t, xsd.ElementParticle, particles[0].Kind, "particle is an element") {
+			return
+		}
+		if !assert.Equal(t, -1, particles[0].MaxOccurs, "element is unbounded") {
+			return
+		}
+		product, err := particles[0].Element()
+		if !assert.NoError(t, err, "Element should succeed") {
+			return
+		}
+		productType, err := product.Type()
+		if !assert.NoError(t, err, "Type should succeed") {
+			return
+		}
+		if !assert.Equal(t, "ProductType", productType.Name, "reference is resolved") {
+			return
+		}
+
+		_, err = s.Element("missing")
+		if !assert.ErrorIs(t, err, xsd.ErrComponentNotFound, "unknown elements are not found") {
+			return
+		}
+	})
+	t.Run("complex types", func(t *testing.T) {
+		typ, err := s.Type("ProductType")
+		if !assert.NoError(t, err, "Type should succeed") {
+			return
+		}
+		if !assert.True(t, typ.Complex, "type is complex") {
+			return
+		}
+		if !assert.Equal(t, "A product for sale.", typ.Documentation, "documentation matches") {
+			return
+		}
+
+		uses, err := typ.Attributes()
+		if !assert.NoError(t, err, "Attributes should succeed") {
+			return
+		}
+		attrs := map[string]xsd.AttributeUse{}
+		for _, a := range uses {
+			attrs[a.Declaration.Name] = a
+		}
+		if !assert.True(t, attrs["sku"].Required, "sku is required") {
+			return
+		}
+		skuType, err := attrs["sku"].Declaration.Type()
+		if !assert.NoError(t, err, "Type should succeed") {
+			return
+		}
+		if !assert.Equal(t, "SkuType", skuType.Name, "sku type matches") {
+			return
+		}
+		if !assert.False(t, attrs["status"].Required, "status is optional") {
+			return
+		}
+		if !assert.Equal(t, "active", attrs["status"].Default, "default value matches") {
+			return
+		}
+
+		content, err := typ.Content()
+		if !assert.NoError(t, err, "Content should succeed") {
+			return
+		}
+		particles, err := content.Particles()
+		if !assert.NoError(t, err, "Particles should succeed") {
+			return
+		}
+		if !assert.Len(t, particles, 3, "sequence has three particles") {
+			return
+		}
+		name, err := particles[0].Element()
+		if !assert.NoError(t, err, "Element should succeed") {
+			return
+		}
+		if !assert.Equal(t, "name", name.Name, "first element matches") {
+			return
+		}
+		if !assert.Equal(t, "urn:example:catalog", name.Namespace, "local elements are qualified") {
+			return
+		}
+
+		choice := particles[2]
+		if !assert.Equal(t, xsd.ChoiceParticle, choice.Kind, "third particle is a choice") {
+			return
+		}
+		if !assert.Equal(t, 0, choice.MinOccurs, "choice is optional") {
+			return
+		}
+		options, err := choice.Particles()
+		if !assert.NoError(t, err, "Particles should succeed") {
+			return
+		}
+		if !assert.Len(t, options, 2, "choice has two options") {
+			return
+		}
+		size, err := options[1].Element()
+		if !assert.NoError(t, err, "Element should succeed") {
+			return
+		}
+		if !assert.True(t, size.Nillable, "size is nillable") {
+			return
+		}
+
+		price, err := s.Type("PriceType")
+		if !assert.NoError(t, err, "Type should succeed") {
+			return
+		}
+		if !assert.Equal(t, xsd.ContentSimple, price.ContentType, "price has simple content") {
+			return
+		}
+		simple, err := price.SimpleContent()
+		if !assert.NoError(t, err, "SimpleContent should succeed") {
+			return
+		}
+		if !assert.Equal(t, "decimal", simple.Name, "simple content type matches") {
+			return
+		}
+		content, err = price.Content()
+		if !assert.NoError(t, err, "Content should succeed") {
+			return
+		}
+		if !assert.Nil(t, content, "price has no content model") {
+			return
+		}
+	})
+	t.Run("simple types", func(t *testing.T) {
+		color, err := s.Type("ColorType")
+		if !assert.NoError(t, err, "Type should succeed") {
+			return
+		}
+		if !assert.False(t, color.Complex, "type is simple") {
+			return
+		}
+		if !assert.Equal(t, "Available colors.", color.Documentation, "documentation matches") {
+			return
+		}
+		values, err := color.Enumerations()
+		if !assert.NoError(t, err, "Enumerations should succeed") {
+			return
+		}
+		if !assert.Equal(t, []string{"red", "green", "blue"}, values, "enumerations match") {
+			return
+		}
+		base, err := color.Base()
+		if !assert.NoError(t, err, "Base should succeed") {
+			return
+		}
+		if !assert.Equal(t, "string", base.Name, "base type matches") {
+			return
+		}
+		if !assert.True(t, base.Builtin, "base type is built in") {
+			return
+		}
+		if !assert.Equal(t, "http://www.w3.org/2001/XMLSchema", base.Namespace, "base type namespace matches") {
+			return
+		}
+
+		size, err := s.Type("SizeType")
+		if !assert.NoError(t, err, "Type should succeed") {
+			return
+		}
+		if !assert.Equal(t, []xsd.Facet{
+			{Kind: xsd.FacetMinInclusive, Value: "1"},
+			{Kind: xsd.FacetMaxInclusive, Value: "50"},
+		}, size.Facets, "facets match") {
+			return
+		}
+
+		sku, err := s.Type("SkuType")
+		if !assert.NoError(t, err, "Type should succeed") {
+			return
+		}
+		if !assert.Equal(t, []xsd.Facet{{Kind: xsd.FacetPattern, Value: "[A-Z]{3}-[0-9]{4}"}}, sku.Facets, "pattern matches") {
+			return
+		}
+	})
+}
+
+func TestXSDIntrospectionAfterFree(t *testing.T) {
+	s, err := xsd.ParseFromFile(filepath.Join("test", "schema", "catalog.xsd"))
+	if !assert.NoError(t, err, "parsing schema") {
+		return
+	}
+
+	catalog, err := s.Element("catalog")
+	if !assert.NoError(t, err, "Element should succeed") {
+		return
+	}
+	typ, err := s.Type("ProductType")
+	if !assert.NoError(t, err, "Type should succeed") {
+		return
+	}
+	content, err := typ.Content()
+	if !assert.NoError(t, err, "Content should succeed") {
+		return
+	}
+	s.Free()
+
+	_, err = catalog.Type()
+	if !assert.ErrorIs(t, err, xsd.ErrSchemaFreed, "Type should fail once the schema is freed") {
+		return
+	}
+	_, err = typ.Base()
+	if !assert.ErrorIs(t, err, xsd.ErrSchemaFreed, "Base should fail once the schema is freed") {
+		return
+	}
+	_, err = typ.Attributes()
+	if !assert.ErrorIs(t, err, xsd.ErrSchemaFreed, "Attributes should fail once the schema is freed") {
+		return
+	}
+	_, err = content.Particles()
+	if !assert.ErrorIs(t, err, xsd.ErrSchemaFreed, "Particles should fail once the schema is freed") {
+		return
+	}
+}
+
+// TestXSDIntrospectionLayout reads values that are only stored in the
+// structures private to libxml2's xmlschemas.c, which clib mirrors. If
+// it fails, the layout of those structures changed in the libxml2 that
+// is being built against, and the mirrors must be updated
+func TestXSDIntrospectionLayout(t *testing.T) {
+	const src = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:attribute name="lang" type="xs:string"/>
+  <xs:element name="root">
+    <xs:complexType>
+      <xs:sequence minOccurs="3" maxOccurs="5">
+        <xs:element name="item" type="xs:string" minOccurs="2" maxOccurs="7"/>
+      </xs:sequence>
+      <xs:attribute ref="lang" fixed="en"/>
+      <xs:attribute name="mode" type="xs:string" default="fast" use="optional"/>
+      <xs:attribute name="id" type="xs:ID" use="required"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+	const layoutChanged = "the private schema layout of this libxml2 does not match clib's mirrors"
+
+	s, err := xsd.Parse([]byte(src))
+	if !assert.NoError(t, err, "parsing schema") {
+		return
+	}
+	defer s.Free()
+
+	root, err := s.Element("root")
+	if !assert.NoError(t, err, "Element should succeed") {
+		return
+	}
+	typ, err := root.Type()
+	if !assert.NoError(t, err, "Type should succeed") {
+		return
+	}
+
+	uses, err := typ.Attributes()
+	if !assert.NoError(t, err, layoutChanged) {
+		return
+	}
+	attrs := map[string]xsd.AttributeUse{}
+	for _, a := range uses {
+		attrs[a.Declaration.Name] = a
+	}
+	if !assert.Len(t, attrs, 3, layoutChanged) {
+		return
+	}
+	if !assert.Equal(t, "en", attrs["lang"].Fixed, layoutChanged) {
+		return
+	}
+	if !assert.Equal(t, "fast", attrs["mode"].Default, layoutChanged) {
+		return
+	}
+	if !assert.False(t, attrs["mode"].Required, layoutChanged) {
+		return
+	}
+	if !assert.True(t, attrs["id"].Required, layoutChanged) {
+		return
+	}
+
+	content, err := typ.Content()
+	if !assert.NoError(t, err, layoutChanged) {
+		return
+	}
+	if !assert.Equal(t, xsd.SequenceParticle, content.Kind, layoutChanged) {
+		return
+	}
+	if !assert.Equal(t, 3, content.MinOccurs, layoutChanged) {
+		return
+	}
+	if !assert.Equal(t, 5, content.MaxOccurs, layoutChanged) {
+		return
+	}
+	particles, err := content.Particles()
+	if !assert.NoError(t, err, layoutChanged) {
+		return
+	}
+	if !assert.Len(t, particles, 1, layoutChanged) {
+		return
+	}
+	if !assert.Equal(t, 2, particles[0].MinOccurs, layoutChanged) {
+		return
+	}
+	if !assert.Equal(t, 7, particles[0].MaxOccurs, layoutChanged) {
+		return
+	}
+	item, err := particles[0].Element()
+	if !assert.NoError(t, err, layoutChanged) {
+		return
+	}
+	if !assert.Equal(t, "item", item.Name, layoutChanged) {
+		return
+	}
+}
+
+func TestXSDFromDocument(t *testing.T) {
+	d, err := libxml2.ParseString(personSchema)
+	if !assert.NoError(t, err, "parsing schema document") {
+		return
+	}
+
+	s, err := xsd.FromDocument(d)
+	if !assert.NoError(t, err, "xsd.FromDocument should succeed") {
+		return
+	}
+	defer s.Free()
+
+	// The schema does not depend on the original document
+	d.Free()
+
+	doc, err := libxml2.ParseString(`<person><name>John</name><age>0</age></person>`)
+	if !assert.NoError(t, err, "parsing XML") {
+		return
+	}
+	defer doc.Free()
+
+	if !assert.Error(t, s.Validate(doc), "s.Validate should fail") {
+		return
+	}
+
+	d, err = libxml2.ParseString(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a" type="missing"/></xs:schema>`)
+	if !assert.NoError(t, err, "parsing schema document") {
+		return
+	}
+	defer d.Free()
+
+	_, err = xsd.FromDocument(d)
+	if !assert.Error(t, err, "xsd.FromDocument should fail") {
+		return
+	}
+	t.Logf("err (OK): '%s'", err)
+}
+
+func TestXSDCache(t *testing.T) {
+	c := xsd.NewCache()
+	defer c.Free()
+
+	if _, ok := c.Get("urn:person"); !assert.False(t, ok, "cache is empty") {
+		return
+	}
+
+	var parsed int32
+	parse := func() (*xsd.Schema, error) {
+		atomic.AddInt32(&parsed, 1)
+		return xsd.Parse([]byte(personSchema))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			s, err := c.Load("urn:person", parse)
+			if !assert.NoError(t, err, "c.Load should succeed") {
+				return
+			}
+
+			d, err := libxml2.ParseString(`<person><name>John</name><age>42</age></person>`)
+			if !assert.NoError(t, err, "parsing XML") {
+				return
+			}
+			defer d.Free()
+
+			assert.NoError(t, s.Validate(d), "s.Validate should pass")
+		}()
+	}
+	wg.Wait()
+
+	if !assert.Equal(t, int32(1), atomic.LoadInt32(&parsed), "schema is parsed once") {
+		return
+	}
+	if _, ok := c.Get("urn:person"); !assert.True(t, ok, "schema is cached") {
+		return
+	}
+
+	_, err := c.Load("broken", func() (*xsd.Schema, error) {
+		return xsd.Parse([]byte(`<broken`))
+	})
+	if !assert.Error(t, err, "c.Load should fail") {
+		return
+	}
+	if _, ok := c.Get("broken"); !assert.False(t, ok, "failures are not cached") {
+		return
+	}
+
+	if !assert.Panics(t, func() {
+		_, _ = c.Load("panic", func() (*xsd.Schema, error) {
+			panic("boom")
+		})
+	}, "c.Load should propagate the panic") {
+		return
+	}
+	if _, ok := c.Get("panic"); !assert.False(t, ok, "panics are not cached") {
+		return
+	}
+	s, err := c.Load("panic", func() (*xsd.Schema, error) {
+		return xsd.Parse([]byte(personSchema))
+	})
+	if !assert.NoError(t, err, "c.Load should succeed after a panic") || !assert.NotNil(t, s, "schema is loaded") {
+		return
+	}
+
+	path := filepath.Join("test", "schema", "catalog.xsd")
+	s1, err := c.LoadFile(path)
+	if !assert.NoError(t, err, "c.LoadFile should succeed") {
+		return
+	}
+	s2, err := c.LoadFile(path)
+	if !assert.NoError(t, err, "c.LoadFile should succeed") {
+		return
+	}
+	if !assert.Same(t, s1, s2, "schema is reused") {
+		return
+	}
+}
@@ -0,0 +1,81 @@
+package libxml2_test
+
+import (
+	"encoding/xml"
+	"path/filepath"
+	"testing"
+
+	"github.com/lestrrat-go/libxml2/test/xsdgen/catalog"
+	"github.com/lestrrat-go/libxml2/xsd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXSDGeneratedTypes(t *testing.T) {
+	s, err := xsd.ParseFromFile(filepath.Join("test", "schema", "catalog.xsd"))
+	if !assert.NoError(t, err, "parsing schema") {
+		return
+	}
+	defer s.Free()
+
+	product := catalog.Product{}
+	if !assert.Error(t, product.Validate(), "Validate should fail without a schema") {
+		return
+	}
+
+	catalog.SetSchema(s)
+
+	red := catalog.ColorTypeRed
+	c := catalog.Catalog{
+		Product: []catalog.Product{
+			{
+				ProductType: catalog.ProductType{
+					Name:  "Shirt",
+					Price: catalog.PriceType{Value: 19.99, Currency: "EUR"},
+					Color: &red,
+					Sku:   "SHT-0001",
+				},
+			},
+			{
+				ProductType: catalog.ProductType{
+					Name:  "Mug",
+					Price: catalog.PriceType{Value: 5, Currency: "USD"},
+					Sku:   "MUG-0002",
+				},
+			},
+		},
+	}
+	if !assert.NoError(t, c.Validate(), "valid catalog should validate") {
+		return
+	}
+
+	buf, err := xml.Marshal(c)
+	if !assert.NoError(t, err, "Marshal should succeed") {
+		return
+	}
+	var decoded catalog.Catalog
+	if !assert.NoError(t, xml.Unmarshal(buf, &decoded), "Unmarshal should succeed") {
+		return
+	}
+	if !assert.Len(t, decoded.Product, 2, "products are decoded") {
+		return
+	}
+	if !assert.Equal(t, &red, decoded.Product[0].Color, "color is decoded") {
+		return
+	}
+	if !assert.Equal(t, catalog.SkuType("MUG-0002"), decoded.Product[1].Sku, "sku is decoded") {
+		return
+	}
+
+	c.Product[1].Sku = "mug"
+	err = c.Validate()
+	if !assert.Error(t, err, "invalid sku should fail validation") {
+		return
+	}
+	verr, ok := err.(xsd.SchemaValidationError)
+	if !assert.True(t, ok, "error is a SchemaValidationError") {
+		return
+	}
+	if !assert.Len(t, verr.Errors(), 1, "one error is reported") {
+		return
+	}
+}
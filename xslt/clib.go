@@ -0,0 +1,571 @@
+package xslt
+
+/*
+#include <stdlib.h>
+#include <stdarg.h>
+#include <string.h>
+#include <stdint.h>
+#include <stdio.h>
+#include <libxml/parser.h>
+#include <libxml/tree.h>
+#include <libxml/xmlerror.h>
+#include <libxslt/xslt.h>
+#include <libxslt/xsltInternals.h>
+#include <libxslt/transform.h>
+#include <libxslt/variables.h>
+#include <libxslt/xsltutils.h>
+#include <libxslt/imports.h>
+#include <libxslt/security.h>
+
+// go_xslt_error is either a structured error reported by libxml2,
+// or a chunk of text printed by a libxslt error handler, which only
+// know about printf-style callbacks. Chunks are put back together
+// into messages on the Go side
+typedef struct go_xslt_error {
+	char *message;
+	char *file;
+	int line;
+	int column;
+	int code;
+	int domain;
+	int level;
+	int chunk;
+} go_xslt_error;
+
+typedef struct go_xslt_error_list {
+	go_xslt_error *errors;
+	int count;
+	int size;
+} go_xslt_error_list;
+
+static
+go_xslt_error_list*
+MY_xsltCreateErrorList() {
+	return (go_xslt_error_list *) calloc(1, sizeof(go_xslt_error_list));
+}
+
+static
+void
+MY_xsltFreeErrorList(go_xslt_error_list *list) {
+	int i;
+	for (i = 0; i < list->count; i++) {
+		free(list->errors[i].message);
+		free(list->errors[i].file);
+	}
+	free(list->errors);
+	free(list);
+}
+
+static
+go_xslt_error*
+MY_xsltAppendError(go_xslt_error_list *list) {
+	if (list->count >= list->size) {
+		int size = list->size == 0 ? 8 : list->size * 2;
+		go_xslt_error *errors = (go_xslt_error *) realloc(list->errors, size * sizeof(go_xslt_error));
+		if (errors == NULL) {
+			return NULL;
+		}
+		list->errors = errors;
+		list->size = size;
+	}
+	return (go_xslt_error *) memset(&list->errors[list->count++], 0, sizeof(go_xslt_error));
+}
+
+static
+char*
+MY_xsltStrdupOrNull(const char *s) {
+	if (s == NULL) {
+		return NULL;
+	}
+	return strdup(s);
+}
+
+static
+void
+MY_xsltCollectGenericError(void *ctx, const char *msg, ...) {
+	go_xslt_error *e;
+	va_list ap;
+	char *buf;
+	int len;
+
+	va_start(ap, msg);
+	len = vsnprintf(NULL, 0, msg, ap);
+	va_end(ap);
+	if (len < 0) {
+		return;
+	}
+
+	buf = (char *) malloc(len + 1);
+	if (buf == NULL) {
+		return;
+	}
+	va_start(ap, msg);
+	vsnprintf(buf, len + 1, msg, ap);
+	va_end(ap);
+
+	e = MY_xsltAppendError((go_xslt_error_list *) ctx);
+	if (e == NULL) {
+		free(buf);
+		return;
+	}
+	e->message = buf;
+	e->chunk = 1;
+}
+
+// Declared with a const argument to match libxml2 >= 2.12. Older
+// versions take a non-const pointer, hence the casts when registering
+static
+void
+MY_xsltCollectStructuredError(void *ctx, const xmlError *err) {
+	go_xslt_error *e;
+
+	if (err == NULL) {
+		return;
+	}
+	e = MY_xsltAppendError((go_xslt_error_list *) ctx);
+	if (e == NULL) {
+		return;
+	}
+	e->message = MY_xsltStrdupOrNull(err->message);
+	e->file = MY_xsltStrdupOrNull(err->file);
+	e->line = err->line;
+	e->column = err->int2;
+	e->code = err->code;
+	e->domain = err->domain;
+	e->level = err->level;
+}
+
+// MY_xsltCompile compiles the stylesheet in doc, which it takes
+// ownership of. Compilation errors are reported through the global
+// libxslt error handler, so calls must hold errorMu
+static
+uintptr_t
+MY_xsltCompile(xmlDocPtr doc, go_xslt_error_list *list) {
+	xsltStylesheetPtr style;
+
+	if (doc == NULL) {
+		return 0;
+	}
+
+	xsltSetGenericErrorFunc(list, MY_xsltCollectGenericError);
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_xsltCollectStructuredError);
+	style = xsltParseStylesheetDoc(doc);
+	xmlSetStructuredErrorFunc(NULL, NULL);
+	xsltSetGenericErrorFunc(NULL, NULL);
+
+	if (style == NULL) {
+		// The document is left to the caller on failure
+		xmlFreeDoc(doc);
+		return 0;
+	}
+	if (style->errors != 0) {
+		xsltFreeStylesheet(style);
+		return 0;
+	}
+	return (uintptr_t) style;
+}
+
+static
+uintptr_t
+MY_xsltParseMemory(const char *buf, int len, const char *url, go_xslt_error_list *list) {
+	xmlDocPtr doc;
+
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_xsltCollectStructuredError);
+	doc = xmlReadMemory(buf, len, url, NULL, XSLT_PARSE_OPTIONS);
+	xmlSetStructuredErrorFunc(NULL, NULL);
+	return MY_xsltCompile(doc, list);
+}
+
+static
+uintptr_t
+MY_xsltParseFile(const char *path, go_xslt_error_list *list) {
+	xmlDocPtr doc;
+
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_xsltCollectStructuredError);
+	doc = xmlReadFile(path, NULL, XSLT_PARSE_OPTIONS);
+	xmlSetStructuredErrorFunc(NULL, NULL);
+	return MY_xsltCompile(doc, list);
+}
+
+static
+uintptr_t
+MY_xsltParseDocument(uintptr_t doc, const char *url, go_xslt_error_list *list) {
+	// The stylesheet owns its document, so it gets a copy
+	xmlDocPtr copy = xmlCopyDoc((xmlDocPtr) doc, 1);
+	if (copy != NULL && url != NULL) {
+		if (copy->URL != NULL) {
+			xmlFree((xmlChar *) copy->URL);
+		}
+		copy->URL = xmlStrdup((const xmlChar *) url);
+	}
+	return MY_xsltCompile(copy, list);
+}
+
+// MY_xsltStripsSpace tells whether the stylesheet, or one it imports,
+// strips whitespace from the source document with xsl:strip-space
+static
+int
+MY_xsltStripsSpace(xsltStylesheetPtr style) {
+	for (; style != NULL; style = xsltNextImport(style)) {
+		if (style->stripSpaces != NULL) {
+			return 1;
+		}
+	}
+	return 0;
+}
+
+// The bits of the forbidden argument of MY_xsltTransform, matching
+// the Access constants
+static const xsltSecurityOption MY_xsltSecurityOptions[] = {
+	XSLT_SECPREF_READ_FILE,
+	XSLT_SECPREF_WRITE_FILE,
+	XSLT_SECPREF_CREATE_DIRECTORY,
+	XSLT_SECPREF_READ_NETWORK,
+	XSLT_SECPREF_WRITE_NETWORK,
+};
+
+// MY_xsltNewSecurityPrefs returns preferences that forbid the
+// operations in forbidden, or NULL if there are none
+static
+xsltSecurityPrefsPtr
+MY_xsltNewSecurityPrefs(int forbidden) {
+	xsltSecurityPrefsPtr prefs;
+	size_t i;
+
+	if (forbidden == 0) {
+		return NULL;
+	}
+	prefs = xsltNewSecurityPrefs();
+	if (prefs == NULL) {
+		return NULL;
+	}
+	for (i = 0; i < sizeof(MY_xsltSecurityOptions) / sizeof(MY_xsltSecurityOptions[0]); i++) {
+		if (forbidden & (1 << i)) {
+			xsltSetSecurityPrefs(prefs, MY_xsltSecurityOptions[i], xsltSecurityForbid);
+		}
+	}
+	return prefs;
+}
+
+static
+uintptr_t
+MY_xsltTransform(uintptr_t style, uintptr_t doc, const char **params, int forbidden, go_xslt_error_list *list) {
+	xsltTransformContextPtr ctxt;
+	xsltSecurityPrefsPtr prefs = NULL;
+	xmlDocPtr src = (xmlDocPtr) doc;
+	xmlDocPtr res = NULL;
+
+	// Stripping whitespace modifies the source document in place, so
+	// the caller's document is left alone and a copy is transformed
+	if (MY_xsltStripsSpace((xsltStylesheetPtr) style)) {
+		src = xmlCopyDoc(src, 1);
+		if (src == NULL) {
+			return 0;
+		}
+	}
+
+	ctxt = xsltNewTransformContext((xsltStylesheetPtr) style, src);
+	if (ctxt == NULL) {
+		if (src != (xmlDocPtr) doc) {
+			xmlFreeDoc(src);
+		}
+		return 0;
+	}
+	if (forbidden != 0) {
+		prefs = MY_xsltNewSecurityPrefs(forbidden);
+		if (prefs == NULL || xsltSetCtxtSecurityPrefs(prefs, ctxt) != 0) {
+			// Never run with fewer restrictions than asked for
+			if (prefs != NULL) {
+				xsltFreeSecurityPrefs(prefs);
+			}
+			xsltFreeTransformContext(ctxt);
+			if (src != (xmlDocPtr) doc) {
+				xmlFreeDoc(src);
+			}
+			return 0;
+		}
+	}
+	xsltSetTransformErrorFunc(ctxt, list, MY_xsltCollectGenericError);
+	xmlSetStructuredErrorFunc(list, (xmlStructuredErrorFunc) MY_xsltCollectStructuredError);
+
+	if (xsltQuoteUserParams(ctxt, params) == 0) {
+		res = xsltApplyStylesheetUser((xsltStylesheetPtr) style, src, NULL, NULL, NULL, ctxt);
+	}
+	if (res != NULL && ctxt->state != XSLT_STATE_OK) {
+		xmlFreeDoc(res);
+		res = NULL;
+	}
+
+	xmlSetStructuredErrorFunc(NULL, NULL);
+	xsltFreeTransformContext(ctxt);
+	if (prefs != NULL) {
+		xsltFreeSecurityPrefs(prefs);
+	}
+	if (src != (xmlDocPtr) doc) {
+		xmlFreeDoc(src);
+	}
+	return (uintptr_t) res;
+}
+
+static
+char*
+MY_xsltSaveResult(uintptr_t res, uintptr_t style, int *len) {
+	xmlChar *out = NULL;
+
+	if (xsltSaveResultToString(&out, len, (xmlDocPtr) res, (xsltStylesheetPtr) style) != 0) {
+		return NULL;
+	}
+	if (out == NULL) {
+		// Nothing to output
+		*len = 0;
+		return (char *) xmlStrdup((const xmlChar *) "");
+	}
+	return (char *) out;
+}
+
+static
+void
+MY_xsltFreeString(char *s) {
+	xmlFree(s);
+}
+
+static
+void
+MY_xsltFreeStylesheet(uintptr_t style) {
+	xsltFreeStylesheet((xsltStylesheetPtr) style);
+}
+*/
+import "C"
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/internal/option"
+	"github.com/pkg/errors"
+)
+
+// The libxslt code lives in this package rather than in clib, so that
+// only the users of this package need to link against libxslt
+
+// errorMu guards the global libxslt error handler. Compilations
+// install it, so they hold the write lock. Transformations report
+// most errors through a handler of their own, but parts of libxslt
+// always use the global one, so they hold the read lock to keep
+// their errors out of a concurrent compilation
+var errorMu sync.RWMutex
+
+// contextLine matches the lines libxslt prints before an error
+// message to tell where it happened
+var contextLine = regexp.MustCompile(`^(?:runtime error|compilation error|error|warning): ?(?:file (\S+))? ?(?:line (\d+))? ?(?:element (\S+))?$`)
+
+// collectErrors turns the contents of the list into errors
+func collectErrors(list *C.go_xslt_error_list) []error {
+	if list.count == 0 {
+		return nil
+	}
+
+	var errs []error
+	var text strings.Builder
+	flush := func() {
+		var file string
+		var line int
+		for _, msg := range strings.Split(text.String(), "\n") {
+			if msg == "" {
+				continue
+			}
+			if m := contextLine.FindStringSubmatch(msg); m != nil {
+				file = m[1]
+				line, _ = strconv.Atoi(m[2])
+				continue
+			}
+			errs = append(errs, Error{
+				Message: msg,
+				File:    file,
+				Line:    line,
+				Domain:  int(C.XML_FROM_XSLT),
+				Level:   clib.ErrorLevelError,
+			})
+			file, line = "", 0
+		}
+		text.Reset()
+	}
+
+	for _, e := range unsafe.Slice(list.errors, int(list.count)) {
+		if e.chunk != 0 {
+			text.WriteString(C.GoString(e.message))
+			continue
+		}
+		flush()
+		errs = append(errs, Error{
+			Message: strings.TrimRight(C.GoString(e.message), "\n"),
+			File:    C.GoString(e.file),
+			Line:    int(e.line),
+			Column:  int(e.column),
+			Code:    int(e.code),
+			Domain:  int(e.domain),
+			Level:   clib.ErrorLevel(e.level),
+		})
+	}
+	flush()
+	return errs
+}
+
+// firstError returns the first error in errs that tells where it was
+// found, as XPath errors reported while compiling a stylesheet do not
+// but are followed by one that does. If there is none, it returns the
+// first error, or def if errs is empty
+func firstError(errs []error, def string) error {
+	for _, err := range errs {
+		if e, ok := err.(Error); ok && e.File != "" {
+			return e
+		}
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return errors.New(def)
+}
+
+func compile(fn func(list *C.go_xslt_error_list) C.uintptr_t) (uintptr, error) {
+	list := C.MY_xsltCreateErrorList()
+	defer C.MY_xsltFreeErrorList(list)
+
+	errorMu.Lock()
+	ptr := fn(list)
+	errorMu.Unlock()
+
+	if ptr == 0 {
+		return 0, firstError(collectErrors(list), "failed to compile stylesheet")
+	}
+	return uintptr(ptr), nil
+}
+
+// uriOption returns the value of the WithURI option as a C string,
+// or nil. The caller must free it
+func uriOption(options []option.Interface) *C.char {
+	var uri string
+	//nolint:forcetypeassert
+	for _, opt := range options {
+		switch opt.Name() {
+		case option.OptKeyWithURI:
+			uri = opt.Value().(string)
+		}
+	}
+	if uri == "" {
+		return nil
+	}
+	return C.CString(uri)
+}
+
+func xsltParse(buf []byte, options ...option.Interface) (uintptr, error) {
+	if len(buf) == 0 {
+		return 0, errors.New("empty buffer")
+	}
+
+	curi := uriOption(options)
+	defer C.free(unsafe.Pointer(curi))
+	cbuf := C.CBytes(buf)
+	defer C.free(cbuf)
+
+	return compile(func(list *C.go_xslt_error_list) C.uintptr_t {
+		return C.MY_xsltParseMemory((*C.char)(cbuf), C.int(len(buf)), curi, list)
+	})
+}
+
+func xsltParseFile(path string) (uintptr, error) {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	return compile(func(list *C.go_xslt_error_list) C.uintptr_t {
+		return C.MY_xsltParseFile(cpath, list)
+	})
+}
+
+func xsltParseDocument(doc uintptr, options ...option.Interface) (uintptr, error) {
+	if doc == 0 {
+		return 0, errors.New("invalid document")
+	}
+
+	curi := uriOption(options)
+	defer C.free(unsafe.Pointer(curi))
+
+	return compile(func(list *C.go_xslt_error_list) C.uintptr_t {
+		return C.MY_xsltParseDocument(C.uintptr_t(doc), curi, list)
+	})
+}
+
+// xsltTransform applies the stylesheet to doc. The parameters are
+// passed as strings, in the order of their names. The operations in
+// forbidden are refused
+func xsltTransform(style, doc uintptr, params map[string]string, forbidden Access) (uintptr, []error) {
+	if style == 0 {
+		return 0, []error{errors.New("invalid stylesheet")}
+	}
+	if doc == 0 {
+		return 0, []error{errors.New("invalid document")}
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// NULL terminated list of name/value pairs
+	cparams := (**C.char)(C.calloc(C.size_t(2*len(names)+1), C.size_t(unsafe.Sizeof((*C.char)(nil)))))
+	defer C.free(unsafe.Pointer(cparams))
+	slots := unsafe.Slice(cparams, 2*len(names)+1)
+	for i, name := range names {
+		slots[2*i] = C.CString(name)
+		slots[2*i+1] = C.CString(params[name])
+	}
+	defer func() {
+		for _, s := range slots {
+			C.free(unsafe.Pointer(s))
+		}
+	}()
+
+	list := C.MY_xsltCreateErrorList()
+	defer C.MY_xsltFreeErrorList(list)
+
+	errorMu.RLock()
+	res := C.MY_xsltTransform(C.uintptr_t(style), C.uintptr_t(doc), cparams, C.int(forbidden), list)
+	errorMu.RUnlock()
+	if res == 0 {
+		if errs := collectErrors(list); len(errs) > 0 {
+			return 0, errs
+		}
+		return 0, []error{errors.New("transformation failed")}
+	}
+	return uintptr(res), nil
+}
+
+func xsltSaveResult(style, doc uintptr) ([]byte, error) {
+	if style == 0 {
+		return nil, errors.New("invalid stylesheet")
+	}
+	if doc == 0 {
+		return nil, errors.New("invalid document")
+	}
+
+	var clen C.int
+	out := C.MY_xsltSaveResult(C.uintptr_t(doc), C.uintptr_t(style), &clen)
+	if out == nil {
+		return nil, errors.New("failed to serialize result")
+	}
+	defer C.MY_xsltFreeString(out)
+
+	return C.GoBytes(unsafe.Pointer(out), clen), nil
+}
+
+func xsltFree(style uintptr) {
+	if style != 0 {
+		C.MY_xsltFreeStylesheet(C.uintptr_t(style))
+	}
+}
@@ -0,0 +1,54 @@
+package xslt
+
+import (
+	"github.com/lestrrat-go/libxml2/clib"
+	"github.com/lestrrat-go/libxml2/internal/option"
+)
+
+// Stylesheet represents a compiled XSLT stylesheet.
+type Stylesheet struct {
+	ptr uintptr // *C.xsltStylesheet
+}
+
+// TransformError is returned when Transform() fails. When there are
+// multiple errors, you may access them using the Errors() method
+type TransformError struct {
+	errors []error
+}
+
+// Error holds the details of a single error. The values returned by
+// TransformError.Errors() are of this type, unless the error was not
+// reported by libxml2 or libxslt
+type Error = clib.StructuredError
+
+type Option = option.Interface
+
+// TransformOption is passed to Transform to change how the
+// stylesheet is applied
+type TransformOption interface {
+	option.Interface
+	xsltTransformOption()
+}
+
+// Access is a set of operations that a stylesheet may be forbidden
+// from performing during a transformation. See WithForbiddenAccess
+type Access int
+
+const (
+	// AccessReadFile covers reading local files, for example
+	// through document()
+	AccessReadFile Access = 1 << iota
+	// AccessWriteFile covers writing local files, for example
+	// through exsl:document
+	AccessWriteFile
+	// AccessCreateDirectory covers creating the directories that
+	// written files are put in
+	AccessCreateDirectory
+	// AccessReadNetwork covers fetching documents by URL
+	AccessReadNetwork
+	// AccessWriteNetwork covers writing documents to URLs
+	AccessWriteNetwork
+
+	// AccessAll covers all of the above
+	AccessAll = AccessReadFile | AccessWriteFile | AccessCreateDirectory | AccessReadNetwork | AccessWriteNetwork
+)
@@ -0,0 +1,7 @@
+//go:build !static_build
+// +build !static_build
+
+package xslt
+
+// #cgo pkg-config: libxslt
+import "C"
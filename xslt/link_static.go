@@ -0,0 +1,8 @@
+//go:build static_build
+// +build static_build
+
+package xslt
+
+// #cgo pkg-config: --static libxslt
+// #cgo LDFLAGS: -static
+import "C"
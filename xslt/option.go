@@ -0,0 +1,45 @@
+package xslt
+
+import "github.com/lestrrat-go/libxml2/internal/option"
+
+type transformOption struct {
+	*option.Option
+}
+
+func (transformOption) xsltTransformOption() {}
+
+// WithPath provides a hint to the XSLT compiler as to where the
+// stylesheet being parsed is located at.
+//
+// This is required when the stylesheet includes other files using
+// relative paths, such as `<xsl:import href="common.xsl"/>`,
+// and the stylesheet is parsed using `Parse()`. When using `ParseFile()`
+// this is handled automatically.
+//
+// If the path is provided as a relative path, the current directory
+// should be obtainable via `os.Getwd` when this call is made.
+func WithPath(path string) Option {
+	return WithURI(option.PathURI(path))
+}
+
+// WithURI specifies the URI of the stylesheet being parsed, which is
+// used to resolve relative references to other stylesheets and
+// documents.
+func WithURI(v string) Option {
+	return option.New(option.OptKeyWithURI, v)
+}
+
+// WithForbiddenAccess forbids the stylesheet from performing the
+// given operations during Transform. Attempting one of them fails
+// the transformation.
+//
+// By default stylesheets may read and write any file or URL, which
+// is not what you want for untrusted stylesheets or documents:
+//
+//	result, err := style.Transform(doc, nil, xslt.WithForbiddenAccess(xslt.AccessAll))
+//
+// Files that the stylesheet imports or includes are read when it is
+// parsed, and are not affected by this option.
+func WithForbiddenAccess(access Access) TransformOption {
+	return transformOption{Option: option.New(option.OptKeyWithForbiddenAccess, access)}
+}
@@ -0,0 +1,135 @@
+// Package xslt contains the tools available from libxslt that allow
+// you to transform XML documents using XSLT 1.0 stylesheets. Unlike
+// the rest of go-libxml2, this package also requires libxslt.
+//
+// This is basically all you need to do:
+//
+//	style, err := xslt.Parse(xslsrc)
+//	if err != nil {
+//	    panic(err)
+//	}
+//	defer style.Free()
+//
+//	result, err := style.Transform(doc, map[string]string{"title": "Hello"})
+//	if err != nil {
+//	    for _, e := range err.(xslt.TransformError).Errors() {
+//	         println(e.Error())
+//	    }
+//	    return
+//	}
+//	defer result.Free()
+//
+//	buf, err := style.Output(result)
+//
+// The EXSLT extensions implemented by libexslt are available to all
+// stylesheets, see the exslt package.
+//
+// Note that unless WithForbiddenAccess is passed to Transform,
+// stylesheets may read any file or URL through document(), and write
+// files using extension elements.
+package xslt
+
+import (
+	"github.com/lestrrat-go/libxml2/dom"
+	"github.com/lestrrat-go/libxml2/internal/option"
+	"github.com/lestrrat-go/libxml2/types"
+	"github.com/pkg/errors"
+
+	// Enables EXSLT in stylesheets
+	_ "github.com/lestrrat-go/libxml2/exslt"
+)
+
+// Parse is used to parse an XSLT stylesheet to produce a Stylesheet
+// instance. Make sure to call Free() on the instance when you
+// are done with it.
+func Parse(buf []byte, options ...Option) (*Stylesheet, error) {
+	ptr, err := xsltParse(buf, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse input")
+	}
+
+	return &Stylesheet{ptr: ptr}, nil
+}
+
+// ParseFile is used to parse an XSLT stylesheet using only the file
+// path. Make sure to call Free() on the instance when you are done
+// with it.
+func ParseFile(path string) (*Stylesheet, error) {
+	ptr, err := xsltParseFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse input from file")
+	}
+
+	return &Stylesheet{ptr: ptr}, nil
+}
+
+// FromDocument compiles the stylesheet held by an already parsed
+// document. The stylesheet works on a copy of the document, which
+// may be freed or modified afterwards. WithURI() and WithPath()
+// override the URI of the document, which relative references are
+// resolved against. Make sure to call Free() on the instance when you
+// are done with it.
+func FromDocument(doc types.Document, options ...Option) (*Stylesheet, error) {
+	ptr, err := xsltParseDocument(doc.Pointer(), options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse document")
+	}
+
+	return &Stylesheet{ptr: ptr}, nil
+}
+
+// Pointer returns the underlying C struct
+func (s *Stylesheet) Pointer() uintptr {
+	return s.ptr
+}
+
+// Free frees the underlying C struct
+func (s *Stylesheet) Free() {
+	xsltFree(s.ptr)
+	s.ptr = 0
+}
+
+// Transform applies the stylesheet to the document, and returns the
+// resulting document. Make sure to call Free() on it when you are
+// done with it. The values of params are passed to the top-level
+// parameters of the same names as strings. See WithForbiddenAccess
+// for restricting the files and URLs the stylesheet may access.
+//
+// If the stylesheet strips whitespace using xsl:strip-space, a copy
+// of doc is transformed, so that doc itself is never modified.
+//
+// If the transformation fails, a TransformError is returned.
+func (s *Stylesheet) Transform(doc types.Document, params map[string]string, options ...TransformOption) (types.Document, error) {
+	var forbidden Access
+	//nolint:forcetypeassert
+	for _, opt := range options {
+		switch opt.Name() {
+		case option.OptKeyWithForbiddenAccess:
+			forbidden = opt.Value().(Access)
+		}
+	}
+
+	ptr, errs := xsltTransform(s.ptr, doc.Pointer(), params, forbidden)
+	if errs != nil {
+		return nil, TransformError{errors: errs}
+	}
+
+	return dom.WrapDocument(ptr), nil
+}
+
+// Output serializes a document returned by Transform() as specified
+// by the xsl:output element of the stylesheet, such as the output
+// method, the encoding and indentation.
+func (s *Stylesheet) Output(doc types.Document) ([]byte, error) {
+	return xsltSaveResult(s.ptr, doc.Pointer())
+}
+
+// Error method fulfils the error interface
+func (te TransformError) Error() string {
+	return "transformation failed"
+}
+
+// Errors returns the list of errors found
+func (te TransformError) Errors() []error {
+	return te.errors
+}
@@ -0,0 +1,357 @@
+package libxml2_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/libxml2"
+	"github.com/lestrrat-go/libxml2/xslt"
+	"github.com/stretchr/testify/assert"
+)
+
+const xsltCatalog = `<catalog xmlns="urn:example:catalog">
+  <product sku="SHT-0001"><name>Shirt</name><price currency="EUR">19.9</price></product>
+  <product sku="MUG-0002"><name>Mug</name><price currency="USD">5</price></product>
+</catalog>`
+
+func TestXSLT(t *testing.T) {
+	doc, err := libxml2.ParseString(xsltCatalog)
+	if !assert.NoError(t, err, "parsing document") {
+		return
+	}
+	defer doc.Free()
+
+	path := filepath.Join("test", "xslt", "catalog.xsl")
+	buf, err := os.ReadFile(path)
+	if !assert.NoError(t, err, "reading stylesheet") {
+		return
+	}
+
+	styledoc, err := libxml2.Parse(buf)
+	if !assert.NoError(t, err, "parsing stylesheet document") {
+		return
+	}
+	defer styledoc.Free()
+
+	parsers := map[string]func() (*xslt.Stylesheet, error){
+		"Parse": func() (*xslt.Stylesheet, error) {
+			return xslt.Parse(buf, xslt.WithPath(path))
+		},
+		"ParseFile": func() (*xslt.Stylesheet, error) {
+			return xslt.ParseFile(path)
+		},
+		"FromDocument": func() (*xslt.Stylesheet, error) {
+			return xslt.FromDocument(styledoc, xslt.WithPath(path))
+		},
+	}
+	for name, parse := range parsers {
+		t.Run(name, func(t *testing.T) {
+			style, err := parse()
+			if !assert.NoError(t, err, "parsing stylesheet should succeed") {
+				return
+			}
+			defer style.Free()
+
+			result, err := style.Transform(doc, map[string]string{"title": `"Spring" & 'Summer'`})
+			if !assert.NoError(t, err, "Transform should succeed") {
+				return
+			}
+			defer result.Free()
+
+			root, err := result.DocumentElement()
+			if !assert.NoError(t, err, "result has a root element") {
+				return
+			}
+			if !assert.Equal(t, "products", root.NodeName(), "root element matches") {
+				return
+			}
+
+			out, err := style.Output(result)
+			if !assert.NoError(t, err, "Output should succeed") {
+				return
+			}
+			s := string(out)
+			if !assert.True(t, strings.HasPrefix(s, `<?xml version="1.0" encoding="ISO-8859-1"?>`), "encoding is honored") {
+				return
+			}
+			if !assert.Contains(t, s, `title="&quot;Spring&quot; &amp; 'Summer'"`, "parameter is passed as a string") {
+				return
+			}
+			if !assert.Contains(t, s, "\n  <product sku=\"SHT-0001\">19.90</product>", "output is indented, and imported template is used") {
+				return
+			}
+		})
+	}
+}
+
+func TestXSLTOutputMethod(t *testing.T) {
+	style, err := xslt.Parse([]byte(`<xsl:stylesheet version="1.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform">
+  <xsl:output method="text"/>
+  <xsl:template match="/">
+    <xsl:for-each select="//*[local-name()='name']">
+      <xsl:value-of select="."/>
+      <xsl:text>;</xsl:text>
+    </xsl:for-each>
+  </xsl:template>
+</xsl:stylesheet>`))
+	if !assert.NoError(t, err, "parsing stylesheet should succeed") {
+		return
+	}
+	defer style.Free()
+
+	doc, err := libxml2.ParseString(xsltCatalog)
+	if !assert.NoError(t, err, "parsing document") {
+		return
+	}
+	defer doc.Free()
+
+	result, err := style.Transform(doc, nil)
+	if !assert.NoError(t, err, "Transform should succeed") {
+		return
+	}
+	defer result.Free()
+
+	out, err := style.Output(result)
+	if !assert.NoError(t, err, "Output should succeed") {
+		return
+	}
+	if !assert.Equal(t, "Shirt;Mug;", string(out), "text output matches") {
+		return
+	}
+}
+
+func TestXSLTErrors(t *testing.T) {
+	t.Run("malformed stylesheet", func(t *testing.T) {
+		_, err := xslt.Parse([]byte(`<xsl:stylesheet`))
+		if !assert.Error(t, err, "Parse should fail") {
+			return
+		}
+	})
+
+	t.Run("invalid stylesheet", func(t *testing.T) {
+		_, err := xslt.Parse([]byte(`<xsl:stylesheet version="1.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform">
+  <xsl:template match="/">
+    <xsl:value-of select="1 +"/>
+  </xsl:template>
+</xsl:stylesheet>`), xslt.WithURI("invalid.xsl"))
+		if !assert.Error(t, err, "Parse should fail") {
+			return
+		}
+		if !assert.Contains(t, err.Error(), "invalid.xsl", "error is located") {
+			return
+		}
+	})
+
+	t.Run("terminating message", func(t *testing.T) {
+		style, err := xslt.Parse([]byte(`<xsl:stylesheet version="1.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform">
+  <xsl:param name="limit" select="1"/>
+  <xsl:template match="/">
+    <xsl:if test="count(/*/*) &gt; $limit">
+      <xsl:message terminate="yes">too many products</xsl:message>
+    </xsl:if>
+    <ok/>
+  </xsl:template>
+</xsl:stylesheet>`))
+		if !assert.NoError(t, err, "parsing stylesheet should succeed") {
+			return
+		}
+		defer style.Free()
+
+		doc, err := libxml2.ParseString(xsltCatalog)
+		if !assert.NoError(t, err, "parsing document") {
+			return
+		}
+		defer doc.Free()
+
+		result, err := style.Transform(doc, map[string]string{"limit": "5"})
+		if !assert.NoError(t, err, "Transform should succeed below the limit") {
+			return
+		}
+		result.Free()
+
+		_, err = style.Transform(doc, nil)
+		if !assert.Error(t, err, "Transform should fail") {
+			return
+		}
+		terr, ok := err.(xslt.TransformError)
+		if !assert.True(t, ok, "error is a TransformError") {
+			return
+		}
+		var messages []string
+		for _, e := range terr.Errors() {
+			messages = append(messages, e.Error())
+		}
+		if !assert.Contains(t, messages, "too many products", "message is reported") {
+			return
+		}
+	})
+}
+
+func TestXSLTResultNormalize(t *testing.T) {
+	style, err := xslt.Parse([]byte(`<xsl:stylesheet version="1.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform">
+  <xsl:template match="/">
+    <root>
+      <xsl:text>a</xsl:text>
+      <xsl:text disable-output-escaping="yes">&amp;b</xsl:text>
+      <xsl:text>c</xsl:text>
+      <xsl:text>d</xsl:text>
+    </root>
+  </xsl:template>
+</xsl:stylesheet>`))
+	if !assert.NoError(t, err, "parsing stylesheet should succeed") {
+		return
+	}
+	defer style.Free()
+
+	doc, err := libxml2.ParseString(`<in/>`)
+	if !assert.NoError(t, err, "parsing document") {
+		return
+	}
+	defer doc.Free()
+
+	result, err := style.Transform(doc, nil)
+	if !assert.NoError(t, err, "Transform should succeed") {
+		return
+	}
+	defer result.Free()
+
+	root, err := result.DocumentElement()
+	if !assert.NoError(t, err, "result has a root element") {
+		return
+	}
+
+	// Text nodes that must not be escaped cannot be merged with others
+	if !assert.NoError(t, root.Normalize(), "Normalize should succeed") {
+		return
+	}
+	children, err := root.ChildNodes()
+	if !assert.NoError(t, err, "ChildNodes should succeed") {
+		return
+	}
+	if !assert.Len(t, children, 3, "adjacent escaped text nodes are merged") {
+		return
+	}
+	if !assert.Equal(t, "a&bcd", root.TextContent(), "text content is preserved") {
+		return
+	}
+}
+
+func TestXSLTStripSpace(t *testing.T) {
+	style, err := xslt.Parse([]byte(`<xsl:stylesheet version="1.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform">
+  <xsl:strip-space elements="*"/>
+  <xsl:output method="text"/>
+  <xsl:template match="/">
+    <xsl:value-of select="count(//text())"/>
+  </xsl:template>
+</xsl:stylesheet>`))
+	if !assert.NoError(t, err, "parsing stylesheet should succeed") {
+		return
+	}
+	defer style.Free()
+
+	const src = "<r>\n  <a>x</a>\n  <b>y</b>\n</r>"
+	doc, err := libxml2.ParseString(src)
+	if !assert.NoError(t, err, "parsing document") {
+		return
+	}
+	defer doc.Free()
+
+	result, err := style.Transform(doc, nil)
+	if !assert.NoError(t, err, "Transform should succeed") {
+		return
+	}
+	defer result.Free()
+
+	out, err := style.Output(result)
+	if !assert.NoError(t, err, "Output should succeed") {
+		return
+	}
+	if !assert.Equal(t, "2", string(out), "whitespace is stripped during the transformation") {
+		return
+	}
+	if !assert.Contains(t, doc.String(), src, "the input document is not modified") {
+		return
+	}
+}
+
+func TestXSLTForbiddenAccess(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.xml")
+	style, err := xslt.Parse([]byte(`<xsl:stylesheet version="1.1" xmlns:xsl="http://www.w3.org/1999/XSL/Transform">
+  <xsl:param name="out"/>
+  <xsl:output method="text"/>
+  <xsl:template match="/">
+    <xsl:value-of select="count(document('catalog.xsl')/*)"/>
+    <xsl:if test="$out != ''">
+      <xsl:document href="{$out}"><written/></xsl:document>
+    </xsl:if>
+  </xsl:template>
+</xsl:stylesheet>`), xslt.WithPath(filepath.Join("test", "xslt", "access.xsl")))
+	if !assert.NoError(t, err, "parsing stylesheet should succeed") {
+		return
+	}
+	defer style.Free()
+
+	doc, err := libxml2.ParseString(`<in/>`)
+	if !assert.NoError(t, err, "parsing document") {
+		return
+	}
+	defer doc.Free()
+
+	transform := func(params map[string]string, options ...xslt.TransformOption) (string, error) {
+		result, err := style.Transform(doc, params, options...)
+		if err != nil {
+			return "", err
+		}
+		defer result.Free()
+		buf, err := style.Output(result)
+		return string(buf), err
+	}
+
+	t.Run("allowed by default", func(t *testing.T) {
+		s, err := transform(map[string]string{"out": out})
+		if !assert.NoError(t, err, "Transform should succeed") {
+			return
+		}
+		if !assert.Equal(t, "1", s, "document() reads the file") {
+			return
+		}
+		if !assert.FileExists(t, out, "xsl:document writes the file") {
+			return
+		}
+		if !assert.NoError(t, os.Remove(out), "removing written file") {
+			return
+		}
+	})
+
+	refused := func(t *testing.T, err error, what string) bool {
+		terr, ok := err.(xslt.TransformError)
+		if !assert.True(t, ok, "error is a TransformError") {
+			return false
+		}
+		var messages []string
+		for _, e := range terr.Errors() {
+			messages = append(messages, e.Error())
+		}
+		return assert.Contains(t, strings.Join(messages, "\n"), what, "access is refused")
+	}
+
+	t.Run("read forbidden", func(t *testing.T) {
+		_, err := transform(nil, xslt.WithForbiddenAccess(xslt.AccessReadFile))
+		if !refused(t, err, "Local file read for") {
+			return
+		}
+	})
+
+	t.Run("write forbidden", func(t *testing.T) {
+		_, err := transform(map[string]string{"out": out}, xslt.WithForbiddenAccess(xslt.AccessWriteFile))
+		if !refused(t, err, "File write for") {
+			return
+		}
+		if !assert.NoFileExists(t, out, "file is not written") {
+			return
+		}
+	})
+}